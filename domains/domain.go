@@ -22,6 +22,9 @@ package domains
 
 import (
 	"context"
+	"encoding/json"
+	"time"
+
 	"github.com/bwmarrin/snowflake"
 )
 
@@ -33,16 +36,353 @@ type Character struct {
 	Player     string        `json:"player"`
 	PlayerName string        `json:"playerName"`
 	System     string        `json:"system"`
+	Campaign   *snowflake.ID `json:"campaign,omitempty"`
 	Sheet      Sheet         `json:"sheet"`
 }
 
 // The CharacterRepository describes the interface to find and store characters.
 type CharacterRepository interface {
 	FindByPlayer(ctx context.Context, id string) ([]*Character, error)
+	FindByGuild(ctx context.Context, id string) ([]*Character, error)
 	FindByID(ctx context.Context, id string) (*Character, error)
 	Store(ctx context.Context, c *Character) error
 }
 
+// A SheetRevision is a copy of a character's sheet kept whenever the bot changes it, so that the
+// change can be reviewed or undone
+type SheetRevision struct {
+	ID        *snowflake.ID `json:"id"`
+	Character *snowflake.ID `json:"character"`
+	Player    string        `json:"player"` // the player who made the change
+	Reason    string        `json:"reason"`
+	Sheet     Sheet         `json:"sheet"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// The SheetRevisionRepository describes the interface to store sheet revisions.
+type SheetRevisionRepository interface {
+	Store(ctx context.Context, r *SheetRevision) error
+}
+
+// A Campaign is a game run in a guild, which characters and players can join.  A guild may run
+// several campaigns at once.
+type Campaign struct {
+	ID           *snowflake.ID `json:"id"`
+	Name         string        `json:"name"`
+	Guild        string        `json:"guild"`
+	System       string        `json:"system"`
+	Storytellers []string      `json:"storytellers"`
+	Members      []string      `json:"members"`
+	Channels     []string      `json:"channels"`
+}
+
+// The CampaignRepository describes the interface to find and store campaigns.
+type CampaignRepository interface {
+	FindByGuild(ctx context.Context, id string) ([]*Campaign, error)
+	FindByID(ctx context.Context, id string) (*Campaign, error)
+	Store(ctx context.Context, c *Campaign) error
+}
+
+// RollVisibility describes who may see the result of a roll
+type RollVisibility string
+
+// The visibilities of a roll
+const (
+	RollPublic RollVisibility = "public"
+	RollSecret RollVisibility = "secret" // seen by the roller and the storytellers
+	RollGM     RollVisibility = "gm"     // seen only by the storytellers
+)
+
+// RollSource describes where a roll was made
+type RollSource string
+
+// The sources of a roll
+const (
+	RollFromBot RollSource = "bot"
+	RollFromWeb RollSource = "web"
+)
+
+// A Roll is a roll of the dice which was kept, so that it can be revealed or reviewed later.
+type Roll struct {
+	ID          *snowflake.ID   `json:"id"`
+	Guild       string          `json:"guild"`
+	Channel     string          `json:"channel"`
+	Player      string          `json:"player"`
+	Character   *snowflake.ID   `json:"character,omitempty"` // the character rolled for, if any
+	Source      RollSource      `json:"source"`
+	System      string          `json:"system"`
+	Parameters  string          `json:"parameters"` // the command or request which made the roll
+	Visibility  RollVisibility  `json:"visibility"`
+	Description string          `json:"description"` // the roll as it was described in discord
+	Outcome     int             `json:"outcome"`
+	Dice        []int64         `json:"dice"`    // every die rolled
+	Results     json.RawMessage `json:"results"` // the roll system, as json
+	Proof       *RollProof      `json:"proof,omitempty"`
+	DrawnFrom   string          `json:"drawnFrom,omitempty"` // the sources the dice were drawn from
+	Revealed    bool            `json:"revealed"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// The methods by which a roll can be proven fair
+const (
+	ProofCommitReveal = "commit-reveal" // dice derived from the secret seed of a fair session
+	ProofRandomOrg    = "random.org"    // dice signed by random.org
+)
+
+// The sources which dice can be drawn from
+const (
+	RandomCrypto      = "crypto"
+	RandomSeeded      = "seeded"
+	RandomOrg         = "random.org"
+	RandomFairSession = "fair session"
+)
+
+// A RollProof records how the dice of a roll were drawn, so that they can be checked later.
+type RollProof struct {
+	Method  string      `json:"method"`
+	Session string      `json:"session,omitempty"` // the fair session, for commit-reveal
+	Nonce   string      `json:"nonce,omitempty"`   // the nonce the dice were derived with, for commit-reveal
+	Draws   []*RollDraw `json:"draws"`
+}
+
+// A RollDraw is one request for dice made while rolling; some systems draw more than once.
+type RollDraw struct {
+	Times     int             `json:"times"`
+	Min       int64           `json:"min"`
+	Max       int64           `json:"max"`
+	Dice      []int64         `json:"dice"`
+	Source    string          `json:"source,omitempty"`
+	Batch     string          `json:"batch,omitempty"`  // the signed batch the dice were taken from, for random.org
+	Random    json.RawMessage `json:"random,omitempty"` // the signed random object, for draws made before batches were kept
+	Signature string          `json:"signature,omitempty"`
+	Offset    int             `json:"offset,omitempty"` // where the dice begin in the signed random object
+}
+
+// A SignedBatch is a batch of integers signed by random.org, which the dice of many draws are taken
+// from.  It is only published once every integer in it has been drawn or thrown away, so that it
+// cannot be read for the dice of rolls yet to be made.
+type SignedBatch struct {
+	ID        string          `json:"id"`
+	Random    json.RawMessage `json:"random"`
+	Signature string          `json:"signature"`
+	Published bool            `json:"published"`
+}
+
+// The SignedBatchRepository describes the interface to find, store and publish signed batches.
+type SignedBatchRepository interface {
+	FindByID(ctx context.Context, id string) (*SignedBatch, error)
+	Store(ctx context.Context, b *SignedBatch) error
+	PublishAll(ctx context.Context) error
+}
+
+// A FairSession is a period of play in a channel during which dice are derived from a secret seed.
+// The hash of the seed is published when the session starts, and the seed when it ends.
+type FairSession struct {
+	ID        *snowflake.ID `json:"id"`
+	Guild     string        `json:"guild"`
+	Channel   string        `json:"channel"`
+	Hash      string        `json:"hash"`
+	Seed      string        `json:"-"` // secret until the session ends
+	StartedAt time.Time     `json:"startedAt"`
+	EndedAt   *time.Time    `json:"endedAt,omitempty"`
+}
+
+// The FairSessionRepository describes the interface to find and store fair sessions.
+type FairSessionRepository interface {
+	FindActive(ctx context.Context, channel string) (*FairSession, error)
+	FindByID(ctx context.Context, id string) (*FairSession, error)
+	Store(ctx context.Context, s *FairSession) error
+}
+
+// ExtendedStatus describes whether an extended action is still being rolled
+type ExtendedStatus string
+
+// The statuses of an extended action
+const (
+	ExtendedActive    ExtendedStatus = "active"
+	ExtendedSucceeded ExtendedStatus = "succeeded"
+	ExtendedFailed    ExtendedStatus = "failed"
+	ExtendedAbandoned ExtendedStatus = "abandoned"
+)
+
+// An ExtendedAction is a Chronicles of Darkness extended action, whose successes are accumulated
+// over several rolls until they reach the target or the rolls run out.
+type ExtendedAction struct {
+	ID          *snowflake.ID  `json:"id"`
+	Guild       string         `json:"guild"`
+	Channel     string         `json:"channel"`
+	Player      string         `json:"player"`
+	Name        string         `json:"name"`
+	Target      int            `json:"target"`
+	Pool        int            `json:"pool"`
+	Skill       int            `json:"skill"` // how far an exceptional success may lower the target
+	Limit       int            `json:"limit"` // the most rolls which may be made
+	Interval    time.Duration  `json:"interval"`
+	Again       int64          `json:"again"`
+	Rote        bool           `json:"rote"`
+	Successes   int            `json:"successes"`
+	Rolls       int            `json:"rolls"`
+	Exceptional bool           `json:"exceptional"` // the action completes as an exceptional success
+	Choosing    bool           `json:"choosing"`    // an exceptional success was rolled, and its benefit must be chosen
+	Status      ExtendedStatus `json:"status"`
+	StartedAt   time.Time      `json:"startedAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// The ExtendedActionRepository describes the interface to find and store extended actions.
+type ExtendedActionRepository interface {
+	FindActive(ctx context.Context, channel, player string) (*ExtendedAction, error)
+	Store(ctx context.Context, a *ExtendedAction) error
+}
+
+// An Initiative is the turn order of a combat in a channel
+type Initiative struct {
+	ID        *snowflake.ID      `json:"id"`
+	Guild     string             `json:"guild"`
+	Channel   string             `json:"channel"`
+	System    string             `json:"system"`  // the roll system initiative is rolled with
+	Message   string             `json:"message"` // the pinned message showing the order
+	Round     int                `json:"round"`   // zero until the first turn is taken
+	Turn      int                `json:"turn"`    // the index of the entry whose turn it is
+	Entries   []*InitiativeEntry `json:"entries"`
+	StartedAt time.Time          `json:"startedAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+	EndedAt   *time.Time         `json:"endedAt,omitempty"`
+}
+
+// An InitiativeEntry is a character, or a non-player character, in the turn order
+type InitiativeEntry struct {
+	Name      string        `json:"name"`
+	Player    string        `json:"player"` // the player who added the entry
+	Character *snowflake.ID `json:"character,omitempty"`
+	Modifier  int           `json:"modifier"`
+	Score     int           `json:"score"`
+	NPC       bool          `json:"npc"`
+	Delayed   bool          `json:"delayed"`
+}
+
+// The InitiativeRepository describes the interface to find and store initiatives.
+type InitiativeRepository interface {
+	FindActive(ctx context.Context, channel string) (*Initiative, error)
+	Store(ctx context.Context, i *Initiative) error
+}
+
+// An NPC is a quick stat block for a non-player character, owned by a campaign and run by its
+// storytellers rather than by a player
+type NPC struct {
+	ID       *snowflake.ID  `json:"id"`
+	Campaign *snowflake.ID  `json:"campaign"`
+	Name     string         `json:"name"`
+	Pools    map[string]int `json:"pools"` // dice pools by name, such as brawl or intimidation
+	Health   int            `json:"health"`
+	Defense  int            `json:"defense"`
+	Notes    string         `json:"notes"`
+	Hidden   bool           `json:"hidden"` // hidden from the players of the campaign
+}
+
+// The NPCRepository describes the interface to find, store and delete npcs.
+type NPCRepository interface {
+	FindByCampaign(ctx context.Context, id string) ([]*NPC, error)
+	Store(ctx context.Context, n *NPC) error
+	Delete(ctx context.Context, id string) error
+}
+
+// A Macro is a roll a player saved to make again by name, for all of their characters or for one
+type Macro struct {
+	ID        *snowflake.ID `json:"id"`
+	Player    string        `json:"player"`
+	Character *snowflake.ID `json:"character,omitempty"` // the character the macro is for; every character if nil
+	Name      string        `json:"name"`
+	Roll      string        `json:"roll"` // the flags and dice of the roll, with parameters such as {mod}
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// The MacroRepository describes the interface to find, store and delete macros.
+type MacroRepository interface {
+	FindByPlayer(ctx context.Context, player string) ([]*Macro, error)
+	Store(ctx context.Context, m *Macro) error
+	Delete(ctx context.Context, id string) error
+}
+
+// A Move is a Powered by the Apocalypse move defined for a campaign, with the text read out for
+// each tier of its result
+type Move struct {
+	ID       *snowflake.ID `json:"id"`
+	Campaign *snowflake.ID `json:"campaign"`
+	Name     string        `json:"name"`
+	Stat     string        `json:"stat"`    // the stat the move is rolled with, such as hard
+	Hit      string        `json:"hit"`     // the text of a strong hit, on 10+
+	Partial  string        `json:"partial"` // the text of a weak hit, on 7-9
+	Miss     string        `json:"miss"`    // the text of a miss, on 6-
+}
+
+// The MoveRepository describes the interface to find, store and delete moves.
+type MoveRepository interface {
+	FindByCampaign(ctx context.Context, id string) ([]*Move, error)
+	Store(ctx context.Context, m *Move) error
+	Delete(ctx context.Context, id string) error
+}
+
+// RollModifiers are the forward and ongoing modifiers a character carries into their Powered by the
+// Apocalypse rolls
+type RollModifiers struct {
+	Character *snowflake.ID `json:"character"`
+	Forward   int           `json:"forward"` // added to the character's next roll only
+	Ongoing   int           `json:"ongoing"` // added to every roll until it is cleared
+}
+
+// The RollModifiersRepository describes the interface to find and store roll modifiers.
+type RollModifiersRepository interface {
+	FindByCharacter(ctx context.Context, id string) (*RollModifiers, error)
+	Store(ctx context.Context, m *RollModifiers) error
+}
+
+// A RollQuery selects kept rolls, newest first.  Empty fields select every roll.
+type RollQuery struct {
+	Guild     string
+	Channel   string
+	Character string
+	Before    string // only select rolls older than the roll with this id
+	Limit     int
+}
+
+// The RollRepository describes the interface to find and store rolls.
+type RollRepository interface {
+	Find(ctx context.Context, q *RollQuery) ([]*Roll, error)
+	FindByID(ctx context.Context, id string) (*Roll, error)
+	Store(ctx context.Context, r *Roll) error
+}
+
+// GuildSettings are the configuration of the bot for a single guild.  Empty fields use the
+// bot's defaults.
+type GuildSettings struct {
+	Guild        string   `json:"guild"`
+	Prefix       string   `json:"prefix"`
+	RollSystem   string   `json:"rollSystem"`
+	SheetSystem  string   `json:"sheetSystem"`
+	Verbose      bool     `json:"verbose"`
+	InlineRolls  bool     `json:"inlineRolls"` // whether [[...]] in ordinary messages is rolled
+	RollChannels []string `json:"rollChannels"`
+	Storytellers []string `json:"storytellers"` // the discord roles whose members are storytellers
+}
+
+// The GuildSettingsRepository describes the interface to find and store guild settings.
+type GuildSettingsRepository interface {
+	FindByGuild(ctx context.Context, guild string) (*GuildSettings, error)
+	Store(ctx context.Context, s *GuildSettings) error
+}
+
+// A Role is the part a user plays in a guild, which determines what they are permitted to do.
+type Role int
+
+// The roles, from least to most permitted
+const (
+	RoleNone Role = iota
+	RolePlayer
+	RoleStoryteller
+	RoleOwner
+)
+
 // A Sheet is a type of character sheet.
 type Sheet interface {
 	System() string
@@ -46,6 +46,19 @@ func (mr *MockCharacterRepositoryMockRecorder) FindByPlayer(ctx, id interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPlayer", reflect.TypeOf((*MockCharacterRepository)(nil).FindByPlayer), ctx, id)
 }
 
+// FindByGuild mocks base method
+func (m *MockCharacterRepository) FindByGuild(ctx context.Context, id string) ([]*Character, error) {
+	ret := m.ctrl.Call(m, "FindByGuild", ctx, id)
+	ret0, _ := ret[0].([]*Character)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByGuild indicates an expected call of FindByGuild
+func (mr *MockCharacterRepositoryMockRecorder) FindByGuild(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByGuild", reflect.TypeOf((*MockCharacterRepository)(nil).FindByGuild), ctx, id)
+}
+
 // FindByID mocks base method
 func (m *MockCharacterRepository) FindByID(ctx context.Context, id string) (*Character, error) {
 	ret := m.ctrl.Call(m, "FindByID", ctx, id)
@@ -71,6 +84,656 @@ func (mr *MockCharacterRepositoryMockRecorder) Store(ctx, c interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCharacterRepository)(nil).Store), ctx, c)
 }
 
+// MockSheetRevisionRepository is a mock of SheetRevisionRepository interface
+type MockSheetRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSheetRevisionRepositoryMockRecorder
+}
+
+// MockSheetRevisionRepositoryMockRecorder is the mock recorder for MockSheetRevisionRepository
+type MockSheetRevisionRepositoryMockRecorder struct {
+	mock *MockSheetRevisionRepository
+}
+
+// NewMockSheetRevisionRepository creates a new mock instance
+func NewMockSheetRevisionRepository(ctrl *gomock.Controller) *MockSheetRevisionRepository {
+	mock := &MockSheetRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockSheetRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSheetRevisionRepository) EXPECT() *MockSheetRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockSheetRevisionRepository) Store(ctx context.Context, r *SheetRevision) error {
+	ret := m.ctrl.Call(m, "Store", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockSheetRevisionRepositoryMockRecorder) Store(ctx, r interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockSheetRevisionRepository)(nil).Store), ctx, r)
+}
+
+// MockCampaignRepository is a mock of CampaignRepository interface
+type MockCampaignRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCampaignRepositoryMockRecorder
+}
+
+// MockCampaignRepositoryMockRecorder is the mock recorder for MockCampaignRepository
+type MockCampaignRepositoryMockRecorder struct {
+	mock *MockCampaignRepository
+}
+
+// NewMockCampaignRepository creates a new mock instance
+func NewMockCampaignRepository(ctrl *gomock.Controller) *MockCampaignRepository {
+	mock := &MockCampaignRepository{ctrl: ctrl}
+	mock.recorder = &MockCampaignRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCampaignRepository) EXPECT() *MockCampaignRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByGuild mocks base method
+func (m *MockCampaignRepository) FindByGuild(ctx context.Context, id string) ([]*Campaign, error) {
+	ret := m.ctrl.Call(m, "FindByGuild", ctx, id)
+	ret0, _ := ret[0].([]*Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByGuild indicates an expected call of FindByGuild
+func (mr *MockCampaignRepositoryMockRecorder) FindByGuild(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByGuild", reflect.TypeOf((*MockCampaignRepository)(nil).FindByGuild), ctx, id)
+}
+
+// FindByID mocks base method
+func (m *MockCampaignRepository) FindByID(ctx context.Context, id string) (*Campaign, error) {
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockCampaignRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockCampaignRepository)(nil).FindByID), ctx, id)
+}
+
+// Store mocks base method
+func (m *MockCampaignRepository) Store(ctx context.Context, c *Campaign) error {
+	ret := m.ctrl.Call(m, "Store", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockCampaignRepositoryMockRecorder) Store(ctx, c interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCampaignRepository)(nil).Store), ctx, c)
+}
+
+// MockRollRepository is a mock of RollRepository interface
+type MockRollRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollRepositoryMockRecorder
+}
+
+// MockRollRepositoryMockRecorder is the mock recorder for MockRollRepository
+type MockRollRepositoryMockRecorder struct {
+	mock *MockRollRepository
+}
+
+// NewMockRollRepository creates a new mock instance
+func NewMockRollRepository(ctrl *gomock.Controller) *MockRollRepository {
+	mock := &MockRollRepository{ctrl: ctrl}
+	mock.recorder = &MockRollRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRollRepository) EXPECT() *MockRollRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Find mocks base method
+func (m *MockRollRepository) Find(ctx context.Context, q *RollQuery) ([]*Roll, error) {
+	ret := m.ctrl.Call(m, "Find", ctx, q)
+	ret0, _ := ret[0].([]*Roll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockRollRepositoryMockRecorder) Find(ctx, q interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRollRepository)(nil).Find), ctx, q)
+}
+
+// FindByID mocks base method
+func (m *MockRollRepository) FindByID(ctx context.Context, id string) (*Roll, error) {
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*Roll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockRollRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRollRepository)(nil).FindByID), ctx, id)
+}
+
+// Store mocks base method
+func (m *MockRollRepository) Store(ctx context.Context, r *Roll) error {
+	ret := m.ctrl.Call(m, "Store", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockRollRepositoryMockRecorder) Store(ctx, r interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockRollRepository)(nil).Store), ctx, r)
+}
+
+// MockFairSessionRepository is a mock of FairSessionRepository interface
+type MockFairSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFairSessionRepositoryMockRecorder
+}
+
+// MockFairSessionRepositoryMockRecorder is the mock recorder for MockFairSessionRepository
+type MockFairSessionRepositoryMockRecorder struct {
+	mock *MockFairSessionRepository
+}
+
+// NewMockFairSessionRepository creates a new mock instance
+func NewMockFairSessionRepository(ctrl *gomock.Controller) *MockFairSessionRepository {
+	mock := &MockFairSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockFairSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFairSessionRepository) EXPECT() *MockFairSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindActive mocks base method
+func (m *MockFairSessionRepository) FindActive(ctx context.Context, channel string) (*FairSession, error) {
+	ret := m.ctrl.Call(m, "FindActive", ctx, channel)
+	ret0, _ := ret[0].(*FairSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActive indicates an expected call of FindActive
+func (mr *MockFairSessionRepositoryMockRecorder) FindActive(ctx, channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActive", reflect.TypeOf((*MockFairSessionRepository)(nil).FindActive), ctx, channel)
+}
+
+// FindByID mocks base method
+func (m *MockFairSessionRepository) FindByID(ctx context.Context, id string) (*FairSession, error) {
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*FairSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockFairSessionRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockFairSessionRepository)(nil).FindByID), ctx, id)
+}
+
+// Store mocks base method
+func (m *MockFairSessionRepository) Store(ctx context.Context, s *FairSession) error {
+	ret := m.ctrl.Call(m, "Store", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockFairSessionRepositoryMockRecorder) Store(ctx, s interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockFairSessionRepository)(nil).Store), ctx, s)
+}
+
+// MockSignedBatchRepository is a mock of SignedBatchRepository interface
+type MockSignedBatchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignedBatchRepositoryMockRecorder
+}
+
+// MockSignedBatchRepositoryMockRecorder is the mock recorder for MockSignedBatchRepository
+type MockSignedBatchRepositoryMockRecorder struct {
+	mock *MockSignedBatchRepository
+}
+
+// NewMockSignedBatchRepository creates a new mock instance
+func NewMockSignedBatchRepository(ctrl *gomock.Controller) *MockSignedBatchRepository {
+	mock := &MockSignedBatchRepository{ctrl: ctrl}
+	mock.recorder = &MockSignedBatchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSignedBatchRepository) EXPECT() *MockSignedBatchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByID mocks base method
+func (m *MockSignedBatchRepository) FindByID(ctx context.Context, id string) (*SignedBatch, error) {
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*SignedBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockSignedBatchRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockSignedBatchRepository)(nil).FindByID), ctx, id)
+}
+
+// Store mocks base method
+func (m *MockSignedBatchRepository) Store(ctx context.Context, b *SignedBatch) error {
+	ret := m.ctrl.Call(m, "Store", ctx, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockSignedBatchRepositoryMockRecorder) Store(ctx, b interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockSignedBatchRepository)(nil).Store), ctx, b)
+}
+
+// PublishAll mocks base method
+func (m *MockSignedBatchRepository) PublishAll(ctx context.Context) error {
+	ret := m.ctrl.Call(m, "PublishAll", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishAll indicates an expected call of PublishAll
+func (mr *MockSignedBatchRepositoryMockRecorder) PublishAll(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAll", reflect.TypeOf((*MockSignedBatchRepository)(nil).PublishAll), ctx)
+}
+
+// MockExtendedActionRepository is a mock of ExtendedActionRepository interface
+type MockExtendedActionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExtendedActionRepositoryMockRecorder
+}
+
+// MockExtendedActionRepositoryMockRecorder is the mock recorder for MockExtendedActionRepository
+type MockExtendedActionRepositoryMockRecorder struct {
+	mock *MockExtendedActionRepository
+}
+
+// NewMockExtendedActionRepository creates a new mock instance
+func NewMockExtendedActionRepository(ctrl *gomock.Controller) *MockExtendedActionRepository {
+	mock := &MockExtendedActionRepository{ctrl: ctrl}
+	mock.recorder = &MockExtendedActionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockExtendedActionRepository) EXPECT() *MockExtendedActionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindActive mocks base method
+func (m *MockExtendedActionRepository) FindActive(ctx context.Context, channel, player string) (*ExtendedAction, error) {
+	ret := m.ctrl.Call(m, "FindActive", ctx, channel, player)
+	ret0, _ := ret[0].(*ExtendedAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActive indicates an expected call of FindActive
+func (mr *MockExtendedActionRepositoryMockRecorder) FindActive(ctx, channel, player interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActive", reflect.TypeOf((*MockExtendedActionRepository)(nil).FindActive), ctx, channel, player)
+}
+
+// Store mocks base method
+func (m *MockExtendedActionRepository) Store(ctx context.Context, a *ExtendedAction) error {
+	ret := m.ctrl.Call(m, "Store", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockExtendedActionRepositoryMockRecorder) Store(ctx, a interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockExtendedActionRepository)(nil).Store), ctx, a)
+}
+
+// MockInitiativeRepository is a mock of InitiativeRepository interface
+type MockInitiativeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInitiativeRepositoryMockRecorder
+}
+
+// MockInitiativeRepositoryMockRecorder is the mock recorder for MockInitiativeRepository
+type MockInitiativeRepositoryMockRecorder struct {
+	mock *MockInitiativeRepository
+}
+
+// NewMockInitiativeRepository creates a new mock instance
+func NewMockInitiativeRepository(ctrl *gomock.Controller) *MockInitiativeRepository {
+	mock := &MockInitiativeRepository{ctrl: ctrl}
+	mock.recorder = &MockInitiativeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInitiativeRepository) EXPECT() *MockInitiativeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindActive mocks base method
+func (m *MockInitiativeRepository) FindActive(ctx context.Context, channel string) (*Initiative, error) {
+	ret := m.ctrl.Call(m, "FindActive", ctx, channel)
+	ret0, _ := ret[0].(*Initiative)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActive indicates an expected call of FindActive
+func (mr *MockInitiativeRepositoryMockRecorder) FindActive(ctx, channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActive", reflect.TypeOf((*MockInitiativeRepository)(nil).FindActive), ctx, channel)
+}
+
+// Store mocks base method
+func (m *MockInitiativeRepository) Store(ctx context.Context, i *Initiative) error {
+	ret := m.ctrl.Call(m, "Store", ctx, i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockInitiativeRepositoryMockRecorder) Store(ctx, i interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockInitiativeRepository)(nil).Store), ctx, i)
+}
+
+// MockNPCRepository is a mock of NPCRepository interface
+type MockNPCRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNPCRepositoryMockRecorder
+}
+
+// MockNPCRepositoryMockRecorder is the mock recorder for MockNPCRepository
+type MockNPCRepositoryMockRecorder struct {
+	mock *MockNPCRepository
+}
+
+// NewMockNPCRepository creates a new mock instance
+func NewMockNPCRepository(ctrl *gomock.Controller) *MockNPCRepository {
+	mock := &MockNPCRepository{ctrl: ctrl}
+	mock.recorder = &MockNPCRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNPCRepository) EXPECT() *MockNPCRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method
+func (m *MockNPCRepository) Delete(arg0 context.Context, arg1 string) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockNPCRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNPCRepository)(nil).Delete), arg0, arg1)
+}
+
+// FindByCampaign mocks base method
+func (m *MockNPCRepository) FindByCampaign(arg0 context.Context, arg1 string) ([]*NPC, error) {
+	ret := m.ctrl.Call(m, "FindByCampaign", arg0, arg1)
+	ret0, _ := ret[0].([]*NPC)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCampaign indicates an expected call of FindByCampaign
+func (mr *MockNPCRepositoryMockRecorder) FindByCampaign(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCampaign", reflect.TypeOf((*MockNPCRepository)(nil).FindByCampaign), arg0, arg1)
+}
+
+// Store mocks base method
+func (m *MockNPCRepository) Store(arg0 context.Context, arg1 *NPC) error {
+	ret := m.ctrl.Call(m, "Store", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockNPCRepositoryMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockNPCRepository)(nil).Store), arg0, arg1)
+}
+
+// MockMacroRepository is a mock of MacroRepository interface
+type MockMacroRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMacroRepositoryMockRecorder
+}
+
+// MockMacroRepositoryMockRecorder is the mock recorder for MockMacroRepository
+type MockMacroRepositoryMockRecorder struct {
+	mock *MockMacroRepository
+}
+
+// NewMockMacroRepository creates a new mock instance
+func NewMockMacroRepository(ctrl *gomock.Controller) *MockMacroRepository {
+	mock := &MockMacroRepository{ctrl: ctrl}
+	mock.recorder = &MockMacroRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMacroRepository) EXPECT() *MockMacroRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method
+func (m *MockMacroRepository) Delete(arg0 context.Context, arg1 string) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockMacroRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMacroRepository)(nil).Delete), arg0, arg1)
+}
+
+// FindByPlayer mocks base method
+func (m *MockMacroRepository) FindByPlayer(arg0 context.Context, arg1 string) ([]*Macro, error) {
+	ret := m.ctrl.Call(m, "FindByPlayer", arg0, arg1)
+	ret0, _ := ret[0].([]*Macro)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPlayer indicates an expected call of FindByPlayer
+func (mr *MockMacroRepositoryMockRecorder) FindByPlayer(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPlayer", reflect.TypeOf((*MockMacroRepository)(nil).FindByPlayer), arg0, arg1)
+}
+
+// Store mocks base method
+func (m *MockMacroRepository) Store(arg0 context.Context, arg1 *Macro) error {
+	ret := m.ctrl.Call(m, "Store", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockMacroRepositoryMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockMacroRepository)(nil).Store), arg0, arg1)
+}
+
+// MockMoveRepository is a mock of MoveRepository interface
+type MockMoveRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMoveRepositoryMockRecorder
+}
+
+// MockMoveRepositoryMockRecorder is the mock recorder for MockMoveRepository
+type MockMoveRepositoryMockRecorder struct {
+	mock *MockMoveRepository
+}
+
+// NewMockMoveRepository creates a new mock instance
+func NewMockMoveRepository(ctrl *gomock.Controller) *MockMoveRepository {
+	mock := &MockMoveRepository{ctrl: ctrl}
+	mock.recorder = &MockMoveRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMoveRepository) EXPECT() *MockMoveRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByCampaign mocks base method
+func (m *MockMoveRepository) FindByCampaign(arg0 context.Context, arg1 string) ([]*Move, error) {
+	ret := m.ctrl.Call(m, "FindByCampaign", arg0, arg1)
+	ret0, _ := ret[0].([]*Move)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCampaign indicates an expected call of FindByCampaign
+func (mr *MockMoveRepositoryMockRecorder) FindByCampaign(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCampaign", reflect.TypeOf((*MockMoveRepository)(nil).FindByCampaign), arg0, arg1)
+}
+
+// Store mocks base method
+func (m *MockMoveRepository) Store(arg0 context.Context, arg1 *Move) error {
+	ret := m.ctrl.Call(m, "Store", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockMoveRepositoryMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockMoveRepository)(nil).Store), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockMoveRepository) Delete(arg0 context.Context, arg1 string) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockMoveRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMoveRepository)(nil).Delete), arg0, arg1)
+}
+
+// MockRollModifiersRepository is a mock of RollModifiersRepository interface
+type MockRollModifiersRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollModifiersRepositoryMockRecorder
+}
+
+// MockRollModifiersRepositoryMockRecorder is the mock recorder for MockRollModifiersRepository
+type MockRollModifiersRepositoryMockRecorder struct {
+	mock *MockRollModifiersRepository
+}
+
+// NewMockRollModifiersRepository creates a new mock instance
+func NewMockRollModifiersRepository(ctrl *gomock.Controller) *MockRollModifiersRepository {
+	mock := &MockRollModifiersRepository{ctrl: ctrl}
+	mock.recorder = &MockRollModifiersRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRollModifiersRepository) EXPECT() *MockRollModifiersRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByCharacter mocks base method
+func (m *MockRollModifiersRepository) FindByCharacter(arg0 context.Context, arg1 string) (*RollModifiers, error) {
+	ret := m.ctrl.Call(m, "FindByCharacter", arg0, arg1)
+	ret0, _ := ret[0].(*RollModifiers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCharacter indicates an expected call of FindByCharacter
+func (mr *MockRollModifiersRepositoryMockRecorder) FindByCharacter(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCharacter", reflect.TypeOf((*MockRollModifiersRepository)(nil).FindByCharacter), arg0, arg1)
+}
+
+// Store mocks base method
+func (m *MockRollModifiersRepository) Store(arg0 context.Context, arg1 *RollModifiers) error {
+	ret := m.ctrl.Call(m, "Store", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockRollModifiersRepositoryMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockRollModifiersRepository)(nil).Store), arg0, arg1)
+}
+
+// MockGuildSettingsRepository is a mock of GuildSettingsRepository interface
+type MockGuildSettingsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGuildSettingsRepositoryMockRecorder
+}
+
+// MockGuildSettingsRepositoryMockRecorder is the mock recorder for MockGuildSettingsRepository
+type MockGuildSettingsRepositoryMockRecorder struct {
+	mock *MockGuildSettingsRepository
+}
+
+// NewMockGuildSettingsRepository creates a new mock instance
+func NewMockGuildSettingsRepository(ctrl *gomock.Controller) *MockGuildSettingsRepository {
+	mock := &MockGuildSettingsRepository{ctrl: ctrl}
+	mock.recorder = &MockGuildSettingsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGuildSettingsRepository) EXPECT() *MockGuildSettingsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByGuild mocks base method
+func (m *MockGuildSettingsRepository) FindByGuild(ctx context.Context, guild string) (*GuildSettings, error) {
+	ret := m.ctrl.Call(m, "FindByGuild", ctx, guild)
+	ret0, _ := ret[0].(*GuildSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByGuild indicates an expected call of FindByGuild
+func (mr *MockGuildSettingsRepositoryMockRecorder) FindByGuild(ctx, guild interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByGuild", reflect.TypeOf((*MockGuildSettingsRepository)(nil).FindByGuild), ctx, guild)
+}
+
+// Store mocks base method
+func (m *MockGuildSettingsRepository) Store(ctx context.Context, s *GuildSettings) error {
+	ret := m.ctrl.Call(m, "Store", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockGuildSettingsRepositoryMockRecorder) Store(ctx, s interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockGuildSettingsRepository)(nil).Store), ctx, s)
+}
+
 // MockSheet is a mock of Sheet interface
 type MockSheet struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,221 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrCampaignGuild is thrown when a campaign command is used outside of a server
+var ErrCampaignGuild = errors.New("campaigns can only be run within a server")
+
+// campaignSubcommands are the subcommands of the campaign command; info is the default
+var campaignSubcommands = []string{"create", "join", "info", "link"}
+
+// campaignFlags declares the flags accepted by the campaign command and its subcommands
+func campaignFlags(fs *flag.FlagSet) {
+	fs.String("system", "", "the character system of a new campaign (create)")
+	fs.String("character", "", "the character to join with (join)")
+}
+
+// Campaign creates, joins, links or describes the campaigns of a guild
+func (bs *BotServiceHandler) Campaign(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrCampaignGuild
+	}
+	fields = subcommandFirst(fields, campaignSubcommands...)
+	sub := "info"
+	if len(fields) > 0 && util.ContainsString(campaignSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("campaign")
+	var system, character string
+	fs.StringVar(&system, "system", "", "the character system of a new campaign")
+	fs.StringVar(&character, "character", "", "the character to join with")
+	err := fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "campaign", err)
+	}
+	name := strings.Join(fs.Args(), " ")
+	switch sub {
+	case "create":
+		return bs.createCampaign(ctx, msg, name, system)
+	case "join":
+		return bs.joinCampaign(ctx, msg, name, character)
+	case "link":
+		return bs.linkCampaign(ctx, msg, name)
+	}
+	return bs.campaignInfo(ctx, msg, name)
+}
+
+// createCampaign creates a new campaign, run by the storyteller who created it
+func (bs *BotServiceHandler) createCampaign(ctx context.Context, msg *discordgo.MessageCreate, name, system string) (*BotResponse, error) {
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = permission.Check(role, msg.Author.ID, permission.ManageCampaign, nil)
+	if err != nil {
+		return nil, err
+	}
+	if system == "" {
+		gs, err := bs.guildSettings(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		system = gs.SheetSystem
+	}
+	repo := bs.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.Create(ctx, repo, msg.GuildID, name, system, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("created %s; link its channels with `%scampaign link %s`", c.Name, commandPrefix(msg, "campaign"), c.Name)}, nil
+}
+
+// joinCampaign joins a campaign, optionally attaching one of the player's characters to it
+func (bs *BotServiceHandler) joinCampaign(ctx context.Context, msg *discordgo.MessageCreate, name, character string) (*BotResponse, error) {
+	repo := bs.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.Find(ctx, repo, msg.GuildID, msg.ChannelID, name)
+	if err != nil {
+		return nil, err
+	}
+	if character == "" {
+		err = campaign.Join(ctx, repo, c, msg.Author.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("you have joined %s", c.Name)}, nil
+	}
+	chars := bs.db.Repository("character").(domains.CharacterRepository)
+	player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
+	guild, _ := strconv.ParseInt(msg.GuildID, 10, 64)
+	char, err := sheet.FindByName(ctx, chars, guild, player, character)
+	if err != nil {
+		return nil, err
+	}
+	err = campaign.Attach(ctx, repo, chars, c, char)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("%s has joined %s", char.Name, c.Name)}, nil
+}
+
+// linkCampaign links the channel to a campaign
+func (bs *BotServiceHandler) linkCampaign(ctx context.Context, msg *discordgo.MessageCreate, name string) (*BotResponse, error) {
+	repo := bs.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.FindByName(ctx, repo, msg.GuildID, name)
+	if err != nil {
+		return nil, err
+	}
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	role = permission.InCampaign(role, msg.Author.ID, c)
+	err = permission.Check(role, msg.Author.ID, permission.ManageCampaign, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = campaign.Link(ctx, repo, c, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("<#%s> is now linked to %s", msg.ChannelID, c.Name)}, nil
+}
+
+// campaignInfo describes a campaign, by default the one linked to the channel
+func (bs *BotServiceHandler) campaignInfo(ctx context.Context, msg *discordgo.MessageCreate, name string) (*BotResponse, error) {
+	repo := bs.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.Find(ctx, repo, msg.GuildID, msg.ChannelID, name)
+	if err != nil {
+		return nil, err
+	}
+	chars, err := campaign.Characters(ctx, bs.db.Repository("character").(domains.CharacterRepository), c)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{campaignEmbed(c, chars)}}, nil
+}
+
+// campaignEmbed renders a campaign and its characters as a discord embed
+func campaignEmbed(c *domains.Campaign, chars []*domains.Character) *discordgo.MessageEmbed {
+	names := make([]string, 0, len(chars))
+	for _, char := range chars {
+		names = append(names, fmt.Sprintf("%s (<@%s>)", char.Name, char.Player))
+	}
+	return &discordgo.MessageEmbed{
+		Title: c.Name,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "System", Value: c.System, Inline: true},
+			{Name: "Storytellers", Value: mentions("<@%s>", c.Storytellers), Inline: true},
+			{Name: "Members", Value: mentions("<@%s>", c.Members), Inline: true},
+			{Name: "Channels", Value: mentions("<#%s>", c.Channels)},
+			{Name: "Characters", Value: util.Truncate(orNobody(strings.Join(names, "\n")), embedFieldLimit)},
+		},
+	}
+}
+
+// mentions formats a list of discord ids as mentions
+func mentions(format string, ids []string) string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		formatted = append(formatted, fmt.Sprintf(format, id))
+	}
+	return util.Truncate(orNobody(strings.Join(formatted, " ")), embedFieldLimit)
+}
+
+func orNobody(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// completeCampaignArgs suggests subcommands and the names of the guild's campaigns
+func (bs *BotServiceHandler) completeCampaignArgs(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error) {
+	fields := strings.SplitN(partial, " ", 2)
+	if len(fields) < 2 || !util.ContainsString(campaignSubcommands, fields[0]) || fields[0] == "create" {
+		return matching(campaignSubcommands, partial), nil
+	}
+	campaigns, err := bs.db.Repository("campaign").(domains.CampaignRepository).FindByGuild(ctx, msg.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(campaigns))
+	for _, c := range campaigns {
+		names = append(names, fields[0]+" "+c.Name)
+	}
+	return matching(names, partial), nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/pkg/errors"
+)
+
+// ErrNotAdmin is thrown when someone other than a server admin tries to configure the bot
+var ErrNotAdmin = errors.New("only server admins can configure slate")
+
+// ErrNoGuild is thrown when the bot is configured from outside of a server
+var ErrNoGuild = errors.New("slate can only be configured from within a server")
+
+// Config shows or changes the settings for the guild
+func (bs *BotServiceHandler) Config(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrNoGuild
+	}
+	repo := bs.db.Repository("guild").(domains.GuildSettingsRepository)
+	if len(fields) == 0 {
+		s, err := config.Get(ctx, repo, msg.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{configEmbed(s)}}, nil
+	}
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !permission.Can(role, msg.Author.ID, permission.Configure, nil) {
+		return nil, ErrNotAdmin
+	}
+	_, err = config.Set(ctx, repo, msg.GuildID, strings.ToLower(fields[0]), fields[1:])
+	if err != nil {
+		return nil, err
+	}
+	s, err := config.Get(ctx, repo, msg.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("%s is now %s", fields[0], configValue(s, strings.ToLower(fields[0])))}, nil
+}
+
+// configEmbed renders the settings for a guild as a discord embed
+func configEmbed(s *domains.GuildSettings) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{Title: "Settings"}
+	for _, key := range config.Keys {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   key,
+			Value:  configValue(s, key),
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+func configValue(s *domains.GuildSettings, key string) string {
+	switch key {
+	case config.KeyPrefix:
+		if s.Prefix == "" {
+			return "the default"
+		}
+		return "`" + s.Prefix + "`"
+	case config.KeyRollSystem:
+		return s.RollSystem
+	case config.KeySheetSystem:
+		return s.SheetSystem
+	case config.KeyVerbose:
+		return fmt.Sprint(s.Verbose)
+	case config.KeyInlineRolls:
+		return fmt.Sprint(s.InlineRolls)
+	case config.KeyRollChannels:
+		if len(s.RollChannels) == 0 {
+			return "all channels"
+		}
+		channels := make([]string, 0, len(s.RollChannels))
+		for _, ch := range s.RollChannels {
+			channels = append(channels, "<#"+ch+">")
+		}
+		return strings.Join(channels, " ")
+	case config.KeyStorytellers:
+		if len(s.Storytellers) == 0 {
+			return "server admins only"
+		}
+		roles := make([]string, 0, len(s.Storytellers))
+		for _, role := range s.Storytellers {
+			roles = append(roles, "<@&"+role+">")
+		}
+		return strings.Join(roles, " ")
+	}
+	return ""
+}
+
+// guildSettings retrieves the settings for the guild a message was sent in
+func (bs *BotServiceHandler) guildSettings(ctx context.Context, msg *discordgo.MessageCreate) (*domains.GuildSettings, error) {
+	repo := bs.db.Repository("guild").(domains.GuildSettingsRepository)
+	return config.Get(ctx, repo, msg.GuildID)
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/extended"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// extendedSubcommands are the subcommands of the extended command
+var extendedSubcommands = []string{"start", "roll", "choose", "abandon"}
+
+// extendedOptions are the flags of the extended command
+type extendedOptions struct {
+	target, pool, skill, limit, modifier int
+	interval                             time.Duration
+	again                                int64
+	rote                                 bool
+}
+
+func (o *extendedOptions) flags(fs *flag.FlagSet) {
+	fs.IntVar(&o.target, "target", 0, "the successes needed to complete a new action")
+	fs.IntVar(&o.pool, "pool", 0, "the dice pool of a new action")
+	fs.IntVar(&o.skill, "skill", 0, "the dots of the skill rolled, which an exceptional success may lower the target by")
+	fs.IntVar(&o.limit, "limit", 0, "the most rolls which may be made; the pool by default")
+	fs.DurationVar(&o.interval, "interval", 0, "the time each roll takes, such as 30m")
+	fs.Int64Var(&o.again, "again", 10, "the n-again of every roll")
+	fs.BoolVar(&o.rote, "rote", false, "whether every roll is a rote action")
+	fs.IntVar(&o.modifier, "modifier", 0, "dice added to or taken from the next roll")
+}
+
+// extendedFlags declares the flags accepted by the extended command
+func extendedFlags(fs *flag.FlagSet) {
+	new(extendedOptions).flags(fs)
+}
+
+// parseInterleaved parses flags which may follow the positional arguments, as in
+// `start "Research the ritual" -target 10`, and returns the arguments
+func parseInterleaved(fs *flag.FlagSet, fields []string) ([]string, error) {
+	args := make([]string, 0)
+	for {
+		err := fs.Parse(fields)
+		if err != nil {
+			return nil, err
+		}
+		fields = fs.Args()
+		if len(fields) == 0 {
+			return args, nil
+		}
+		args = append(args, fields[0])
+		fields = fields[1:]
+	}
+}
+
+// Extended starts, rolls, or shows the Chronicles of Darkness extended action of a player
+func (bs *BotServiceHandler) Extended(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = subcommandFirst(fields, extendedSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(extendedSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("extended")
+	opts := new(extendedOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "extended", err)
+	}
+	repo := bs.db.Repository("extended").(domains.ExtendedActionRepository)
+	if sub == "start" {
+		a := &domains.ExtendedAction{
+			Guild:    msg.GuildID,
+			Channel:  msg.ChannelID,
+			Player:   msg.Author.ID,
+			Name:     strings.Trim(strings.Join(args, " "), `"“”`),
+			Target:   opts.target,
+			Pool:     opts.pool,
+			Skill:    opts.skill,
+			Limit:    opts.limit,
+			Interval: opts.interval,
+			Again:    opts.again,
+			Rote:     opts.rote,
+		}
+		if a.Name == "" {
+			return nil, bs.usageError(msg, "extended", errors.New("name the action you are starting"))
+		}
+		err = extended.Start(ctx, repo, a)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{extendedEmbed(a)}}, nil
+	}
+	a, err := extended.Find(ctx, repo, msg.ChannelID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch sub {
+	case "roll":
+		return bs.rollExtended(ctx, msg, repo, a, opts.modifier)
+	case "choose":
+		err = extended.Choose(ctx, repo, a, strings.ToLower(strings.Join(args, " ")))
+	case "abandon":
+		err = extended.Abandon(ctx, repo, a)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{extendedEmbed(a)}}, nil
+}
+
+// rollExtended makes the next roll of an extended action, which is kept like any other roll
+func (bs *BotServiceHandler) rollExtended(ctx context.Context, msg *discordgo.MessageCreate, repo domains.ExtendedActionRepository, a *domains.ExtendedAction, modifier int) (*BotResponse, error) {
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !config.RollAllowed(gs, msg.ChannelID) {
+		return nil, ErrRollChannel
+	}
+	rs, err := extended.Roller(a, modifier)
+	if err != nil {
+		return nil, err
+	}
+	rs.Verbose = gs.Verbose
+	id := bs.db.ID()
+	rec, err := diceFor(ctx, bs.db, bs.rand, msg.ChannelID, id.String(), domains.RollPublic)
+	if err != nil {
+		return nil, err
+	}
+	rs.SetRand(rec.Rand)
+	err = rs.Roll(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "roll failed")
+	}
+	r, err := roll.Record(rs, "cofd", domains.RollPublic)
+	if err != nil {
+		return nil, err
+	}
+	r.ID = id
+	r.Proof = rec.Proof()
+	r.DrawnFrom = rec.DrawnFrom()
+	r.Guild = msg.GuildID
+	r.Channel = msg.ChannelID
+	r.Player = msg.Author.ID
+	r.Source = domains.RollFromBot
+	r.Parameters = fmt.Sprintf("extended roll -modifier=%d", modifier)
+	err = roll.Keep(ctx, bs.db.Repository("roll").(domains.RollRepository), r)
+	if err != nil {
+		return nil, err
+	}
+	err = extended.Apply(ctx, repo, a, rs)
+	if err != nil {
+		return nil, err
+	}
+	response := rollResponse(rs)
+	response.Embeds[0].Footer = rollFooter(r)
+	response.Embeds = append(response.Embeds, extendedEmbed(a))
+	return response, nil
+}
+
+// extendedEmbed describes the progress of an extended action as a discord embed
+func extendedEmbed(a *domains.ExtendedAction) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: util.Truncate(a.Name, embedTitleLimit),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Successes", Value: fmt.Sprintf("%d of %d", a.Successes, a.Target), Inline: true},
+			{Name: "Rolls", Value: fmt.Sprintf("%d of %d", a.Rolls, a.Limit), Inline: true},
+		},
+	}
+	if a.Interval > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Time taken",
+			Value:  fmt.Sprintf("%s, at %s a roll", time.Duration(a.Rolls)*a.Interval, a.Interval),
+			Inline: true,
+		})
+	}
+	switch {
+	case a.Status == domains.ExtendedSucceeded && a.Exceptional:
+		embed.Description = "Completed with an exceptional success!"
+		embed.Color = outcomeColors[roll.OutcomeExceptional]
+	case a.Status == domains.ExtendedSucceeded:
+		embed.Description = "Completed."
+		embed.Color = outcomeColors[roll.OutcomeSuccess]
+	case a.Status == domains.ExtendedFailed:
+		embed.Description = "The rolls ran out before the action was complete."
+		embed.Color = outcomeColors[roll.OutcomeFailure]
+	case a.Status == domains.ExtendedAbandoned:
+		embed.Description = "Abandoned."
+	case a.Choosing:
+		embed.Description = fmt.Sprintf("Exceptional success! Choose %s for it before rolling again.", strings.Join(extended.Choices, ", "))
+		embed.Color = outcomeColors[roll.OutcomeExceptional]
+	default:
+		embed.Description = fmt.Sprintf("The next roll is %d dice.", extended.Dice(a, 0))
+		if a.Exceptional {
+			embed.Description += " It will complete as an exceptional success."
+		}
+	}
+	return embed
+}
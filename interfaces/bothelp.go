@@ -0,0 +1,176 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// mentionLimit is the longest mention of a user, along with the space after it
+const mentionLimit = len("<@18446744073709551615> ")
+
+// usageLimit is the longest usage which fits in a message along with the mention of its author
+const usageLimit = messageLimit - mentionLimit
+
+// ignoredUsage marks a flag which is accepted but has no effect, and so is left out of the help
+const ignoredUsage = "-- ignored --"
+
+// Help describes the available commands, or how to use a single command
+func (bs *BotServiceHandler) Help(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	prefix := commandPrefix(msg, "help")
+	if len(fields) == 0 {
+		lines := make([]string, 0)
+		for _, cmd := range bs.Commands() {
+			lines = append(lines, fmt.Sprintf("`%s%s` %s", prefix, cmd.Name, cmd.Description))
+		}
+		lines = append(lines, fmt.Sprintf("use `%shelp <command>` to learn more about a command", prefix))
+		return &BotResponse{Content: strings.Join(lines, "\n")}, nil
+	}
+	name := strings.TrimPrefix(fields[0], prefix)
+	cmd := bs.command(name)
+	if cmd == nil {
+		return nil, errors.Errorf("there is no %s command; use `%shelp` to list the commands", name, prefix)
+	}
+	return &BotResponse{Content: usage(cmd, prefix)}, nil
+}
+
+// completeCommands suggests the names of commands
+func (bs *BotServiceHandler) completeCommands(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error) {
+	names := make([]string, 0)
+	for _, cmd := range bs.Commands() {
+		names = append(names, cmd.Name)
+	}
+	return matching(names, partial), nil
+}
+
+func (bs *BotServiceHandler) command(name string) *BotCommand {
+	for _, cmd := range bs.Commands() {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// usageError explains a flag parse error with the usage of the command
+func (bs *BotServiceHandler) usageError(msg *discordgo.MessageCreate, name string, err error) error {
+	u := usage(bs.command(name), commandPrefix(msg, name))
+	if err == flag.ErrHelp {
+		return errors.New(u)
+	}
+	return errors.New(util.Truncate(fmt.Sprintf("%s\n%s", err, u), usageLimit))
+}
+
+// newFlagSet creates a flag set which reports parse errors instead of printing them
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// commandPrefix determines the prefix the command was invoked with; slash commands have none
+func commandPrefix(msg *discordgo.MessageCreate, name string) string {
+	i := strings.Index(msg.Content, name)
+	if i <= 0 {
+		return "/"
+	}
+	return msg.Content[:i]
+}
+
+// usage renders the description, flags and examples of a command, cut short if it will not fit in
+// a message
+func usage(cmd *BotCommand, prefix string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s%s**", prefix, cmd.Name)
+	if cmd.Flags != nil {
+		b.WriteString(" [flags]")
+	}
+	if cmd.Args != "" {
+		fmt.Fprintf(&b, " <%s>", cmd.Args)
+	}
+	fmt.Fprintf(&b, "\n%s\n", cmd.Description)
+
+	variants := make([]string, 0, len(cmd.Variants))
+	inVariant := make(map[string]bool)
+	for variant, declare := range cmd.Variants {
+		variants = append(variants, variant)
+		visitFlags(declare, func(f *flag.Flag) { inVariant[f.Name] = true })
+	}
+	sort.Strings(variants)
+	if lines := flagLines(cmd.Flags, inVariant); lines != "" {
+		fmt.Fprintf(&b, "flags:\n%s", lines)
+	}
+	for _, variant := range variants {
+		if lines := flagLines(cmd.Variants[variant], nil); lines != "" {
+			fmt.Fprintf(&b, "flags for %s:\n%s", variant, lines)
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString("examples:\n")
+		for _, example := range cmd.Examples {
+			fmt.Fprintf(&b, "`%s`\n", strings.TrimSpace(prefix+cmd.Name+" "+example))
+		}
+	}
+	return util.Truncate(strings.TrimSpace(b.String()), usageLimit)
+}
+
+// visitFlags visits each flag declared, except those which are ignored
+func visitFlags(declare func(*flag.FlagSet), fn func(*flag.Flag)) {
+	if declare == nil {
+		return
+	}
+	fs := newFlagSet("")
+	declare(fs)
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Usage != ignoredUsage {
+			fn(f)
+		}
+	})
+}
+
+// flagLines renders one line for each declared flag, along with its default
+func flagLines(declare func(*flag.FlagSet), skip map[string]bool) string {
+	var b strings.Builder
+	visitFlags(declare, func(f *flag.Flag) {
+		if skip[f.Name] {
+			return
+		}
+		kind, text := flag.UnquoteUsage(f)
+		fmt.Fprintf(&b, "`%s` %s", strings.TrimSpace("-"+f.Name+" "+kind), text)
+		switch f.DefValue {
+		case "", "false", "0":
+		default:
+			fmt.Fprintf(&b, " (default %s)", f.DefValue)
+		}
+		b.WriteString("\n")
+	})
+	return b.String()
+}
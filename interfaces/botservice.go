@@ -24,14 +24,22 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/interfaces/repositories"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/move"
+	"github.com/kkragenbrink/slate/usecases/permission"
 	"github.com/kkragenbrink/slate/usecases/roll"
 	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
 	"github.com/pkg/errors"
 )
 
@@ -39,6 +47,18 @@ import (
 // todo: this should be a configuration parameter
 const SiteURL = "https://slate.sosly.org"
 
+// ErrRollChannel is thrown when dice are rolled outside of the channels a guild allows
+var ErrRollChannel = errors.New("dice cannot be rolled in this channel")
+
+// embedFieldLimit is the maximum length of the value of a discord embed field
+const embedFieldLimit = 1024
+
+// embedFieldsLimit is the most fields a discord embed may have
+const embedFieldsLimit = 25
+
+// embedTitleLimit is the maximum length of the title of a discord embed
+const embedTitleLimit = 256
+
 // The BotServiceHandler stores information useful to the bot service message handlers
 type BotServiceHandler struct {
 	bot  Bot
@@ -57,64 +77,873 @@ func NewBotServiceHandler(bot Bot, db repositories.Database, rand Random) *BotSe
 
 // A Bot is a repository for discord command handlers
 type Bot interface {
+	AddCommand(*BotCommand) error
 	AddHandler(string, BotHandler) error
+	AddResponseHandler(string, BotResponseHandler) error
 	Channel(string) (*discordgo.Channel, error)
 	Channels(string) ([]*discordgo.Channel, error)
+	EditEmbed(channel, message string, embed *discordgo.MessageEmbed) error
+	Member(guild, user string) (*permission.Member, error)
+	RoleMembers(guild string, roles []string) ([]string, error)
+	SendDirectEmbed(string, *discordgo.MessageEmbed) error
+	SendEmbed(string, *discordgo.MessageEmbed) error
 	SendMessage(string, string) error
+	SendPinnedEmbed(string, *discordgo.MessageEmbed) (string, error)
+	Stats() *BotStats
+	Unpin(channel, message string) error
 	User(string) (*discordgo.User, error)
 }
 
+// BotStats describe the load on the bot's command queues
+type BotStats struct {
+	Workers    int    `json:"workers"`
+	Capacity   int    `json:"capacity"`
+	Queued     int    `json:"queued"`
+	QueueDepth []int  `json:"queueDepth"`
+	Dispatched uint64 `json:"dispatched"`
+	Completed  uint64 `json:"completed"`
+	Rejected   uint64 `json:"rejected"`
+}
+
 // A BotHandler is a message handler for discord messages
 type BotHandler func(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (string, error)
 
+// A BotResponseHandler is a message handler for discord messages which responds with more than
+// plain text
+type BotResponseHandler func(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error)
+
+// A BotCommand describes a command which can be invoked with a message or as a slash command
+type BotCommand struct {
+	Name        string
+	Description string
+	Args        string                         // describes the positional arguments of the command
+	Flags       func(*flag.FlagSet)            // declares the flags the command accepts
+	Complete    map[string]BotComplete         // suggests values for the named flags (or "args")
+	Variants    map[string]func(*flag.FlagSet) // declares flags which only apply to a variant, such as a roll system
+	Examples    []string
+	Handle      BotResponseHandler
+}
+
+// A BotComplete suggests complete values for a partially typed option
+type BotComplete func(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error)
+
+// A BotResponse is a structured response to a discord message
+type BotResponse struct {
+	Content   string
+	Embeds    []*discordgo.MessageEmbed
+	Files     []*discordgo.File
+	Direct    bool     // send the response to the author's direct messages
+	Ephemeral bool     // only show the response to the author, where discord allows it
+	Reactions []string // emoji to react to the original message with
+	Quiet     bool     // mention no one but the author, for content which repeats what the author wrote
+}
+
+// Commands lists the commands handled by the BotServiceHandler
+func (bs *BotServiceHandler) Commands() []*BotCommand {
+	return []*BotCommand{
+		{
+			Name:        "sheet",
+			Description: "Create, import or show a character sheet",
+			Args:        "the character name, optionally preceded by import or show",
+			Flags:       sheetFlags,
+			Complete: map[string]BotComplete{
+				"system": completeFrom(sheet.Systems),
+				"args":   bs.completeSheetArgs,
+			},
+			Examples: []string{
+				"-system=cofd2e Jane Doe",
+				"show -section=skills Jane Doe",
+				"import Jane Doe",
+			},
+			Handle: bs.Sheet,
+		},
+		{
+			Name:        "roll",
+			Description: "Roll some dice",
+			Args:        "the dice to roll",
+			Flags:       rollFlags,
+			Complete: map[string]BotComplete{
+				"system": completeFrom(roll.Systems),
+				"resist": completeFrom(sheet.ResistTraits),
+			},
+			Variants: rollVariants(),
+			Examples: []string{
+				"2d6+3",
+				"-system=cofd -again=8 -rote 5",
+				"-secret 1d20",
+				"-system=cofd -resist=composure -against=Bob 6",
+				`-system=cofd -as "Bouncer" brawl+1`,
+				"-system=v5 -hunger=2 -difficulty=3 6",
+				`-system=pbta -move="Go Aggro" +2`,
+			},
+			Handle: bs.Roll,
+		},
+		{
+			Name:        "odds",
+			Description: "Work out the odds of a roll",
+			Args:        "the dice to roll",
+			Flags:       oddsFlags,
+			Complete: map[string]BotComplete{
+				"system": completeFrom(roll.Systems),
+			},
+			Variants: rollVariants(),
+			Examples: []string{
+				"-system=cofd 6",
+				"-system=cofd -again=9 -rote 6",
+				"2d20kh1+5",
+			},
+			Handle: bs.Odds,
+		},
+		{
+			Name:        "macro",
+			Description: "Save a roll to make again by name, for all of your characters or for one",
+			Args:        "save, a name and the roll, with parameters such as {mod}; list; or delete and a name",
+			Flags:       macroFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(macroSubcommands),
+			},
+			Examples: []string{
+				`save attack "-system=cofd -verbose strength+weaponry-1"`,
+				"save -character=Jane attack -system=cofd strength+brawl+{mod=0}",
+				"save hit 1d20+{bonus}",
+				"list",
+				"delete attack",
+			},
+			Handle: bs.Macro,
+		},
+		{
+			Name:        "m",
+			Description: "Roll a saved macro",
+			Args:        "the name of the macro, its parameters as values in order or as name=value, and any extra flags",
+			Examples: []string{
+				"attack",
+				"attack mod=-2",
+				"hit 5 -secret",
+			},
+			Handle: bs.RollMacro,
+		},
+		{
+			Name:        "init",
+			Description: "Track the initiative of a combat in this channel, shown in a pinned message",
+			Args:        "join, add and an NPC, next, delay, act or remove and a name, or end; leave it out to show the order",
+			Flags:       initiativeFlags,
+			Complete: map[string]BotComplete{
+				"args":   completeFrom(initiativeSubcommands),
+				"system": completeFrom(roll.Systems),
+			},
+			Examples: []string{
+				"join",
+				"join -modifier=3",
+				"add Goblin -modifier=2",
+				"next",
+				"delay",
+				"act Jane",
+				"remove Goblin",
+				"end",
+			},
+			Handle: bs.Initiative,
+		},
+		{
+			Name:        "condition",
+			Description: "Give a character a Condition or Tilt, resolve it for a beat, or list them",
+			Args:        "add, resolve or remove and the name of the condition; leave it out to list them",
+			Flags:       conditionFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(conditionSubcommands),
+			},
+			Examples: []string{
+				`add Guilty -resolution="Confess or make amends"`,
+				"add Stunned -tilt -modifiers=all:-2 -source=Bob",
+				"add Leg Wrack -tilt -modifiers=dexterity:-2,athletics:-2",
+				"resolve Guilty",
+				"remove Stunned",
+				"",
+			},
+			Handle: bs.Condition,
+		},
+		{
+			Name:        "spend",
+			Description: "Spend points of a resource, such as Willpower or Essence, from your character's sheet",
+			Args:        "the resource and the points to spend; one by default",
+			Flags:       resourceFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(sheet.Resources),
+			},
+			Examples: []string{"willpower", "essence 2", "willpower 1 -character=Jane"},
+			Handle:   bs.Spend,
+		},
+		{
+			Name:        "gain",
+			Description: "Regain points of a resource, such as Willpower or Essence, up to its maximum",
+			Args:        "the resource and the points to gain; one by default",
+			Flags:       resourceFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(sheet.Resources),
+			},
+			Examples: []string{"willpower", "essence 3"},
+			Handle:   bs.Gain,
+		},
+		{
+			Name:        "contest",
+			Description: "Roll a Chronicles of Darkness contested action, in which both sides roll and the most successes win",
+			Args:        "a pool for each side, either side of vs; traits are taken from the sheets of the characters",
+			Flags:       contestFlags,
+			Examples: []string{
+				"6 vs 4",
+				"strength+brawl vs dexterity+athletics -opponent=Bob",
+				"wits+subterfuge vs wits+empathy -character=Jane -opponent=Bob -again=9",
+			},
+			Handle: bs.Contest,
+		},
+		{
+			Name:        "extended",
+			Description: "Roll a Chronicles of Darkness extended action, accumulating successes over several rolls",
+			Args:        "start and the action's name, roll, choose a benefit, or abandon; leave it out to show your action",
+			Flags:       extendedFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(append(extendedSubcommands, "choose time", "choose target", "choose exceptional")),
+			},
+			Examples: []string{
+				`start "Research the ritual" -target=10 -pool=6 -interval=30m`,
+				"roll",
+				"roll -modifier=-2",
+				"choose time",
+				"",
+			},
+			Handle: bs.Extended,
+		},
+		{
+			Name:        "fair",
+			Description: "Start or end a fair session, in which every roll can be verified",
+			Args:        "start or end; leave it out to show the running session",
+			Complete: map[string]BotComplete{
+				"args": completeFrom([]string{"start", "end"}),
+			},
+			Examples: []string{"", "start", "end"},
+			Handle:   bs.Fair,
+		},
+		{
+			Name:        "rolls",
+			Description: "List the latest rolls made in this channel",
+			Args:        "optionally, last followed by how many rolls to list",
+			Examples:    []string{"", "last 10"},
+			Handle:      bs.Rolls,
+		},
+		{
+			Name:        "reveal",
+			Description: "Reveal a secret or gm roll to the channel it was rolled in",
+			Args:        "the id of the roll",
+			Examples:    []string{"1234567890"},
+			Handle:      bs.Reveal,
+		},
+		{
+			Name:        "config",
+			Description: "Show or change the settings for this server",
+			Args:        "a setting followed by its new value; leave out the value to reset it",
+			Complete: map[string]BotComplete{
+				"args": completeFrom(config.Keys),
+			},
+			Examples: []string{
+				"",
+				"prefix !",
+				"roll-system cofd",
+				"roll-channels #dice #combat",
+				"inline-rolls true",
+				"verbose",
+			},
+			Handle: bs.Config,
+		},
+		{
+			Name:        "campaign",
+			Description: "Create, join, link or describe a campaign",
+			Args:        "a subcommand (create, join, info or link) followed by the campaign name",
+			Flags:       campaignFlags,
+			Complete: map[string]BotComplete{
+				"system": completeFrom(sheet.Systems),
+				"args":   bs.completeCampaignArgs,
+			},
+			Examples: []string{
+				"",
+				"create -system=cofd2e Boston",
+				"join -character=Jane Boston",
+				"link Boston",
+			},
+			Handle: bs.Campaign,
+		},
+		{
+			Name:        "npc",
+			Description: "Keep quick stat blocks for a campaign's non-player characters",
+			Args:        "create, clone, show, reveal, hide or remove, then the npc's name; leave it out to list them",
+			Flags:       npcFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(npcSubcommands),
+			},
+			Examples: []string{
+				"",
+				`create Bouncer -pools=brawl:7,intimidation:5 -health=8 -defense=2 -notes="Works the door at Elysium"`,
+				"clone Thug 5",
+				"show Bouncer",
+				"reveal Bouncer",
+				"remove Thug 3",
+			},
+			Handle: bs.NPC,
+		},
+		{
+			Name:        "move",
+			Description: "Define the Powered by the Apocalypse moves of a campaign",
+			Args:        "a subcommand (define, show or remove) followed by the move's name; leave it out to list them",
+			Flags:       moveFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(moveSubcommands),
+			},
+			Examples: []string{
+				"",
+				`define "Go Aggro" -stat=hard -hit="They have to choose" -partial="They can choose" -miss="Be ready"`,
+				"show Go Aggro",
+				"remove Go Aggro",
+			},
+			Handle: bs.Move,
+		},
+		{
+			Name:        "modifier",
+			Description: "Track the forward and ongoing a character carries into their moves",
+			Args:        "forward or ongoing followed by the amount to add, or clear; leave it out to show them",
+			Flags:       modifierFlags,
+			Complete: map[string]BotComplete{
+				"args": completeFrom(modifierSubcommands),
+			},
+			Examples: []string{
+				"",
+				"forward +1",
+				"ongoing -1",
+				"-character=Rolfball clear",
+			},
+			Handle: bs.Modifier,
+		},
+		{
+			Name:        "help",
+			Description: "Describe the commands and how to use them",
+			Args:        "the command to describe",
+			Complete: map[string]BotComplete{
+				"args": bs.completeCommands,
+			},
+			Examples: []string{"", "roll"},
+			Handle:   bs.Help,
+		},
+	}
+}
+
+// sheetFlags declares the flags accepted by the sheet command and its subcommands
+func sheetFlags(fs *flag.FlagSet) {
+	fs.String("system", "wtf2e", "the character system to use")
+	fs.String("section", "", "the section of the sheet to show")
+}
+
+// rollFlags declares the flags accepted by the roll command for every roll system
+func rollFlags(fs *flag.FlagSet) {
+	oddsFlags(fs)
+	visibilityFlags(fs, new(bool), new(bool))
+	resistFlags(fs, new(string), new(string))
+	asFlags(fs, new(string))
+}
+
+// oddsFlags declares the flags of every roll system, which the odds command accepts too
+func oddsFlags(fs *flag.FlagSet) {
+	fs.String("system", "d20", "the dice system to use")
+	for _, system := range roll.Systems {
+		rs, _ := roll.NewRoller(system, nil)
+		sfs := flag.NewFlagSet(system, flag.ContinueOnError)
+		rs.Flags(sfs)
+		sfs.VisitAll(func(f *flag.Flag) {
+			if fs.Lookup(f.Name) == nil {
+				fs.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+	}
+}
+
+// rollVariants declares the flags of each roll system
+func rollVariants() map[string]func(*flag.FlagSet) {
+	variants := make(map[string]func(*flag.FlagSet))
+	for _, system := range roll.Systems {
+		rs, _ := roll.NewRoller(system, nil)
+		variants["-system="+system] = rs.Flags
+	}
+	return variants
+}
+
+// completeFrom suggests values from a fixed list
+func completeFrom(values []string) BotComplete {
+	return func(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error) {
+		return matching(values, partial), nil
+	}
+}
+
+// completeSheetArgs suggests subcommands and, for show, the names of the player's characters
+func (bs *BotServiceHandler) completeSheetArgs(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error) {
+	if !strings.HasPrefix(partial, "show ") {
+		return matching([]string{"show", "import"}, partial), nil
+	}
+	player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
+	guild, _ := strconv.ParseInt(msg.GuildID, 10, 64)
+	repo := bs.db.Repository("character").(domains.CharacterRepository)
+	chars, err := sheet.List(ctx, repo, guild, player)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(chars))
+	for _, char := range chars {
+		names = append(names, "show "+char.Name)
+	}
+	return matching(names, partial), nil
+}
+
+// subcommandFirst moves a subcommand given after the flags (as slash commands send it) to the front
+func subcommandFirst(fields []string, subcommands ...string) []string {
+	for i, field := range fields {
+		if strings.HasPrefix(field, "-") && strings.Contains(field, "=") {
+			continue
+		}
+		for _, sub := range subcommands {
+			if field == sub {
+				reordered := append([]string{sub}, fields[:i]...)
+				return append(reordered, fields[i+1:]...)
+			}
+		}
+		break
+	}
+	return fields
+}
+
+// quoteMarks are the marks which may quote a value typed in a message, including the curly quotes
+// of phone keyboards
+var quoteMarks = strings.NewReplacer(`"`, "", "“", "", "”", "")
+
+// joinQuoted joins fields which were split within quotes, such as -resolution="Admit the lie",
+// into one field without the quotes
+func joinQuoted(fields []string) []string {
+	joined := make([]string, 0, len(fields))
+	open := false
+	for _, field := range fields {
+		if open {
+			joined[len(joined)-1] += " " + field
+		} else {
+			joined = append(joined, field)
+		}
+		if (strings.Count(field, `"`)+strings.Count(field, "“")+strings.Count(field, "”"))%2 == 1 {
+			open = !open
+		}
+	}
+	for i, field := range joined {
+		joined[i] = quoteMarks.Replace(field)
+	}
+	return joined
+}
+
+func matching(values []string, partial string) []string {
+	matches := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), strings.ToLower(partial)) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
+
 // Sheet creates a new character sheet
-func (bs *BotServiceHandler) Sheet(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (string, error) {
-	// determine the sheet system
-	fs := &flag.FlagSet{}
-	fs.Usage = func() {}
+func (bs *BotServiceHandler) Sheet(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = subcommandFirst(fields, "import", "show")
+	if len(fields) > 0 && fields[0] == "import" {
+		return bs.importSheet(ctx, msg, fields[1:])
+	}
+	if len(fields) > 0 && fields[0] == "show" {
+		return bs.showSheet(ctx, msg, fields[1:])
+	}
+	// determine the sheet system, preferring that of the channel's campaign
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	campaigns := bs.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.ForChannel(ctx, campaigns, msg.GuildID, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	defaultSystem := gs.SheetSystem
+	if c != nil {
+		defaultSystem = c.System
+	}
+	fs := newFlagSet("sheet")
 	var system string
-	fs.StringVar(&system, "system", "wtf2e", "the character system to use")
-	fs.Parse(fields)
+	fs.StringVar(&system, "system", defaultSystem, "the character system to use")
+	fs.String("section", "", ignoredUsage)
+	err = fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "sheet", err)
+	}
 	name := strings.Join(fs.Args(), " ")
 	player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
 	ch, err := bs.bot.Channel(msg.ChannelID)
 	if err != nil {
 		// todo
-		return "", err
+		return nil, err
 	}
 	guild, _ := strconv.ParseInt(ch.GuildID, 10, 64)
 	repo := bs.db.Repository("character").(domains.CharacterRepository)
 	character, err := sheet.New(ctx, repo, name, system, guild, player)
 	if err != nil {
-		return "", errors.Wrap(err, "could not create a new sheet")
+		return nil, errors.Wrap(err, "could not create a new sheet")
+	}
+	if c != nil && c.Guild == ch.GuildID {
+		err = campaign.Attach(ctx, campaigns, repo, c, character)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return fmt.Sprintf("your new character is at %s/sheets/%s", SiteURL, character.ID), nil
+	return &BotResponse{Content: fmt.Sprintf("your new character is at %s/sheets/%s", SiteURL, character.ID)}, nil
 }
 
 // Roll handles incoming roll messages and sends them to the roll usecase.
-func (bs *BotServiceHandler) Roll(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (string, error) {
+func (bs *BotServiceHandler) Roll(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !config.RollAllowed(gs, msg.ChannelID) {
+		return nil, ErrRollChannel
+	}
+	// names may be quoted, as in -as "Big Tony"
+	fields = joinQuoted(fields)
 	// determine the system
-	fs := &flag.FlagSet{}
-	fs.Usage = func() {}
-	var system string
-	fs.StringVar(&system, "system", "d20", "the dice system to use")
-	fs.Parse(fields)
+	fs := newFlagSet("roll")
+	rollFlags(fs)
+	fs.Set("system", gs.RollSystem)
+	err = fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "roll", err)
+	}
+	system := fs.Lookup("system").Value.String()
 	// get a roller
-	cfs := &flag.FlagSet{}
 	rs, err := roll.NewRoller(system, nil)
-	rs.SetRand(bs.rand.Rand)
 	if err != nil {
 		// todo: log
-		return "", errors.Wrap(err, "could not get a roller")
+		return nil, errors.Wrap(err, "could not get a roller")
 	}
+	cfs := newFlagSet(system)
 	rs.Flags(cfs)
-	cfs.Parse(fields)
+	var secret, gm bool
+	visibilityFlags(cfs, &secret, &gm)
+	var trait, against, as string
+	resistFlags(cfs, &trait, &against)
+	asFlags(cfs, &as)
+	if gs.Verbose {
+		cfs.Set("verbose", "true")
+	}
+	err = cfs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "roll", err)
+	}
+	visibility := roll.Visibility(secret, gm)
+	if visibility != domains.RollPublic && msg.GuildID == "" {
+		return nil, ErrHiddenRollGuild
+	}
+	if visibility == domains.RollGM {
+		_, err = hiddenRollRecipients(ctx, bs.bot, bs.db, msg.GuildID, msg.ChannelID, msg.Author.ID, visibility)
+		if err != nil {
+			return nil, err
+		}
+	}
+	id := bs.db.ID()
+	rec, err := diceFor(ctx, bs.db, bs.rand, msg.ChannelID, id.String(), visibility)
+	if err != nil {
+		return nil, err
+	}
+	rs.SetRand(rec.Rand)
+	args := cfs.Args()
+	titles := make([]string, 0)
+	var char *domains.Character
+	var mods *domains.RollModifiers
+	if pbta, ok := rs.(*roll.PbtARollSystem); ok {
+		char, mods, err = bs.prepareMove(ctx, msg, pbta)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if as != "" {
+		if system != "cofd" {
+			return nil, ErrAsSystem
+		}
+		var dice int
+		var title string
+		dice, char, title, err = bs.rollAs(ctx, msg, as, strings.Join(args, "+"))
+		if err != nil {
+			return nil, err
+		}
+		args = []string{strconv.Itoa(dice)}
+		titles = append(titles, title)
+	} else if pool := strings.Join(args, "+"); system == "cofd" && sheet.NamesTraits(pool) {
+		// pools which name traits, such as those of macros, are taken from the author's character
+		char, err = bs.activeCharacter(ctx, msg, "")
+		if err != nil {
+			return nil, err
+		}
+		dice, title, err := characterPool(char, pool)
+		if err != nil {
+			return nil, err
+		}
+		args = []string{strconv.Itoa(dice)}
+		titles = append(titles, title)
+	}
+	if trait != "" {
+		if system != "cofd" {
+			return nil, ErrResistSystem
+		}
+		target, dots, err := bs.resistance(ctx, msg, trait, against)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fmt.Sprintf("-%d", dots))
+		titles = append(titles, fmt.Sprintf("%s resists with %s %d", target.Name, strings.Title(strings.ToLower(trait)), dots))
+	}
+	// make sure there is willpower to spend before the dice are drawn, but spend it only once the
+	// roll is kept, so a roll which cannot be made costs nothing.  npcs keep no willpower.
+	var willpower bool
+	if wp := cfs.Lookup("wp"); wp != nil && wp.Value.String() == "true" && (as == "" || char != nil) {
+		name := ""
+		if char != nil {
+			name = char.Name
+		}
+		char, err = bs.willpowerCharacter(ctx, msg, name)
+		if err != nil {
+			return nil, err
+		}
+		willpower = true
+	}
 	// roll
-	err = rs.Roll(ctx, cfs.Args())
+	err = rs.Roll(ctx, args)
 	if err != nil {
 		// todo: log
-		return "", errors.Wrap(err, "roll failed")
+		return nil, errors.Wrap(err, "roll failed")
+	}
+	r, err := roll.Record(rs, system, visibility)
+	if err != nil {
+		return nil, err
+	}
+	r.ID = id
+	r.Proof = rec.Proof()
+	r.DrawnFrom = rec.DrawnFrom()
+	r.Guild = msg.GuildID
+	r.Channel = msg.ChannelID
+	r.Player = msg.Author.ID
+	r.Source = domains.RollFromBot
+	r.Parameters = strings.Join(fields, " ")
+	if char != nil {
+		r.Character = char.ID
+	}
+	var response *BotResponse
+	if visibility == domains.RollPublic {
+		err = roll.Keep(ctx, bs.db.Repository("roll").(domains.RollRepository), r)
+		if err != nil {
+			return nil, err
+		}
+		// send the results
+		response = rollResponse(rs)
+		response.Embeds[0].Title = strings.Join(titles, "; ")
+		response.Embeds[0].Footer = rollFooter(r)
+	} else {
+		// keep hidden results for those allowed to see them
+		embed := rollEmbed(rs)
+		embed.Title = strings.Join(titles, "; ")
+		notice, err := keepHiddenRoll(ctx, bs.bot, bs.db, r, embed)
+		if err != nil {
+			return nil, err
+		}
+		response = &BotResponse{Content: notice}
+	}
+	// as with willpower, forward is only spent once the roll is kept.  The roll has been made by now,
+	// so whatever could not be spent is reported along with it rather than as an error.
+	if mods != nil {
+		err = move.SpendForward(ctx, bs.db.Repository("modifiers").(domains.RollModifiersRepository), mods)
+		if err != nil {
+			unspent(response, "forward", err)
+		}
+	}
+	if willpower {
+		err = bs.spendWillpower(ctx, msg, char)
+		if err != nil {
+			unspent(response, "Willpower", err)
+		}
+	}
+	return response, nil
+}
+
+// unspent notes on the response to a roll which was kept that something it cost could not be spent
+func unspent(response *BotResponse, what string, err error) {
+	response.Content = strings.TrimSpace(fmt.Sprintf("%s\nthe roll was kept, but %s could not be spent: %s", response.Content, what, err))
+}
+
+// outcomeColors are the embed colors used for each roll outcome
+var outcomeColors = map[roll.Outcome]int{
+	roll.OutcomeNone:            0x7289da,
+	roll.OutcomeFailure:         0xe74c3c,
+	roll.OutcomeSuccess:         0x2ecc71,
+	roll.OutcomeExceptional:     0xf1c40f,
+	roll.OutcomeDramaticFailure: 0x992d22,
+}
+
+// outcomeReactions are the reactions added to the roll message for notable outcomes
+var outcomeReactions = map[roll.Outcome]string{
+	roll.OutcomeExceptional:     "🎉",
+	roll.OutcomeDramaticFailure: "💀",
+}
+
+// rollEmbed converts a completed roll to a discord embed coloured by its outcome
+func rollEmbed(rs roll.System) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Description: rs.ToString(),
+		Color:       outcomeColors[rs.Outcome()],
+	}
+}
+
+// rollFooter identifies a kept roll, so that it can be found in the roll history, and where its
+// dice came from
+func rollFooter(r *domains.Roll) *discordgo.MessageEmbedFooter {
+	if r.DrawnFrom == "" {
+		return &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("roll %s", r.ID)}
+	}
+	return &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("roll %s · %s", r.ID, r.DrawnFrom)}
+}
+
+func rollResponse(rs roll.System) *BotResponse {
+	response := &BotResponse{Embeds: []*discordgo.MessageEmbed{rollEmbed(rs)}}
+	if reaction, ok := outcomeReactions[rs.Outcome()]; ok {
+		response.Reactions = append(response.Reactions, reaction)
+	}
+	return response
+}
+
+// importSheet creates a new character sheet from an attached (or pasted) export bundle
+func (bs *BotServiceHandler) importSheet(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fs := newFlagSet("import")
+	var system string
+	fs.StringVar(&system, "system", "", "the character system to use")
+	fs.String("section", "", ignoredUsage)
+	err := fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "sheet", err)
+	}
+	var name string
+	var data []byte
+	if len(msg.Attachments) > 0 {
+		name = strings.Join(fs.Args(), " ")
+		data, err = download(ctx, msg.Attachments[0].URL)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not download the attachment")
+		}
+	} else {
+		data = []byte(strings.Join(fs.Args(), " "))
+	}
+	player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
+	ch, err := bs.bot.Channel(msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	guild, _ := strconv.ParseInt(ch.GuildID, 10, 64)
+	repo := bs.db.Repository("character").(domains.CharacterRepository)
+	character, report, err := sheet.Import(ctx, repo, data, name, system, guild, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not import the sheet")
+	}
+	response := fmt.Sprintf("your imported character is at %s/sheets/%s", SiteURL, character.ID)
+	if len(report.Unmapped) > 0 {
+		response += fmt.Sprintf(" (could not import: %s)", strings.Join(report.Unmapped, ", "))
+	}
+	return &BotResponse{Content: response}, nil
+}
+
+// showSheet sends a summary of one of the player's characters to the channel
+func (bs *BotServiceHandler) showSheet(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fs := newFlagSet("show")
+	var section string
+	fs.StringVar(&section, "section", "", "the section of the sheet to show")
+	fs.String("system", "", ignoredUsage)
+	err := fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "sheet", err)
+	}
+	name := strings.Join(fs.Args(), " ")
+	player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
+	ch, err := bs.bot.Channel(msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	guild, _ := strconv.ParseInt(ch.GuildID, 10, 64)
+	repo := bs.db.Repository("character").(domains.CharacterRepository)
+	character, err := sheet.FindByName(ctx, repo, guild, player, name)
+	if err == sheet.ErrCharacterNotFound {
+		// storytellers can see every character in the guild
+		role, rerr := roleOf(ctx, bs.bot, bs.db, ch.GuildID, msg.Author.ID)
+		if rerr != nil {
+			return nil, rerr
+		}
+		if permission.Can(role, msg.Author.ID, permission.ViewGuildSheets, nil) {
+			character, err = sheet.FindInGuild(ctx, repo, guild, name)
+		} else {
+			// as can the storytellers of the channel's campaign, for its characters
+			campaigns := bs.db.Repository("campaign").(domains.CampaignRepository)
+			c, cerr := campaign.ForChannel(ctx, campaigns, ch.GuildID, msg.ChannelID)
+			if cerr != nil {
+				return nil, cerr
+			}
+			if campaign.IsStoryteller(c, msg.Author.ID) {
+				character, err = campaign.FindCharacter(ctx, repo, c, name)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	summary, err := sheet.Summarize(character, strings.ToLower(section))
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{summaryEmbed(character, summary)}}, nil
+}
+
+// summaryEmbed converts a sheet summary to a discord embed
+func summaryEmbed(character *domains.Character, summary *sheet.Summary) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  summary.Title,
+		URL:    fmt.Sprintf("%s/sheets/%s", SiteURL, character.ID),
+		Footer: &discordgo.MessageEmbedFooter{Text: summary.System},
+	}
+	summary.Fold(embedFieldsLimit)
+	for _, field := range summary.Fields {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   field.Name,
+			Value:  util.Truncate(field.Value, embedFieldLimit),
+			Inline: field.Inline,
+		})
+	}
+	return embed
+}
+
+// maxImportSize is the most of an export bundle which is read when importing a sheet
+const maxImportSize = 1 << 20
+
+// download fetches an attachment, reading no more than an import could use
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
 	}
-	// send the results
-	return rs.ToString(), nil
+	return ioutil.ReadAll(io.LimitReader(res.Body, maxImportSize))
 }
@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// conditionSubcommands are the subcommands of the condition command
+var conditionSubcommands = []string{"add", "resolve", "remove"}
+
+// conditionOptions are the flags of the condition command
+type conditionOptions struct {
+	character, source, resolution, modifiers string
+	tilt, persistent, beat                   bool
+}
+
+func (o *conditionOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.character, "character", "", "the character with the condition; your active character by default")
+	fs.StringVar(&o.source, "source", "", "what caused the condition (add)")
+	fs.StringVar(&o.resolution, "resolution", "", "how the condition is resolved, in quotes (add)")
+	fs.StringVar(&o.modifiers, "modifiers", "", "the dice the condition adds or takes away, such as all:-2,dexterity:-1 (add)")
+	fs.BoolVar(&o.tilt, "tilt", false, "whether it is a tilt, which lasts only for the scene (add)")
+	fs.BoolVar(&o.persistent, "persistent", false, "whether the condition is persistent (add)")
+	fs.BoolVar(&o.beat, "beat", true, "whether resolving the condition earns a beat; tilts do not by default (add)")
+}
+
+// conditionFlags declares the flags accepted by the condition command
+func conditionFlags(fs *flag.FlagSet) {
+	new(conditionOptions).flags(fs)
+}
+
+// Condition adds, resolves or lists the conditions and tilts of a character
+func (bs *BotServiceHandler) Condition(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = subcommandFirst(joinQuoted(fields), conditionSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(conditionSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("condition")
+	opts := new(conditionOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "condition", err)
+	}
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	name := strings.Join(args, " ")
+	char, err := bs.editableCharacter(ctx, msg, opts.character)
+	if err != nil {
+		return nil, err
+	}
+	chars := bs.db.Repository("character").(domains.CharacterRepository)
+	revisions := bs.db.Repository("revision").(domains.SheetRevisionRepository)
+	switch sub {
+	case "add":
+		c := sheet.Condition{
+			Name:       name,
+			Source:     opts.source,
+			Tilt:       opts.tilt,
+			Persistent: opts.persistent,
+			Resolution: opts.resolution,
+			Beat:       opts.beat && (given["beat"] || !opts.tilt),
+		}
+		c.Modifiers, err = sheet.ParseModifiers(opts.modifiers)
+		if err != nil {
+			return nil, bs.usageError(msg, "condition", err)
+		}
+		err = sheet.AddCondition(ctx, chars, revisions, char, c, msg.Author.ID)
+		if err != nil {
+			return nil, err
+		}
+		kind := "condition"
+		if c.Tilt {
+			kind = "tilt"
+		}
+		return &BotResponse{Content: fmt.Sprintf("**%s** gains the %s %s.", char.Name, c.Name, kind)}, nil
+	case "resolve", "remove":
+		c, beat, err := sheet.ResolveCondition(ctx, chars, revisions, char, name, sub == "resolve", msg.Author.ID)
+		if err != nil {
+			return nil, err
+		}
+		verb := "resolves"
+		if sub == "remove" {
+			verb = "is rid of"
+		}
+		note := fmt.Sprintf("**%s** %s %s.", char.Name, verb, c.Name)
+		if beat {
+			note += " They earn a beat."
+		}
+		return &BotResponse{Content: note}, nil
+	}
+	summary, err := sheet.Summarize(char, "conditions")
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{summaryEmbed(char, summary)}}, nil
+}
+
+// editableCharacter finds the author's active character, or the named character if the author may
+// edit it: one of their own, or as a storyteller, any in the guild
+func (bs *BotServiceHandler) editableCharacter(ctx context.Context, msg *discordgo.MessageCreate, name string) (*domains.Character, error) {
+	char, err := bs.activeCharacter(ctx, msg, name)
+	if err != sheet.ErrCharacterNotFound || name == "" {
+		return char, err
+	}
+	guild, _ := strconv.ParseInt(msg.GuildID, 10, 64)
+	char, err = sheet.FindInGuild(ctx, bs.db.Repository("character").(domains.CharacterRepository), guild, name)
+	if err != nil {
+		return nil, err
+	}
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	// the storytellers of the channel's campaign may change the sheets of its characters
+	c, err := campaign.ForChannel(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil && char.Campaign != nil && *char.Campaign == *c.ID {
+		role = permission.InCampaign(role, msg.Author.ID, c)
+	}
+	err = permission.Check(role, msg.Author.ID, permission.EditSheet, char)
+	if err != nil {
+		return nil, errors.Wrap(err, "only storytellers can change the sheets of other players' characters")
+	}
+	return char, nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrContestPools is thrown when a contest is not given a pool for each side
+var ErrContestPools = errors.New("give a pool for each side, such as strength+brawl vs dexterity+athletics")
+
+// ErrResistSystem is thrown when a roll other than a Chronicles of Darkness roll is resisted
+var ErrResistSystem = errors.New("only cofd rolls can be resisted")
+
+// ErrResistTrait is thrown when a roll is resisted with a trait which cannot resist
+var ErrResistTrait = errors.New("rolls can only be resisted with resolve, composure or stamina")
+
+// ErrResistTarget is thrown when a roll is resisted without naming who resists it
+var ErrResistTarget = errors.New("name the character resisting the roll with -against")
+
+// contestOptions are the flags of the contest command
+type contestOptions struct {
+	character, opponent string
+	again               int64
+	rote                bool
+}
+
+func (o *contestOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.character, "character", "", "your character, whose traits make up the first pool")
+	fs.StringVar(&o.opponent, "opponent", "", "the opposing character, whose traits make up the second pool")
+	fs.Int64Var(&o.again, "again", 10, "the n-again of both rolls")
+	fs.BoolVar(&o.rote, "rote", false, "whether both rolls are rote actions")
+}
+
+// contestFlags declares the flags accepted by the contest command
+func contestFlags(fs *flag.FlagSet) {
+	new(contestOptions).flags(fs)
+}
+
+// resistFlags declares the flags which resist a roll with a trait of the target
+func resistFlags(fs *flag.FlagSet, trait, against *string) {
+	fs.StringVar(trait, "resist", "", "the trait the target resists with: resolve, composure or stamina")
+	fs.StringVar(against, "against", "", "the character resisting the roll")
+}
+
+// contestSide is one side of a contested action
+type contestSide struct {
+	name       string
+	pool       string
+	conditions []string // the conditions which changed the pool
+	rs         *roll.CofDRollSystem
+	r          *domains.Roll
+}
+
+// Contest rolls a Chronicles of Darkness contested action, in which each side rolls its own pool
+// and the most successes win
+func (bs *BotServiceHandler) Contest(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !config.RollAllowed(gs, msg.ChannelID) {
+		return nil, ErrRollChannel
+	}
+	fs := newFlagSet("contest")
+	opts := new(contestOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "contest", err)
+	}
+	pools := splitContest(args)
+	if len(pools) != 2 {
+		return nil, bs.usageError(msg, "contest", ErrContestPools)
+	}
+	sides := []*contestSide{
+		{name: "You", pool: pools[0]},
+		{name: "Opponent", pool: pools[1]},
+	}
+	dice := make([]int, len(sides))
+	for i, side := range sides {
+		dice[i], err = bs.contestPool(ctx, msg, side, i == 0, []string{opts.character, opts.opponent}[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, side := range sides {
+		side.rs = new(roll.CofDRollSystem)
+		side.rs.Verbose = gs.Verbose
+		side.rs.Again = opts.again
+		side.rs.Rote = opts.rote
+		side.rs.Dice = util.Max(dice[i], 0)
+		id := bs.db.ID()
+		rec, err := diceFor(ctx, bs.db, bs.rand, msg.ChannelID, id.String(), domains.RollPublic)
+		if err != nil {
+			return nil, err
+		}
+		side.rs.SetRand(rec.Rand)
+		err = side.rs.Roll(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "roll failed")
+		}
+		side.r, err = roll.Record(side.rs, "cofd", domains.RollPublic)
+		if err != nil {
+			return nil, err
+		}
+		side.r.ID = id
+		side.r.Proof = rec.Proof()
+		side.r.DrawnFrom = rec.DrawnFrom()
+		side.r.Guild = msg.GuildID
+		side.r.Channel = msg.ChannelID
+		side.r.Player = msg.Author.ID
+		side.r.Source = domains.RollFromBot
+		side.r.Parameters = "contest " + strings.Join(fields, " ")
+		err = roll.Keep(ctx, bs.db.Repository("roll").(domains.RollRepository), side.r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	contest := roll.Compare(sides[0].rs, sides[1].rs)
+	response := new(BotResponse)
+	switch contest.Winner {
+	case -1:
+		response.Content = "It's a tie; neither side wins."
+	default:
+		response.Content = fmt.Sprintf("**%s** wins by %d.", sides[contest.Winner].name, contest.Margin)
+	}
+	for _, side := range sides {
+		embed := rollEmbed(side.rs)
+		dice := fmt.Sprintf("%d dice", side.rs.Dice)
+		if len(side.conditions) > 0 {
+			dice += " with " + strings.Join(side.conditions, ", ")
+		}
+		embed.Title = util.Truncate(fmt.Sprintf("%s: %s (%s)", side.name, side.pool, dice), embedTitleLimit)
+		embed.Footer = rollFooter(side.r)
+		response.Embeds = append(response.Embeds, embed)
+	}
+	return response, nil
+}
+
+// contestPool adds up the dice pool of one side of a contest, taking any traits from the named
+// character: one of the player's own for the first side, or any character in the guild for the
+// second
+func (bs *BotServiceHandler) contestPool(ctx context.Context, msg *discordgo.MessageCreate, side *contestSide, own bool, name string) (int, error) {
+	dice, err := sheet.Pool(nil, side.pool)
+	if err == nil && name == "" {
+		return dice, nil
+	}
+	if err != nil && errors.Cause(err) != sheet.ErrNoSheet {
+		return 0, err
+	}
+	if name == "" && !own {
+		return 0, err
+	}
+	repo := bs.db.Repository("character").(domains.CharacterRepository)
+	guild, _ := strconv.ParseInt(msg.GuildID, 10, 64)
+	var char *domains.Character
+	if own {
+		player, _ := strconv.ParseInt(msg.Author.ID, 10, 64)
+		char, err = sheet.FindByName(ctx, repo, guild, player, name)
+	} else {
+		char, err = sheet.FindInGuild(ctx, repo, guild, name)
+	}
+	if err != nil {
+		return 0, err
+	}
+	side.name = char.Name
+	dice, side.conditions, err = sheet.SheetPool(char.Sheet, side.pool)
+	return dice, err
+}
+
+// splitContest splits the arguments of a contest into the pool of each side, either side of "vs"
+func splitContest(args []string) []string {
+	pools := []string{""}
+	for _, arg := range args {
+		if strings.EqualFold(arg, "vs") || strings.EqualFold(arg, "vs.") {
+			pools = append(pools, "")
+			continue
+		}
+		pools[len(pools)-1] += arg
+	}
+	for _, pool := range pools {
+		if pool == "" {
+			return nil
+		}
+	}
+	return pools
+}
+
+// resistance finds the dots of the trait a character resists a roll with
+func (bs *BotServiceHandler) resistance(ctx context.Context, msg *discordgo.MessageCreate, trait, against string) (*domains.Character, int, error) {
+	if !util.ContainsString(sheet.ResistTraits, strings.ToLower(trait)) {
+		return nil, 0, ErrResistTrait
+	}
+	if against == "" {
+		return nil, 0, ErrResistTarget
+	}
+	guild, _ := strconv.ParseInt(msg.GuildID, 10, 64)
+	char, err := sheet.FindInGuild(ctx, bs.db.Repository("character").(domains.CharacterRepository), guild, against)
+	if err != nil {
+		return nil, 0, err
+	}
+	dots, err := sheet.Trait(char.Sheet, trait)
+	if err != nil {
+		return nil, 0, err
+	}
+	return char, dots, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/go-chi/chi"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/interfaces/repositories"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/fair"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/pkg/errors"
+)
+
+// ErrFairGuild is thrown when a fair session is started outside of a server
+var ErrFairGuild = errors.New("fair sessions can only be run within a server")
+
+// diceFor chooses where the dice of a roll in a channel come from.  While a fair session runs in
+// the channel they are derived from its seed; otherwise they come from the random service.  Hidden
+// rolls always come from the random service, since anyone could work out their dice once the seed
+// is revealed.
+func diceFor(ctx context.Context, db repositories.Database, random Random, channel, nonce string, visibility domains.RollVisibility) (*fair.Recorder, error) {
+	if visibility != domains.RollPublic {
+		return fair.NewRecorder(random.Draw), nil
+	}
+	s, err := db.Repository("fair").(domains.FairSessionRepository).FindActive(ctx, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find fair session")
+	}
+	if s != nil {
+		return fair.NewSessionRecorder(s, nonce), nil
+	}
+	return fair.NewRecorder(random.Draw), nil
+}
+
+// Fair starts, ends or describes the fair session in the channel
+func (bs *BotServiceHandler) Fair(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrFairGuild
+	}
+	repo := bs.db.Repository("fair").(domains.FairSessionRepository)
+	if len(fields) == 0 {
+		s, err := repo.FindActive(ctx, msg.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, fair.ErrNoSession
+		}
+		return &BotResponse{Content: fmt.Sprintf("a fair session has been running here since <t:%d:f>; the hash of its seed is `%s`", s.StartedAt.Unix(), s.Hash)}, nil
+	}
+	sub := strings.ToLower(fields[0])
+	if len(fields) > 1 || (sub != "start" && sub != "end") {
+		return nil, bs.usageError(msg, "fair", errors.Errorf("unknown subcommand %s", fields[0]))
+	}
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := campaign.ForChannel(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	err = permission.Check(permission.InCampaign(role, msg.Author.ID, c), msg.Author.ID, permission.ManageDice, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sub == "start" {
+		s, err := fair.Start(ctx, repo, msg.GuildID, msg.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("a fair session has started; the hash of its seed is `%s`. "+
+			"Every roll here can be verified once the session ends and the seed is revealed.", s.Hash)}, nil
+	}
+	s, err := fair.End(ctx, repo, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("the fair session has ended; its seed was `%s`, which hashes to `%s`", s.Seed, s.Hash)}, nil
+}
+
+// VerifyRoll checks a kept roll against the proof of how its dice were drawn
+func (ws *WebServiceHandler) VerifyRoll(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	r, err := roll.Find(req.Context(), ws.db.Repository("roll").(domains.RollRepository), chi.URLParam(req, "ID"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	role, err := roleOf(req.Context(), ws.bot, ws.db, r.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c, err := campaign.ForChannel(req.Context(), ws.db.Repository("campaign").(domains.CampaignRepository), r.Guild, r.Channel)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	role = permission.InCampaign(role, uid, c)
+	if (r.Player != uid && role < domains.RolePlayer) || (roll.Hidden(r) && !permission.CanReveal(role, uid, r)) {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	sessions := ws.db.Repository("fair").(domains.FairSessionRepository)
+	batches := ws.db.Repository("batch").(domains.SignedBatchRepository)
+	v, err := fair.Verify(req.Context(), r, sessions, batches, ws.rand)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(res).Encode(v)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
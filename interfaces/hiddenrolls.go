@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/interfaces/repositories"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrHiddenRollGuild is thrown when a secret or gm roll is made outside of a server
+var ErrHiddenRollGuild = errors.New("secret and gm rolls can only be made within a server")
+
+// visibilityFlags declares the flags which hide a roll
+func visibilityFlags(fs *flag.FlagSet, secret, gm *bool) {
+	fs.BoolVar(secret, "secret", false, "only show the result to you and the storytellers")
+	fs.BoolVar(gm, "gm", false, "only show the result to the storytellers")
+}
+
+// ErrNoStorytellers is thrown when a gm roll is made where no storyteller would see it
+var ErrNoStorytellers = errors.New("gm rolls can only be made where there are storytellers, either of the channel's campaign or of the server")
+
+// hiddenRollRecipients finds those allowed to see a secret or gm roll: the storytellers of the
+// channel's campaign, the holders of the guild's storyteller roles and, for a secret roll, the
+// roller.  A gm roll which nobody would see is refused, so it should be checked before the dice
+// are drawn.
+func hiddenRollRecipients(ctx context.Context, bot Bot, db repositories.Database, guild, channel, player string, visibility domains.RollVisibility) ([]string, error) {
+	c, err := campaign.ForChannel(ctx, db.Repository("campaign").(domains.CampaignRepository), guild, channel)
+	if err != nil {
+		return nil, err
+	}
+	gs, err := config.Get(ctx, db.Repository("guild").(domains.GuildSettingsRepository), guild)
+	if err != nil {
+		return nil, err
+	}
+	storytellers, err := bot.RoleMembers(guild, gs.Storytellers)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		storytellers = append(storytellers, c.Storytellers...)
+	}
+	recipients := make([]string, 0)
+	if visibility == domains.RollSecret {
+		recipients = append(recipients, player)
+	}
+	for _, storyteller := range storytellers {
+		if !util.ContainsString(recipients, storyteller) {
+			recipients = append(recipients, storyteller)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, ErrNoStorytellers
+	}
+	return recipients, nil
+}
+
+// keepHiddenRoll stores a secret or gm roll and sends its result to those allowed to see it.  The
+// notice to post in the channel in place of the result is returned, naming any recipients whom
+// the result could not be sent to.
+func keepHiddenRoll(ctx context.Context, bot Bot, db repositories.Database, r *domains.Roll, embed *discordgo.MessageEmbed) (string, error) {
+	recipients, err := hiddenRollRecipients(ctx, bot, db, r.Guild, r.Channel, r.Player, r.Visibility)
+	if err != nil {
+		return "", err
+	}
+	err = roll.Keep(ctx, db.Repository("roll").(domains.RollRepository), r)
+	if err != nil {
+		return "", err
+	}
+	embed.Title = fmt.Sprintf("Roll %s in #%s", r.ID, channelName(bot, r.Channel))
+	embed.Description = fmt.Sprintf("<@%s> %s", r.Player, embed.Description)
+	failed := make([]string, 0)
+	for _, recipient := range recipients {
+		if bot.SendDirectEmbed(recipient, embed) != nil {
+			failed = append(failed, fmt.Sprintf("<@%s>", recipient))
+		}
+	}
+	notice := fmt.Sprintf("rolled secretly (roll %s)", r.ID)
+	if r.Visibility == domains.RollGM {
+		notice = fmt.Sprintf("rolled for the storytellers (roll %s)", r.ID)
+	}
+	if len(failed) > 0 {
+		notice = fmt.Sprintf("%s, but the result could not be sent to %s", notice, strings.Join(failed, ", "))
+	}
+	return notice, nil
+}
+
+// channelName finds the name of a channel, falling back to its id
+func channelName(bot Bot, id string) string {
+	ch, err := bot.Channel(id)
+	if err != nil {
+		return id
+	}
+	return ch.Name
+}
+
+// Reveal posts a secret or gm roll to the channel it was rolled in
+func (bs *BotServiceHandler) Reveal(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if len(fields) != 1 {
+		return nil, bs.usageError(msg, "reveal", flag.ErrHelp)
+	}
+	repo := bs.db.Repository("roll").(domains.RollRepository)
+	r, err := roll.Find(ctx, repo, strings.TrimPrefix(fields[0], "#"))
+	if err != nil {
+		return nil, err
+	}
+	if r.Guild != msg.GuildID {
+		return nil, roll.ErrRollNotFound
+	}
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := campaign.ForChannel(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), r.Guild, r.Channel)
+	if err != nil {
+		return nil, err
+	}
+	role = permission.InCampaign(role, msg.Author.ID, c)
+	if !permission.CanReveal(role, msg.Author.ID, r) {
+		return nil, permission.ErrForbidden
+	}
+	err = roll.Reveal(ctx, repo, r)
+	if err != nil {
+		return nil, err
+	}
+	embed := revealEmbed(r)
+	if msg.ChannelID != r.Channel {
+		err = bs.bot.SendEmbed(r.Channel, embed)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("revealed roll %s in <#%s>", r.ID, r.Channel)}, nil
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{embed}}, nil
+}
+
+// revealEmbed converts a kept roll to a discord embed coloured by its outcome
+func revealEmbed(r *domains.Roll) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Roll %s, revealed", r.ID),
+		Description: fmt.Sprintf("<@%s> %s", r.Player, r.Description),
+		Color:       outcomeColors[roll.Outcome(r.Outcome)],
+	}
+}
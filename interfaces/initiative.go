@@ -0,0 +1,299 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/initiative"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrInitiativeGuild is thrown when initiative is used outside of a server
+var ErrInitiativeGuild = errors.New("initiative can only be tracked within a server")
+
+// ErrInitiativeName is thrown when a non-player character is added, or a turn taken, without a name
+var ErrInitiativeName = errors.New("name who the initiative is for")
+
+// initiativeSubcommands are the subcommands of the initiative command
+var initiativeSubcommands = []string{"join", "add", "next", "delay", "act", "remove", "end"}
+
+// initiativeOptions are the flags of the initiative command
+type initiativeOptions struct {
+	character, system string
+	modifier, score   int
+}
+
+func (o *initiativeOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.character, "character", "", "the character to join with; your active character by default (join)")
+	fs.StringVar(&o.system, "system", "", "the dice system to roll initiative with when starting a combat: cofd or d20")
+	fs.IntVar(&o.modifier, "modifier", 0, "the initiative modifier; from the sheet by default in cofd (join, add)")
+	fs.IntVar(&o.score, "score", 0, "an initiative score to use instead of rolling (join, add)")
+}
+
+// initiativeFlags declares the flags accepted by the initiative command
+func initiativeFlags(fs *flag.FlagSet) {
+	new(initiativeOptions).flags(fs)
+}
+
+// Initiative tracks the turn order of the combat in a channel, which is shown in a pinned message
+// updated as the order changes
+func (bs *BotServiceHandler) Initiative(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrInitiativeGuild
+	}
+	fields = subcommandFirst(fields, initiativeSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(initiativeSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("init")
+	opts := new(initiativeOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "init", err)
+	}
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	name := strings.Trim(strings.Join(args, " "), `"“”`)
+	repo := bs.db.Repository("initiative").(domains.InitiativeRepository)
+	if sub == "join" || sub == "add" {
+		i, note, err := bs.joinInitiative(ctx, msg, repo, sub == "add", name, opts, given)
+		if err != nil {
+			return nil, err
+		}
+		return bs.pinInitiative(ctx, repo, i, note)
+	}
+	i, err := initiative.Find(ctx, repo, msg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if (sub == "act" || sub == "remove") && name == "" {
+		return nil, bs.usageError(msg, "init", ErrInitiativeName)
+	}
+	if sub != "" {
+		err = bs.mayChangeInitiative(ctx, msg, i, sub, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var note string
+	switch sub {
+	case "":
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{initiativeEmbed(i)}}, nil
+	case "next":
+		_, err = initiative.Next(ctx, repo, i)
+		note = turnNote(i)
+	case "delay":
+		var e *domains.InitiativeEntry
+		e, err = initiative.Delay(ctx, repo, i, name)
+		if err == nil {
+			note = fmt.Sprintf("**%s** delays their turn. %s", e.Name, turnNote(i))
+		}
+	case "act":
+		_, err = initiative.Act(ctx, repo, i, name)
+		note = turnNote(i)
+	case "remove":
+		var e *domains.InitiativeEntry
+		e, err = initiative.Remove(ctx, repo, i, name)
+		if err == nil {
+			note = fmt.Sprintf("**%s** leaves the initiative.", e.Name)
+		}
+	case "end":
+		err = initiative.End(ctx, repo, i)
+		if err != nil {
+			return nil, err
+		}
+		if i.Message != "" {
+			// the message may already have been unpinned or deleted
+			bs.bot.Unpin(i.Channel, i.Message)
+		}
+		return &BotResponse{Content: fmt.Sprintf("The combat ends after %d rounds.", i.Round)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bs.pinInitiative(ctx, repo, i, note)
+}
+
+// mayChangeInitiative determines whether the author may take a turn of the initiative.  Players
+// may end, delay and act on their own turns, while ending the turns of others, removing others
+// and ending the combat is left to the storytellers.  Anyone may start the first turn.
+func (bs *BotServiceHandler) mayChangeInitiative(ctx context.Context, msg *discordgo.MessageCreate, i *domains.Initiative, sub, name string) error {
+	if sub != "end" {
+		if sub == "next" {
+			name = ""
+		}
+		e, err := initiative.Entry(i, name)
+		if err != nil {
+			// the entry is missing, which the subcommand reports
+			return nil
+		}
+		if e == nil || e.Player == msg.Author.ID {
+			return nil
+		}
+	}
+	c, err := campaign.ForChannel(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID)
+	if err != nil {
+		return err
+	}
+	storyteller, err := bs.runsCampaign(ctx, msg, c)
+	if err != nil {
+		return err
+	}
+	if !storyteller {
+		return errors.Wrap(permission.ErrForbidden, "only storytellers can change the turns of others or end the combat")
+	}
+	return nil
+}
+
+// joinInitiative rolls initiative for the author's active character, or for a named non-player
+// character, and adds it to the order
+func (bs *BotServiceHandler) joinInitiative(ctx context.Context, msg *discordgo.MessageCreate, repo domains.InitiativeRepository, npc bool, name string, opts *initiativeOptions, given map[string]bool) (*domains.Initiative, string, error) {
+	system := opts.system
+	if system == "" {
+		gs, err := bs.guildSettings(ctx, msg)
+		if err != nil {
+			return nil, "", err
+		}
+		system = gs.RollSystem
+	}
+	i, err := initiative.Find(ctx, repo, msg.ChannelID)
+	switch {
+	case err == nil:
+		system = i.System
+	case err != initiative.ErrNoInitiative:
+		return nil, "", err
+	}
+	e := &domains.InitiativeEntry{Name: name, Player: msg.Author.ID, Modifier: opts.modifier, NPC: npc}
+	if npc && name == "" {
+		return nil, "", bs.usageError(msg, "init", ErrInitiativeName)
+	}
+	if !npc {
+		char, err := bs.activeCharacter(ctx, msg, opts.character)
+		switch {
+		case err == sheet.ErrCharacterNotFound && opts.character == "":
+			// players without a sheet join as themselves
+			e.Name = msg.Author.Username
+		case err != nil:
+			return nil, "", err
+		default:
+			e.Name = char.Name
+			e.Character = char.ID
+			if !given["modifier"] && system == "cofd" {
+				e.Modifier, err = sheet.Initiative(char.Sheet)
+				if err != nil {
+					return nil, "", err
+				}
+			}
+		}
+	}
+	e.Score = opts.score
+	note := fmt.Sprintf("**%s** joins the initiative with %d.", e.Name, e.Score)
+	if !given["score"] {
+		rec, err := diceFor(ctx, bs.db, bs.rand, msg.ChannelID, bs.db.ID().String(), domains.RollPublic)
+		if err != nil {
+			return nil, "", err
+		}
+		e.Score, err = initiative.Roll(system, e.Modifier, rec.Rand)
+		if err != nil {
+			return nil, "", err
+		}
+		note = fmt.Sprintf("**%s** joins the initiative with %d (rolled %d, %+d).", e.Name, e.Score, e.Score-e.Modifier, e.Modifier)
+	}
+	i, err = initiative.Join(ctx, repo, msg.GuildID, msg.ChannelID, system, e)
+	if err != nil {
+		return nil, "", err
+	}
+	return i, note, nil
+}
+
+// pinInitiative updates the pinned message which shows the initiative, or sends and pins a new one
+// if there is none or it was deleted
+func (bs *BotServiceHandler) pinInitiative(ctx context.Context, repo domains.InitiativeRepository, i *domains.Initiative, note string) (*BotResponse, error) {
+	embed := initiativeEmbed(i)
+	if i.Message != "" && bs.bot.EditEmbed(i.Channel, i.Message, embed) == nil {
+		return &BotResponse{Content: note}, nil
+	}
+	id, err := bs.bot.SendPinnedEmbed(i.Channel, embed)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not send the initiative")
+	}
+	err = initiative.Pin(ctx, repo, i, id)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: note}, nil
+}
+
+// turnNote announces whose turn it is, mentioning their player
+func turnNote(i *domains.Initiative) string {
+	e := initiative.Current(i)
+	if e == nil {
+		return ""
+	}
+	if e.NPC {
+		return fmt.Sprintf("Round %d: it's **%s**'s turn.", i.Round, e.Name)
+	}
+	return fmt.Sprintf("Round %d: it's **%s**'s turn, <@%s>.", i.Round, e.Name, e.Player)
+}
+
+// initiativeEmbed shows the turn order of a combat as a discord embed
+func initiativeEmbed(i *domains.Initiative) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{Title: "Initiative"}
+	if i.Round > 0 {
+		embed.Title = fmt.Sprintf("Initiative · Round %d", i.Round)
+	}
+	current := initiative.Current(i)
+	lines := make([]string, 0, len(i.Entries))
+	for _, e := range i.Entries {
+		line := fmt.Sprintf("`%3d` %s", e.Score, e.Name)
+		if e.NPC {
+			line += " (NPC)"
+		}
+		if e.Delayed {
+			line += " · delaying"
+		}
+		if e == current {
+			line = "▶ **" + line + "**"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No one has joined yet.")
+	}
+	embed.Description = util.Truncate(strings.Join(lines, "\n"), embedDescriptionLimit)
+	if current == nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "the combat starts with the first next"}
+	}
+	return embed
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// MaxInlineRolls is the most inline rolls which are rolled from one message; any more are left as
+// they were written
+const MaxInlineRolls = 10
+
+// messageLimit is the maximum length of the content of a discord message
+const messageLimit = 2000
+
+// inlineRollPattern matches the rolls written inline in a message, such as [[1d20+5]]
+var inlineRollPattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// HasInlineRolls determines whether a message has rolls written inline
+func HasInlineRolls(content string) bool {
+	return inlineRollPattern.MatchString(content)
+}
+
+// InlineRolls rolls each inline roll of an ordinary message with the guild's roll system, and
+// replies with the message rewritten with the results.  Guilds must turn inline rolls on.  Since the
+// message was not a command, whatever cannot be rolled, such as a [[wiki link]], is left as written.
+func (bs *BotServiceHandler) InlineRolls(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !gs.InlineRolls || !config.RollAllowed(gs, msg.ChannelID) {
+		return new(BotResponse), nil
+	}
+	var rewritten strings.Builder
+	lines := make([]string, 0)
+	ids := make([]string, 0)
+	last := 0
+	for _, match := range inlineRollPattern.FindAllStringSubmatchIndex(msg.Content, MaxInlineRolls) {
+		expression := strings.TrimSpace(msg.Content[match[2]:match[3]])
+		rs, r, err := bs.rollInline(ctx, msg, gs.RollSystem, expression)
+		if err != nil {
+			continue
+		}
+		rewritten.WriteString(msg.Content[last:match[0]])
+		fmt.Fprintf(&rewritten, "**%s**", inlineTotal(rs))
+		last = match[1]
+		lines = append(lines, fmt.Sprintf("`%s` %s", expression, rs.ToString()))
+		ids = append(ids, r.ID.String())
+	}
+	if len(ids) == 0 {
+		return new(BotResponse), nil
+	}
+	rewritten.WriteString(msg.Content[last:])
+	embed := &discordgo.MessageEmbed{
+		Description: util.Truncate(strings.Join(lines, "\n"), embedDescriptionLimit),
+		Color:       outcomeColors[roll.OutcomeNone],
+		Footer:      &discordgo.MessageEmbedFooter{Text: "rolls " + strings.Join(ids, ", ")},
+	}
+	content := util.Truncate(rewritten.String(), messageLimit-len(msg.Author.Mention())-1)
+	return &BotResponse{Content: content, Embeds: []*discordgo.MessageEmbed{embed}, Quiet: true}, nil
+}
+
+// rollInline rolls and keeps one inline roll.  Ordinary chat is rolled just as it was written, so a
+// roll system which panics on it is recovered from rather than taking down the bot.
+func (bs *BotServiceHandler) rollInline(ctx context.Context, msg *discordgo.MessageCreate, system, expression string) (rs roll.System, r *domains.Roll, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			rs, r, err = nil, nil, errors.Errorf("could not roll [[%s]]: %v", expression, p)
+		}
+	}()
+	rs, err = roll.NewRoller(system, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not get a roller")
+	}
+	id := bs.db.ID()
+	rec, err := diceFor(ctx, bs.db, bs.rand, msg.ChannelID, id.String(), domains.RollPublic)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs.SetRand(rec.Rand)
+	fs := newFlagSet(system)
+	rs.Flags(fs)
+	err = fs.Parse(strings.Fields(expression))
+	if err != nil {
+		return nil, nil, err
+	}
+	err = rs.Roll(ctx, fs.Args())
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err = roll.Record(rs, system, domains.RollPublic)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.ID = id
+	r.Proof = rec.Proof()
+	r.DrawnFrom = rec.DrawnFrom()
+	r.Guild = msg.GuildID
+	r.Channel = msg.ChannelID
+	r.Player = msg.Author.ID
+	r.Source = domains.RollFromBot
+	r.Parameters = fmt.Sprintf("[[%s]]", expression)
+	err = roll.Keep(ctx, bs.db.Repository("roll").(domains.RollRepository), r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rs, r, nil
+}
+
+// inlineTotal describes the result of an inline roll in a word or two
+func inlineTotal(rs roll.System) string {
+	if _, ok := rs.(*roll.CofDRollSystem); ok {
+		if rs.Total() == 1 {
+			return "1 success"
+		}
+		return fmt.Sprintf("%d successes", rs.Total())
+	}
+	return fmt.Sprint(rs.Total())
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/macro"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrMacroArgs is thrown when the macro command is given no subcommand
+var ErrMacroArgs = errors.New("save, list or delete a macro, or roll one with m")
+
+// macroSubcommands are the subcommands of the macro command
+var macroSubcommands = []string{"save", "list", "delete"}
+
+// macroFlags declares the flags accepted by the macro command
+func macroFlags(fs *flag.FlagSet) {
+	fs.String("character", "", "the character the macro is for; every character by default")
+}
+
+// Macro saves, lists or deletes the roll macros of a player
+func (bs *BotServiceHandler) Macro(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = subcommandFirst(joinQuoted(fields), macroSubcommands...)
+	if len(fields) == 0 || !util.ContainsString(macroSubcommands, fields[0]) {
+		return nil, bs.usageError(msg, "macro", ErrMacroArgs)
+	}
+	sub := fields[0]
+	fs := newFlagSet("macro")
+	var character string
+	fs.StringVar(&character, "character", "", "the character the macro is for")
+	// the roll of a macro may have flags of its own, so only flags before the name are the macro's
+	err := fs.Parse(fields[1:])
+	if err != nil {
+		return nil, bs.usageError(msg, "macro", err)
+	}
+	args := fs.Args()
+	var char *domains.Character
+	if character != "" {
+		char, err = bs.activeCharacter(ctx, msg, character)
+		if err != nil {
+			return nil, err
+		}
+	}
+	db := bs.db.Repository("macro").(domains.MacroRepository)
+	switch sub {
+	case "save":
+		if len(args) == 0 {
+			return nil, bs.usageError(msg, "macro", macro.ErrMacroName)
+		}
+		m, err := macro.Save(ctx, db, msg.Author.ID, characterID(char), args[0], strings.Join(args[1:], " "))
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("saved %s; roll it with `%sm %s`", m.Name, commandPrefix(msg, "macro"), m.Name)}, nil
+	case "delete":
+		m, err := macro.Delete(ctx, db, msg.Author.ID, characterID(char), strings.Join(args, " "))
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("deleted %s", m.Name)}, nil
+	}
+	if char == nil {
+		char, err = bs.optionalCharacter(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	macros, err := macro.List(ctx, db, msg.Author.ID, characterID(char))
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{macroEmbed(char, macros)}}, nil
+}
+
+// RollMacro rolls one of the author's macros, filling in its parameters from the arguments
+func (bs *BotServiceHandler) RollMacro(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = joinQuoted(fields)
+	if len(fields) == 0 {
+		return nil, bs.usageError(msg, "m", macro.ErrMacroName)
+	}
+	char, err := bs.optionalCharacter(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	m, err := macro.Find(ctx, bs.db.Repository("macro").(domains.MacroRepository), msg.Author.ID, characterID(char), fields[0])
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := macro.Expand(m, fields[1:])
+	if err != nil {
+		return nil, err
+	}
+	return bs.Roll(ctx, msg, expanded)
+}
+
+// characterID is the id of a character, or nil without one
+func characterID(char *domains.Character) *snowflake.ID {
+	if char == nil {
+		return nil
+	}
+	return char.ID
+}
+
+// macroEmbed renders the macros a player may roll as a discord embed
+func macroEmbed(char *domains.Character, macros []*domains.Macro) *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(macros))
+	for _, m := range macros {
+		lines = append(lines, fmt.Sprintf("**%s** `%s`", m.Name, m.Roll))
+	}
+	title := "Your macros"
+	if char != nil {
+		title = fmt.Sprintf("Your macros for %s", char.Name)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: util.Truncate(orNobody(strings.Join(lines, "\n")), embedDescriptionLimit),
+	}
+}
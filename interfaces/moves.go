@@ -0,0 +1,259 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/move"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrMoveGuild is thrown when a move is defined or made outside of a server
+var ErrMoveGuild = errors.New("moves can only be kept within a server")
+
+// ErrModifierAmount is thrown when forward or ongoing is changed without saying by how much
+var ErrModifierAmount = errors.New("say how much to add, such as forward +1 or ongoing -1")
+
+// moveSubcommands are the subcommands of the move command; without one, the campaign's moves are listed
+var moveSubcommands = []string{"define", "show", "remove"}
+
+// modifierSubcommands are the subcommands of the modifier command; without one, the modifiers are shown
+var modifierSubcommands = []string{"forward", "ongoing", "clear"}
+
+// moveOptions are the flags of the move command
+type moveOptions struct {
+	campaign, stat, hit, partial, miss string
+}
+
+func (o *moveOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.campaign, "campaign", "", "the campaign of the move; the channel's campaign by default")
+	fs.StringVar(&o.stat, "stat", "", "the stat the move is rolled with, such as hard (define)")
+	fs.StringVar(&o.hit, "hit", "", "what happens on a 10+, in quotes (define)")
+	fs.StringVar(&o.partial, "partial", "", "what happens on a 7-9, in quotes (define)")
+	fs.StringVar(&o.miss, "miss", "", "what happens on a 6-, in quotes (define)")
+}
+
+// moveFlags declares the flags accepted by the move command
+func moveFlags(fs *flag.FlagSet) {
+	new(moveOptions).flags(fs)
+}
+
+// modifierFlags declares the flags accepted by the modifier command
+func modifierFlags(fs *flag.FlagSet) {
+	fs.String("character", "", "the character whose modifiers change; your active character by default")
+}
+
+// Move defines, shows or lists the Powered by the Apocalypse moves of a campaign.  Only the
+// campaign's storytellers may change its moves.
+func (bs *BotServiceHandler) Move(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrMoveGuild
+	}
+	fields = subcommandFirst(joinQuoted(fields), moveSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(moveSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("move")
+	opts := new(moveOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "move", err)
+	}
+	name := strings.Join(args, " ")
+	c, err := campaign.Find(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID, opts.campaign)
+	if err != nil {
+		return nil, err
+	}
+	if sub == "define" || sub == "remove" {
+		storyteller, err := bs.runsCampaign(ctx, msg, c)
+		if err != nil {
+			return nil, err
+		}
+		if !storyteller {
+			return nil, errors.Wrap(permission.ErrForbidden, "only storytellers can change moves")
+		}
+	}
+	db := bs.db.Repository("move").(domains.MoveRepository)
+	switch sub {
+	case "define":
+		m := &domains.Move{
+			Name:    name,
+			Stat:    strings.ToLower(strings.TrimSpace(opts.stat)),
+			Hit:     opts.hit,
+			Partial: opts.partial,
+			Miss:    opts.miss,
+		}
+		replaced, err := move.Define(ctx, db, c, m)
+		if err != nil {
+			return nil, err
+		}
+		verb := "defined"
+		if replaced {
+			verb = "redefined"
+		}
+		return &BotResponse{Content: fmt.Sprintf("%s %s for %s", verb, m.Name, c.Name)}, nil
+	case "remove":
+		m, err := move.Remove(ctx, db, c, name)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("removed %s from %s", m.Name, c.Name)}, nil
+	case "show":
+		m, err := move.Find(ctx, db, c, name)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{moveEmbed(m)}}, nil
+	}
+	moves, err := move.List(ctx, db, c)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{moveListEmbed(c, moves)}}, nil
+}
+
+// Modifier adds to, clears or shows the forward and ongoing modifiers a character carries into
+// their moves
+func (bs *BotServiceHandler) Modifier(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	fields = subcommandFirst(joinQuoted(fields), modifierSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(modifierSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	// the amount may be negative, so it is taken out before it can be mistaken for a flag
+	amount, given := 0, false
+	rest := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if n, err := strconv.Atoi(field); err == nil && !given {
+			amount, given = n, true
+			continue
+		}
+		rest = append(rest, field)
+	}
+	fs := newFlagSet("modifier")
+	modifierFlags(fs)
+	_, err := parseInterleaved(fs, rest)
+	if err != nil {
+		return nil, bs.usageError(msg, "modifier", err)
+	}
+	char, err := bs.editableCharacter(ctx, msg, fs.Lookup("character").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	db := bs.db.Repository("modifiers").(domains.RollModifiersRepository)
+	var mods *domains.RollModifiers
+	switch sub {
+	case "forward", "ongoing":
+		if !given {
+			return nil, bs.usageError(msg, "modifier", ErrModifierAmount)
+		}
+		forward, ongoing := amount, 0
+		if sub == "ongoing" {
+			forward, ongoing = 0, amount
+		}
+		mods, err = move.Adjust(ctx, db, char, forward, ongoing)
+	case "clear":
+		err = move.Clear(ctx, db, char)
+		mods = &domains.RollModifiers{Character: char.ID}
+	default:
+		mods, err = move.Modifiers(ctx, db, char)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Content: fmt.Sprintf("**%s** has forward %+d and ongoing %+d.", char.Name, mods.Forward, mods.Ongoing)}, nil
+}
+
+// prepareMove fills in the text of the campaign's move being made, if one is named, and adds the
+// forward and ongoing of the author's active character.  It returns the character and their
+// modifiers, whose forward is spent once the roll is made.
+func (bs *BotServiceHandler) prepareMove(ctx context.Context, msg *discordgo.MessageCreate, rs *roll.PbtARollSystem) (*domains.Character, *domains.RollModifiers, error) {
+	if rs.Move != "" {
+		if msg.GuildID == "" {
+			return nil, nil, ErrMoveGuild
+		}
+		c, err := campaign.Find(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		m, err := move.Find(ctx, bs.db.Repository("move").(domains.MoveRepository), c, rs.Move)
+		if err != nil {
+			return nil, nil, err
+		}
+		rs.Move, rs.Hit, rs.Partial, rs.Miss = m.Name, m.Hit, m.Partial, m.Miss
+	}
+	char, err := bs.optionalCharacter(ctx, msg)
+	if err != nil || char == nil {
+		return nil, nil, err
+	}
+	mods, err := move.Modifiers(ctx, bs.db.Repository("modifiers").(domains.RollModifiersRepository), char)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs.Forward += mods.Forward
+	rs.Ongoing += mods.Ongoing
+	return char, mods, nil
+}
+
+// moveEmbed renders a move and the text of each of its results as a discord embed
+func moveEmbed(m *domains.Move) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{Title: m.Name}
+	if m.Stat != "" {
+		embed.Description = fmt.Sprintf("Roll +%s", m.Stat)
+	}
+	for _, tier := range []struct{ name, text string }{{"10+", m.Hit}, {"7-9", m.Partial}, {"6-", m.Miss}} {
+		if tier.text != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: tier.name, Value: util.Truncate(tier.text, embedFieldLimit)})
+		}
+	}
+	return embed
+}
+
+// moveListEmbed renders the moves of a campaign as a discord embed
+func moveListEmbed(c *domains.Campaign, moves []*domains.Move) *discordgo.MessageEmbed {
+	names := make([]string, 0, len(moves))
+	for _, m := range moves {
+		name := m.Name
+		if m.Stat != "" {
+			name += fmt.Sprintf(" (+%s)", m.Stat)
+		}
+		names = append(names, name)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Moves of %s", c.Name),
+		Description: util.Truncate(orNobody(strings.Join(names, "\n")), embedDescriptionLimit),
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/npc"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrNPCGuild is thrown when an npc command is used outside of a server
+var ErrNPCGuild = errors.New("npcs can only be kept within a server")
+
+// npcSubcommands are the subcommands of the npc command; without one, the campaign's npcs are listed
+var npcSubcommands = []string{"create", "clone", "show", "reveal", "hide", "remove"}
+
+// npcOptions are the flags of the npc command
+type npcOptions struct {
+	campaign, pools, notes string
+	health, defense        int
+	visible                bool
+}
+
+func (o *npcOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.campaign, "campaign", "", "the campaign of the npc; the channel's campaign by default")
+	fs.StringVar(&o.pools, "pools", "", "the npc's dice pools, such as brawl:7,intimidation:5 (create)")
+	fs.IntVar(&o.health, "health", 0, "the npc's health (create)")
+	fs.IntVar(&o.defense, "defense", 0, "the npc's defense (create)")
+	fs.StringVar(&o.notes, "notes", "", "notes about the npc, in quotes (create)")
+	fs.BoolVar(&o.visible, "visible", false, "whether the campaign's players can see the npc (create)")
+}
+
+// npcFlags declares the flags accepted by the npc command
+func npcFlags(fs *flag.FlagSet) {
+	new(npcOptions).flags(fs)
+}
+
+// NPC creates, clones, shows or lists the npcs of a campaign.  Only the campaign's storytellers may
+// change its npcs or see those which are hidden.
+func (bs *BotServiceHandler) NPC(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	if msg.GuildID == "" {
+		return nil, ErrNPCGuild
+	}
+	fields = subcommandFirst(joinQuoted(fields), npcSubcommands...)
+	sub := ""
+	if len(fields) > 0 && util.ContainsString(npcSubcommands, fields[0]) {
+		sub = fields[0]
+		fields = fields[1:]
+	}
+	fs := newFlagSet("npc")
+	opts := new(npcOptions)
+	opts.flags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "npc", err)
+	}
+	c, err := campaign.Find(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID, opts.campaign)
+	if err != nil {
+		return nil, err
+	}
+	storyteller, err := bs.runsCampaign(ctx, msg, c)
+	if err != nil {
+		return nil, err
+	}
+	if sub != "" && sub != "show" && !storyteller {
+		return nil, errors.Wrap(permission.ErrForbidden, "only storytellers can change npcs")
+	}
+	db := bs.db.Repository("npc").(domains.NPCRepository)
+	switch sub {
+	case "create":
+		n := &domains.NPC{
+			Name:    strings.Join(args, " "),
+			Health:  opts.health,
+			Defense: opts.defense,
+			Notes:   opts.notes,
+			Hidden:  !opts.visible,
+		}
+		n.Pools, err = npc.ParsePools(opts.pools)
+		if err != nil {
+			return nil, bs.usageError(msg, "npc", err)
+		}
+		err = npc.Create(ctx, db, c, n)
+		if err != nil {
+			return nil, err
+		}
+		note := fmt.Sprintf("created %s in %s", n.Name, c.Name)
+		if n.Hidden {
+			note += fmt.Sprintf("; it is hidden from players until you `%snpc reveal %s`", commandPrefix(msg, "npc"), n.Name)
+		}
+		return &BotResponse{Content: note}, nil
+	case "clone":
+		count := 1
+		if len(args) > 1 {
+			if n, err := strconv.Atoi(args[len(args)-1]); err == nil {
+				count = n
+				args = args[:len(args)-1]
+			}
+		}
+		clones, err := npc.Clone(ctx, db, c, strings.Join(args, " "), count)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(clones))
+		for _, clone := range clones {
+			names = append(names, clone.Name)
+		}
+		return &BotResponse{Content: fmt.Sprintf("cloned %s", strings.Join(names, ", "))}, nil
+	case "reveal", "hide":
+		n, err := npc.Find(ctx, db, c, strings.Join(args, " "), true)
+		if err != nil {
+			return nil, err
+		}
+		err = npc.SetHidden(ctx, db, n, sub == "hide")
+		if err != nil {
+			return nil, err
+		}
+		if n.Hidden {
+			return &BotResponse{Content: fmt.Sprintf("%s is hidden from players", n.Name)}, nil
+		}
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{npcEmbed(n)}}, nil
+	case "remove":
+		n, err := npc.Remove(ctx, db, c, strings.Join(args, " "))
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Content: fmt.Sprintf("removed %s from %s", n.Name, c.Name)}, nil
+	case "show":
+		n, err := npc.Find(ctx, db, c, strings.Join(args, " "), storyteller)
+		if err != nil {
+			return nil, err
+		}
+		return &BotResponse{Embeds: []*discordgo.MessageEmbed{npcEmbed(n)}, Direct: n.Hidden, Ephemeral: n.Hidden}, nil
+	}
+	npcs, err := npc.List(ctx, db, c, storyteller)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{npcListEmbed(c, npcs)}, Direct: storyteller, Ephemeral: storyteller}, nil
+}
+
+// runsCampaign determines whether the author is a storyteller of a campaign, or of the guild
+func (bs *BotServiceHandler) runsCampaign(ctx context.Context, msg *discordgo.MessageCreate, c *domains.Campaign) (bool, error) {
+	role, err := roleOf(ctx, bs.bot, bs.db, msg.GuildID, msg.Author.ID)
+	if err != nil {
+		return false, err
+	}
+	role = permission.InCampaign(role, msg.Author.ID, c)
+	return permission.Can(role, msg.Author.ID, permission.ManageCampaign, nil), nil
+}
+
+// npcEmbed renders the stat block of an npc as a discord embed
+func npcEmbed(n *domains.NPC) *discordgo.MessageEmbed {
+	names := make([]string, 0, len(n.Pools))
+	for name := range n.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pools := make([]string, 0, len(names))
+	for _, name := range names {
+		pools = append(pools, fmt.Sprintf("%s %d", strings.Title(name), n.Pools[name]))
+	}
+	embed := &discordgo.MessageEmbed{
+		Title: util.Truncate(n.Name, embedTitleLimit),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Health", Value: strconv.Itoa(n.Health), Inline: true},
+			{Name: "Defense", Value: strconv.Itoa(n.Defense), Inline: true},
+			{Name: "Pools", Value: util.Truncate(orNobody(strings.Join(pools, ", ")), embedFieldLimit)},
+		},
+	}
+	if n.Notes != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Notes", Value: util.Truncate(n.Notes, embedFieldLimit)})
+	}
+	if n.Hidden {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "hidden from players"}
+	}
+	return embed
+}
+
+// npcListEmbed renders the npcs of a campaign as a discord embed
+func npcListEmbed(c *domains.Campaign, npcs []*domains.NPC) *discordgo.MessageEmbed {
+	names := make([]string, 0, len(npcs))
+	for _, n := range npcs {
+		name := n.Name
+		if n.Hidden {
+			name += " (hidden)"
+		}
+		names = append(names, name)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       util.Truncate(fmt.Sprintf("NPCs of %s", c.Name), embedTitleLimit),
+		Description: util.Truncate(orNobody(strings.Join(names, "\n")), embedDescriptionLimit),
+	}
+}
+
+// ErrAsSystem is thrown when a roll other than a chronicles of darkness roll is made as an npc or character
+var ErrAsSystem = errors.New("only chronicles of darkness pools can be rolled as an npc or character")
+
+// ErrAsPool is thrown when a roll is made as an npc or character without naming a pool
+var ErrAsPool = errors.New("name the pool to roll, such as brawl or strength+brawl")
+
+// asFlags declares the flag which rolls the pools of an npc or character
+func asFlags(fs *flag.FlagSet, name *string) {
+	fs.StringVar(name, "as", "", `the npc or character whose pool is rolled, such as -as "Bouncer" brawl`)
+}
+
+// rollAs adds up a pool from the pools of a named npc, for the storytellers of the channel's
+// campaign, or else from the sheet of one of the author's characters, along with its conditions.
+// It returns the character, if the pool was theirs, and a title describing who rolled.
+func (bs *BotServiceHandler) rollAs(ctx context.Context, msg *discordgo.MessageCreate, name, pool string) (int, *domains.Character, string, error) {
+	if strings.TrimSpace(pool) == "" {
+		return 0, nil, "", ErrAsPool
+	}
+	c, err := campaign.ForChannel(ctx, bs.db.Repository("campaign").(domains.CampaignRepository), msg.GuildID, msg.ChannelID)
+	if err != nil {
+		return 0, nil, "", err
+	}
+	if c != nil {
+		storyteller, err := bs.runsCampaign(ctx, msg, c)
+		if err != nil {
+			return 0, nil, "", err
+		}
+		n, err := npc.Find(ctx, bs.db.Repository("npc").(domains.NPCRepository), c, name, true)
+		switch {
+		case storyteller && err == nil:
+			dice, err := npc.Pool(n, pool)
+			if err != nil {
+				return 0, nil, "", err
+			}
+			return dice, nil, fmt.Sprintf("%s rolls %s", n.Name, pool), nil
+		case err != nil && err != npc.ErrNPCNotFound:
+			return 0, nil, "", err
+		}
+	}
+	char, err := bs.activeCharacter(ctx, msg, name)
+	if err != nil {
+		return 0, nil, "", err
+	}
+	dice, title, err := characterPool(char, pool)
+	if err != nil {
+		return 0, nil, "", err
+	}
+	return dice, char, title, nil
+}
+
+// characterPool adds up a dice pool from a character's sheet, and describes the roll of it
+func characterPool(char *domains.Character, pool string) (int, string, error) {
+	dice, applied, err := sheet.SheetPool(char.Sheet, pool)
+	if err != nil {
+		return 0, "", err
+	}
+	title := fmt.Sprintf("%s rolls %s", char.Name, pool)
+	if len(applied) > 0 {
+		title += " with " + strings.Join(applied, ", ")
+	}
+	return dice, title, nil
+}
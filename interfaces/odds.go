@@ -0,0 +1,193 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+)
+
+// histogramRows is the most rows an odds histogram is drawn with in discord
+const histogramRows = 24
+
+// histogramWidth is the length of the bar of the likeliest row of an odds histogram
+const histogramWidth = 20
+
+// Odds works out the odds of a roll
+func (bs *BotServiceHandler) Odds(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	gs, err := bs.guildSettings(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	fs := newFlagSet("odds")
+	oddsFlags(fs)
+	fs.Set("system", gs.RollSystem)
+	err = fs.Parse(fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "odds", err)
+	}
+	system := fs.Lookup("system").Value.String()
+	newSystem, args, err := oddsSystem(system, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, "odds", err)
+	}
+	odds, err := roll.CalculateOdds(ctx, newSystem, args, roll.DefaultTrials)
+	if err != nil {
+		return nil, err
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{oddsEmbed(odds, system, strings.Join(fields, " "))}}, nil
+}
+
+// Odds works out the odds of a roll, described as it would be to the roll command
+func (ws *WebServiceHandler) Odds(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	params := req.URL.Query()
+	newSystem, args, err := oddsSystem(params.Get("system"), strings.Fields(params.Get("roll")))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	odds, err := roll.CalculateOdds(req.Context(), newSystem, args, roll.DefaultTrials)
+	switch err {
+	case nil:
+	case roll.ErrOddsDice, roll.ErrOddsAgain:
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(res).Encode(odds)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// oddsSystem checks the flags of a roll, and returns a function to create its system configured by
+// them, along with the dice to roll
+func oddsSystem(system string, fields []string) (func() (roll.System, error), []string, error) {
+	newSystem := func() (roll.System, error) {
+		rs, err := roll.NewRoller(system, nil)
+		if err != nil {
+			return nil, err
+		}
+		fs := newFlagSet(system)
+		rs.Flags(fs)
+		return rs, fs.Parse(fields)
+	}
+	rs, err := roll.NewRoller(system, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs := newFlagSet(system)
+	rs.Flags(fs)
+	err = fs.Parse(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newSystem, fs.Args(), nil
+}
+
+// oddsEmbed describes the odds of a roll as a discord embed, with a histogram of its results
+func oddsEmbed(odds *roll.Odds, system, described string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       util.Truncate(fmt.Sprintf("The odds of %s", described), embedTitleLimit),
+		Description: util.Truncate(histogram(odds), embedDescriptionLimit),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s, worked out exactly", system)},
+	}
+	if !odds.Exact {
+		embed.Footer.Text = fmt.Sprintf("%s, estimated from %d rolls", system, odds.Trials)
+	}
+	expected := &discordgo.MessageEmbedField{Name: "Expected", Value: fmt.Sprintf("%.2f", odds.Expected), Inline: true}
+	embed.Fields = append(embed.Fields, expected)
+	if !odds.Outcomes {
+		return embed
+	}
+	expected.Value += " successes"
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{Name: "Success", Value: percent(odds.Success), Inline: true},
+		&discordgo.MessageEmbedField{Name: "Exceptional", Value: percent(odds.Exceptional), Inline: true},
+	)
+	if odds.DramaticFailure > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Dramatic failure", Value: percent(odds.DramaticFailure), Inline: true})
+	}
+	return embed
+}
+
+// histogram draws the chance of each result of a roll as a bar chart.  Results too unlikely to
+// show are left off either end, and wide ranges of results are grouped into fewer rows.
+func histogram(odds *roll.Odds) string {
+	buckets := odds.Histogram
+	for len(buckets) > 1 && buckets[0].Chance < 0.0005 {
+		buckets = buckets[1:]
+	}
+	for len(buckets) > 1 && buckets[len(buckets)-1].Chance < 0.0005 {
+		buckets = buckets[:len(buckets)-1]
+	}
+	per := (len(buckets) + histogramRows - 1) / histogramRows
+	type row struct {
+		label  string
+		chance float64
+	}
+	rows := make([]row, 0, histogramRows)
+	var likeliest float64
+	for i := 0; i < len(buckets); i += per {
+		group := buckets[i:util.Min(i+per, len(buckets))]
+		r := row{label: fmt.Sprint(group[0].Value)}
+		if len(group) > 1 {
+			r.label = fmt.Sprintf("%d-%d", group[0].Value, group[len(group)-1].Value)
+		}
+		for _, b := range group {
+			r.chance += b.Chance
+		}
+		if r.chance > likeliest {
+			likeliest = r.chance
+		}
+		rows = append(rows, r)
+	}
+	width := 0
+	for _, r := range rows {
+		if len(r.label) > width {
+			width = len(r.label)
+		}
+	}
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		bar := strings.Repeat("█", int(r.chance/likeliest*histogramWidth+0.5))
+		lines = append(lines, fmt.Sprintf("%*s %-*s %s", width, r.label, histogramWidth, bar, percent(r.chance)))
+	}
+	return "```\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+// percent formats a chance as a percentage
+func percent(chance float64) string {
+	return fmt.Sprintf("%.1f%%", chance*100)
+}
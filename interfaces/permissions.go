@@ -0,0 +1,47 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/interfaces/repositories"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/permission"
+)
+
+// roleOf determines the role a user has in a guild.  The bot and web handlers both check this
+// role against the permission policy, so that they agree on who may do what.
+func roleOf(ctx context.Context, bot Bot, db repositories.Database, guild, user string) (domains.Role, error) {
+	if guild == "" {
+		return domains.RoleNone, nil
+	}
+	member, err := bot.Member(guild, user)
+	if err != nil {
+		return domains.RoleNone, err
+	}
+	gs, err := config.Get(ctx, db.Repository("guild").(domains.GuildSettingsRepository), guild)
+	if err != nil {
+		return domains.RoleNone, err
+	}
+	return permission.RoleOf(member, gs), nil
+}
@@ -20,7 +20,15 @@
 
 package interfaces
 
+import (
+	"context"
+
+	"github.com/kkragenbrink/slate/domains"
+)
+
 // Random describes what methods the random service must have
 type Random interface {
+	Draw(times int, min, max int64) (*domains.RollDraw, error)
 	Rand(times int, min, max int64) ([]int64, error)
+	VerifyDraw(ctx context.Context, d *domains.RollDraw) (bool, error)
 }
@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The CampaignRepository stores the instructions to get and set campaigns from the database
+type CampaignRepository struct {
+	db Database
+}
+
+// NewCampaignRepository returns a new CampaignRepository instance
+func NewCampaignRepository(db Database) *CampaignRepository {
+	cr := new(CampaignRepository)
+	cr.db = db
+	return cr
+}
+
+// FindByGuild retrieves a list of Campaigns from the database by the guild ID.
+func (cr *CampaignRepository) FindByGuild(ctx context.Context, id string) ([]*domains.Campaign, error) {
+	query := "SELECT id, name, guild, system, storytellers, members, channels FROM campaigns WHERE guild = $1 ORDER BY name"
+	gid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	rows, err := cr.db.Conn().QueryContext(ctx, query, gid)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get campaigns")
+	}
+	defer rows.Close()
+	campaigns := make([]*domains.Campaign, 0)
+	for rows.Next() {
+		var c domains.Campaign
+		var id int64
+		err := rows.Scan(&id, &c.Name, &c.Guild, &c.System, pq.Array(&c.Storytellers), pq.Array(&c.Members), pq.Array(&c.Channels))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan campaign")
+		}
+		sid := snowflake.ID(id)
+		c.ID = &sid
+		campaigns = append(campaigns, &c)
+	}
+	return campaigns, nil
+}
+
+// FindByID retrieves a Campaign from the database by ID.
+func (cr *CampaignRepository) FindByID(ctx context.Context, id string) (*domains.Campaign, error) {
+	var c domains.Campaign
+	idc, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	sid := snowflake.ID(idc)
+	c.ID = &sid
+	query := "SELECT name, guild, system, storytellers, members, channels FROM campaigns WHERE id = $1"
+	row := cr.db.Conn().QueryRowContext(ctx, query, sid.Int64())
+	err = row.Scan(&c.Name, &c.Guild, &c.System, pq.Array(&c.Storytellers), pq.Array(&c.Members), pq.Array(&c.Channels))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve campaign from the database")
+	}
+	return &c, nil
+}
+
+// Store saves a campaign to the database.
+// If the campaign does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (cr *CampaignRepository) Store(ctx context.Context, c *domains.Campaign) error {
+	if c.ID == nil {
+		c.ID = cr.db.ID()
+	}
+	gid, err := strconv.ParseInt(c.Guild, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse guild")
+	}
+	query := "INSERT INTO campaigns (id, name, guild, system, storytellers, members, channels) VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, storytellers = EXCLUDED.storytellers, members = EXCLUDED.members, channels = EXCLUDED.channels"
+	_, err = cr.db.Conn().ExecContext(ctx, query, c.ID.Int64(), c.Name, gid, c.System,
+		pq.Array(notNull(c.Storytellers)), pq.Array(notNull(c.Members)), pq.Array(notNull(c.Channels)))
+	if err != nil {
+		return errors.Wrap(err, "could not upsert campaign")
+	}
+	return nil
+}
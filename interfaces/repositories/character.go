@@ -22,6 +22,7 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"github.com/bwmarrin/snowflake"
 	"github.com/kkragenbrink/slate/domains"
@@ -44,7 +45,7 @@ func NewCharacterRepository(db Database) *CharacterRepository {
 
 // FindByPlayer retrieves a list of Characters from the database by the player ID.
 func (cr *CharacterRepository) FindByPlayer(ctx context.Context, id string) ([]*domains.Character, error) {
-	query := "SELECT id, name, guild, player, system FROM characters WHERE player = $1"
+	query := "SELECT id, name, guild, player, system, campaign FROM characters WHERE player = $1"
 	pid, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not parse id")
@@ -58,12 +59,43 @@ func (cr *CharacterRepository) FindByPlayer(ctx context.Context, id string) ([]*
 	for rows.Next() {
 		var char domains.Character
 		var id int64
-		err := rows.Scan(&id, &char.Name, &char.Guild, &char.Player, &char.System)
+		var campaign sql.NullInt64
+		err := rows.Scan(&id, &char.Name, &char.Guild, &char.Player, &char.System, &campaign)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not scan character")
 		}
 		sid := snowflake.ID(id)
 		char.ID = &sid
+		char.Campaign = nullID(campaign)
+		chars = append(chars, &char)
+	}
+	return chars, nil
+}
+
+// FindByGuild retrieves a list of Characters from the database by the guild ID.
+func (cr *CharacterRepository) FindByGuild(ctx context.Context, id string) ([]*domains.Character, error) {
+	query := "SELECT id, name, guild, player, system, campaign FROM characters WHERE guild = $1"
+	gid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	rows, err := cr.db.Conn().QueryContext(ctx, query, gid)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get characters")
+	}
+	defer rows.Close()
+	chars := make([]*domains.Character, 0)
+	for rows.Next() {
+		var char domains.Character
+		var id int64
+		var campaign sql.NullInt64
+		err := rows.Scan(&id, &char.Name, &char.Guild, &char.Player, &char.System, &campaign)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan character")
+		}
+		sid := snowflake.ID(id)
+		char.ID = &sid
+		char.Campaign = nullID(campaign)
 		chars = append(chars, &char)
 	}
 	return chars, nil
@@ -79,13 +111,15 @@ func (cr *CharacterRepository) FindByID(ctx context.Context, id string) (*domain
 	}
 	sid = snowflake.ID(idc)
 	c.ID = &sid
-	query := "SELECT name, guild, player, system, sheet FROM characters WHERE id = $1"
+	query := "SELECT name, guild, player, system, campaign, sheet FROM characters WHERE id = $1"
 	row := cr.db.Conn().QueryRowContext(ctx, query, sid.Int64())
 	var sh json.RawMessage
-	err = row.Scan(&c.Name, &c.Guild, &c.Player, &c.System, &sh)
+	var campaign sql.NullInt64
+	err = row.Scan(&c.Name, &c.Guild, &c.Player, &c.System, &campaign, &sh)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not retrieve character from the database")
 	}
+	c.Campaign = nullID(campaign)
 	c.Sheet = sheet.GenerateSheetBySystem(c.System, sh)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not unmarshal sheet for character")
@@ -103,8 +137,9 @@ func (cr *CharacterRepository) Store(ctx context.Context, c *domains.Character)
 	if err != nil {
 		return errors.Wrap(err, "could not marshal sheet")
 	}
-	query := "INSERT INTO characters (id, name, guild, player, system, sheet) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, sheet = EXCLUDED.sheet"
-	result, err := cr.db.Conn().ExecContext(ctx, query, c.ID.Int64(), c.Name, c.Guild, c.Player, c.System, sh)
+	query := "INSERT INTO characters (id, name, guild, player, system, campaign, sheet) VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, campaign = EXCLUDED.campaign, sheet = EXCLUDED.sheet"
+	result, err := cr.db.Conn().ExecContext(ctx, query, c.ID.Int64(), c.Name, c.Guild, c.Player, c.System, idValue(c.Campaign), sh)
 	if err != nil {
 		return errors.Wrap(err, "could not upsert character")
 	}
@@ -114,3 +149,20 @@ func (cr *CharacterRepository) Store(ctx context.Context, c *domains.Character)
 	}
 	return nil
 }
+
+// nullID converts a nullable id column to an id
+func nullID(n sql.NullInt64) *snowflake.ID {
+	if !n.Valid {
+		return nil
+	}
+	id := snowflake.ID(n.Int64)
+	return &id
+}
+
+// idValue converts an id to a nullable id column
+func idValue(id *snowflake.ID) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: id.Int64(), Valid: true}
+}
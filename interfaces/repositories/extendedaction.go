@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The ExtendedActionRepository stores the instructions to get and set extended actions from the database
+type ExtendedActionRepository struct {
+	db Database
+}
+
+// NewExtendedActionRepository returns a new ExtendedActionRepository instance
+func NewExtendedActionRepository(db Database) *ExtendedActionRepository {
+	er := new(ExtendedActionRepository)
+	er.db = db
+	return er
+}
+
+// extendedActionColumns are the columns of an extended action, in the order they are scanned
+const extendedActionColumns = "id, guild, channel, player, name, target, pool, skill, roll_limit, interval_seconds, again, rote, " +
+	"successes, rolls, exceptional, choosing, status, started_at, updated_at"
+
+// FindActive retrieves the extended action a player is rolling in a channel.  A player without one has none.
+func (er *ExtendedActionRepository) FindActive(ctx context.Context, channel, player string) (*domains.ExtendedAction, error) {
+	query := "SELECT " + extendedActionColumns + " FROM extended_actions WHERE channel = $1 AND player = $2 AND status = $3"
+	row := er.db.Conn().QueryRowContext(ctx, query, channel, player, string(domains.ExtendedActive))
+	a, err := scanExtendedAction(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve extended action from the database")
+	}
+	return a, nil
+}
+
+// scanExtendedAction scans the columns of an extended action from a row
+func scanExtendedAction(row interface{ Scan(...interface{}) error }) (*domains.ExtendedAction, error) {
+	var a domains.ExtendedAction
+	var id, interval int64
+	var guild sql.NullString
+	err := row.Scan(&id, &guild, &a.Channel, &a.Player, &a.Name, &a.Target, &a.Pool, &a.Skill, &a.Limit, &interval, &a.Again,
+		&a.Rote, &a.Successes, &a.Rolls, &a.Exceptional, &a.Choosing, &a.Status, &a.StartedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	sid := snowflake.ID(id)
+	a.ID = &sid
+	a.Guild = guild.String
+	a.Interval = time.Duration(interval) * time.Second
+	return &a, nil
+}
+
+// Store saves an extended action to the database.
+// If the action does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (er *ExtendedActionRepository) Store(ctx context.Context, a *domains.ExtendedAction) error {
+	if a.ID == nil {
+		a.ID = er.db.ID()
+	}
+	if a.StartedAt.IsZero() {
+		a.StartedAt = time.Now()
+	}
+	a.UpdatedAt = time.Now()
+	var gid sql.NullInt64
+	if a.Guild != "" {
+		n, err := strconv.ParseInt(a.Guild, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "could not parse guild")
+		}
+		gid = sql.NullInt64{Int64: n, Valid: true}
+	}
+	query := "INSERT INTO extended_actions (" + extendedActionColumns + ") " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) " +
+		"ON CONFLICT (id) DO UPDATE SET target = EXCLUDED.target, interval_seconds = EXCLUDED.interval_seconds, " +
+		"successes = EXCLUDED.successes, rolls = EXCLUDED.rolls, exceptional = EXCLUDED.exceptional, " +
+		"choosing = EXCLUDED.choosing, status = EXCLUDED.status, updated_at = EXCLUDED.updated_at"
+	_, err := er.db.Conn().ExecContext(ctx, query, a.ID.Int64(), gid, a.Channel, a.Player, a.Name, a.Target, a.Pool, a.Skill,
+		a.Limit, int64(a.Interval/time.Second), a.Again, a.Rote, a.Successes, a.Rolls, a.Exceptional, a.Choosing,
+		string(a.Status), a.StartedAt, a.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert extended action")
+	}
+	return nil
+}
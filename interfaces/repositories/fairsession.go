@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The FairSessionRepository stores the instructions to get and set fair sessions from the database
+type FairSessionRepository struct {
+	db Database
+}
+
+// NewFairSessionRepository returns a new FairSessionRepository instance
+func NewFairSessionRepository(db Database) *FairSessionRepository {
+	fr := new(FairSessionRepository)
+	fr.db = db
+	return fr
+}
+
+// fairSessionColumns are the columns of a fair session, in the order they are scanned
+const fairSessionColumns = "id, guild, channel, hash, seed, started_at, ended_at"
+
+// FindActive retrieves the fair session running in a channel.  A channel without one has none.
+func (fr *FairSessionRepository) FindActive(ctx context.Context, channel string) (*domains.FairSession, error) {
+	query := "SELECT " + fairSessionColumns + " FROM fair_sessions WHERE channel = $1 AND ended_at IS NULL"
+	s, err := scanFairSession(fr.db.Conn().QueryRowContext(ctx, query, channel))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve fair session from the database")
+	}
+	return s, nil
+}
+
+// FindByID retrieves a fair session from the database by ID.
+func (fr *FairSessionRepository) FindByID(ctx context.Context, id string) (*domains.FairSession, error) {
+	idc, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	query := "SELECT " + fairSessionColumns + " FROM fair_sessions WHERE id = $1"
+	s, err := scanFairSession(fr.db.Conn().QueryRowContext(ctx, query, idc))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve fair session from the database")
+	}
+	return s, nil
+}
+
+// scanFairSession scans the columns of a fair session from a row
+func scanFairSession(row interface{ Scan(...interface{}) error }) (*domains.FairSession, error) {
+	var s domains.FairSession
+	var id int64
+	var guild sql.NullString
+	var ended pq.NullTime
+	err := row.Scan(&id, &guild, &s.Channel, &s.Hash, &s.Seed, &s.StartedAt, &ended)
+	if err != nil {
+		return nil, err
+	}
+	sid := snowflake.ID(id)
+	s.ID = &sid
+	s.Guild = guild.String
+	if ended.Valid {
+		s.EndedAt = &ended.Time
+	}
+	return &s, nil
+}
+
+// Store saves a fair session to the database.
+// If the session does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (fr *FairSessionRepository) Store(ctx context.Context, s *domains.FairSession) error {
+	if s.ID == nil {
+		s.ID = fr.db.ID()
+	}
+	if s.StartedAt.IsZero() {
+		s.StartedAt = time.Now()
+	}
+	var gid sql.NullInt64
+	if s.Guild != "" {
+		n, err := strconv.ParseInt(s.Guild, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "could not parse guild")
+		}
+		gid = sql.NullInt64{Int64: n, Valid: true}
+	}
+	var ended pq.NullTime
+	if s.EndedAt != nil {
+		ended = pq.NullTime{Time: *s.EndedAt, Valid: true}
+	}
+	query := "INSERT INTO fair_sessions (" + fairSessionColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+		"ON CONFLICT (id) DO UPDATE SET ended_at = EXCLUDED.ended_at"
+	_, err := fr.db.Conn().ExecContext(ctx, query, s.ID.Int64(), gid, s.Channel, s.Hash, s.Seed, s.StartedAt, ended)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert fair session")
+	}
+	return nil
+}
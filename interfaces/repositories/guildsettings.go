@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The GuildSettingsRepository stores the instructions to get and set guild settings from the
+// database.  Settings are read on every message, so they are cached once found.
+type GuildSettingsRepository struct {
+	db    Database
+	cache map[string]*domains.GuildSettings
+	mutex sync.RWMutex
+}
+
+// NewGuildSettingsRepository returns a new GuildSettingsRepository instance
+func NewGuildSettingsRepository(db Database) *GuildSettingsRepository {
+	gr := new(GuildSettingsRepository)
+	gr.db = db
+	gr.cache = make(map[string]*domains.GuildSettings)
+	return gr
+}
+
+// FindByGuild retrieves the settings for a guild.  A guild which has never been configured has empty
+// settings.
+func (gr *GuildSettingsRepository) FindByGuild(ctx context.Context, guild string) (*domains.GuildSettings, error) {
+	gr.mutex.RLock()
+	cached, ok := gr.cache[guild]
+	gr.mutex.RUnlock()
+	if ok {
+		copied := *cached
+		return &copied, nil
+	}
+
+	gid, err := strconv.ParseInt(guild, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse guild")
+	}
+	s := &domains.GuildSettings{Guild: guild}
+	query := "SELECT prefix, roll_system, sheet_system, verbose, inline_rolls, roll_channels, storytellers FROM guild_settings WHERE guild = $1"
+	row := gr.db.Conn().QueryRowContext(ctx, query, gid)
+	err = row.Scan(&s.Prefix, &s.RollSystem, &s.SheetSystem, &s.Verbose, &s.InlineRolls, pq.Array(&s.RollChannels), pq.Array(&s.Storytellers))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrap(err, "could not retrieve guild settings from the database")
+	}
+	gr.remember(s)
+	return s, nil
+}
+
+// Store saves the settings for a guild to the database.
+func (gr *GuildSettingsRepository) Store(ctx context.Context, s *domains.GuildSettings) error {
+	gid, err := strconv.ParseInt(s.Guild, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse guild")
+	}
+	query := "INSERT INTO guild_settings (guild, prefix, roll_system, sheet_system, verbose, inline_rolls, roll_channels, storytellers) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) " +
+		"ON CONFLICT (guild) DO UPDATE SET prefix = EXCLUDED.prefix, roll_system = EXCLUDED.roll_system, sheet_system = EXCLUDED.sheet_system, " +
+		"verbose = EXCLUDED.verbose, inline_rolls = EXCLUDED.inline_rolls, roll_channels = EXCLUDED.roll_channels, storytellers = EXCLUDED.storytellers"
+	_, err = gr.db.Conn().ExecContext(ctx, query, gid, s.Prefix, s.RollSystem, s.SheetSystem, s.Verbose, s.InlineRolls, pq.Array(notNull(s.RollChannels)), pq.Array(notNull(s.Storytellers)))
+	if err != nil {
+		return errors.Wrap(err, "could not upsert guild settings")
+	}
+	gr.remember(s)
+	return nil
+}
+
+// notNull replaces a nil array, which would be stored as null, with an empty one
+func notNull(a []string) []string {
+	if a == nil {
+		return []string{}
+	}
+	return a
+}
+
+func (gr *GuildSettingsRepository) remember(s *domains.GuildSettings) {
+	copied := *s
+	gr.mutex.Lock()
+	gr.cache[s.Guild] = &copied
+	gr.mutex.Unlock()
+}
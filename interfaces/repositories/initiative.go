@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The InitiativeRepository stores the instructions to get and set initiatives from the database
+type InitiativeRepository struct {
+	db Database
+}
+
+// NewInitiativeRepository returns a new InitiativeRepository instance
+func NewInitiativeRepository(db Database) *InitiativeRepository {
+	ir := new(InitiativeRepository)
+	ir.db = db
+	return ir
+}
+
+// initiativeColumns are the columns of an initiative, in the order they are scanned
+const initiativeColumns = "id, guild, channel, system, message, round, turn, entries, started_at, updated_at, ended_at"
+
+// FindActive retrieves the initiative of the combat running in a channel.  A channel without one has none.
+func (ir *InitiativeRepository) FindActive(ctx context.Context, channel string) (*domains.Initiative, error) {
+	query := "SELECT " + initiativeColumns + " FROM initiatives WHERE channel = $1 AND ended_at IS NULL"
+	i, err := scanInitiative(ir.db.Conn().QueryRowContext(ctx, query, channel))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve initiative from the database")
+	}
+	return i, nil
+}
+
+// scanInitiative scans the columns of an initiative from a row
+func scanInitiative(row interface{ Scan(...interface{}) error }) (*domains.Initiative, error) {
+	var i domains.Initiative
+	var id int64
+	var guild sql.NullString
+	var entries []byte
+	var ended pq.NullTime
+	err := row.Scan(&id, &guild, &i.Channel, &i.System, &i.Message, &i.Round, &i.Turn, &entries, &i.StartedAt, &i.UpdatedAt, &ended)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(entries, &i.Entries)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal initiative entries")
+	}
+	sid := snowflake.ID(id)
+	i.ID = &sid
+	i.Guild = guild.String
+	if ended.Valid {
+		i.EndedAt = &ended.Time
+	}
+	return &i, nil
+}
+
+// Store saves an initiative to the database.
+// If the initiative does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (ir *InitiativeRepository) Store(ctx context.Context, i *domains.Initiative) error {
+	if i.ID == nil {
+		i.ID = ir.db.ID()
+	}
+	if i.StartedAt.IsZero() {
+		i.StartedAt = time.Now()
+	}
+	i.UpdatedAt = time.Now()
+	var gid sql.NullInt64
+	if i.Guild != "" {
+		n, err := strconv.ParseInt(i.Guild, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "could not parse guild")
+		}
+		gid = sql.NullInt64{Int64: n, Valid: true}
+	}
+	entries, err := json.Marshal(i.Entries)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal initiative entries")
+	}
+	var ended pq.NullTime
+	if i.EndedAt != nil {
+		ended = pq.NullTime{Time: *i.EndedAt, Valid: true}
+	}
+	query := "INSERT INTO initiatives (" + initiativeColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) " +
+		"ON CONFLICT (id) DO UPDATE SET message = EXCLUDED.message, round = EXCLUDED.round, turn = EXCLUDED.turn, " +
+		"entries = EXCLUDED.entries, updated_at = EXCLUDED.updated_at, ended_at = EXCLUDED.ended_at"
+	_, err = ir.db.Conn().ExecContext(ctx, query, i.ID.Int64(), gid, i.Channel, i.System, i.Message, i.Round, i.Turn, entries,
+		i.StartedAt, i.UpdatedAt, ended)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert initiative")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The MacroRepository stores the instructions to get and set macros from the database
+type MacroRepository struct {
+	db Database
+}
+
+// NewMacroRepository returns a new MacroRepository instance
+func NewMacroRepository(db Database) *MacroRepository {
+	mr := new(MacroRepository)
+	mr.db = db
+	return mr
+}
+
+// FindByPlayer retrieves the macros a player saved, for every character and for each, from the database.
+func (mr *MacroRepository) FindByPlayer(ctx context.Context, player string) ([]*domains.Macro, error) {
+	query := "SELECT id, player, character, name, roll, updated_at FROM macros WHERE player = $1 ORDER BY lower(name)"
+	rows, err := mr.db.Conn().QueryContext(ctx, query, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get macros")
+	}
+	defer rows.Close()
+	macros := make([]*domains.Macro, 0)
+	for rows.Next() {
+		var m domains.Macro
+		var id int64
+		var character sql.NullInt64
+		err := rows.Scan(&id, &m.Player, &character, &m.Name, &m.Roll, &m.UpdatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan macro")
+		}
+		sid := snowflake.ID(id)
+		m.ID = &sid
+		if character.Valid {
+			cid := snowflake.ID(character.Int64)
+			m.Character = &cid
+		}
+		macros = append(macros, &m)
+	}
+	return macros, nil
+}
+
+// Store saves a macro to the database.
+// If the macro does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (mr *MacroRepository) Store(ctx context.Context, m *domains.Macro) error {
+	if m.ID == nil {
+		m.ID = mr.db.ID()
+	}
+	m.UpdatedAt = time.Now()
+	var character sql.NullInt64
+	if m.Character != nil {
+		character = sql.NullInt64{Int64: m.Character.Int64(), Valid: true}
+	}
+	query := "INSERT INTO macros (id, player, character, name, roll, updated_at) VALUES ($1, $2, $3, $4, $5, $6) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, roll = EXCLUDED.roll, updated_at = EXCLUDED.updated_at"
+	_, err := mr.db.Conn().ExecContext(ctx, query, m.ID.Int64(), m.Player, character, m.Name, m.Roll, m.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert macro")
+	}
+	return nil
+}
+
+// Delete removes a macro from the database by ID.
+func (mr *MacroRepository) Delete(ctx context.Context, id string) error {
+	mid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse id")
+	}
+	_, err = mr.db.Conn().ExecContext(ctx, "DELETE FROM macros WHERE id = $1", mid)
+	if err != nil {
+		return errors.Wrap(err, "could not delete macro")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The RollModifiersRepository stores the instructions to get and set roll modifiers from the database
+type RollModifiersRepository struct {
+	db Database
+}
+
+// NewRollModifiersRepository returns a new RollModifiersRepository instance
+func NewRollModifiersRepository(db Database) *RollModifiersRepository {
+	rr := new(RollModifiersRepository)
+	rr.db = db
+	return rr
+}
+
+// FindByCharacter retrieves the roll modifiers of a character by the character ID.  A character
+// without any has none of either.
+func (rr *RollModifiersRepository) FindByCharacter(ctx context.Context, id string) (*domains.RollModifiers, error) {
+	cid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	sid := snowflake.ID(cid)
+	m := &domains.RollModifiers{Character: &sid}
+	query := "SELECT forward, ongoing FROM roll_modifiers WHERE character = $1"
+	err = rr.db.Conn().QueryRowContext(ctx, query, cid).Scan(&m.Forward, &m.Ongoing)
+	if err == sql.ErrNoRows {
+		return m, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve roll modifiers from the database")
+	}
+	return m, nil
+}
+
+// Store saves the roll modifiers of a character to the database.
+func (rr *RollModifiersRepository) Store(ctx context.Context, m *domains.RollModifiers) error {
+	if m.Character == nil {
+		return errors.New("roll modifiers have no character")
+	}
+	query := "INSERT INTO roll_modifiers (character, forward, ongoing) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (character) DO UPDATE SET forward = EXCLUDED.forward, ongoing = EXCLUDED.ongoing"
+	_, err := rr.db.Conn().ExecContext(ctx, query, m.Character.Int64(), m.Forward, m.Ongoing)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert roll modifiers")
+	}
+	return nil
+}
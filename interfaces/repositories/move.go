@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The MoveRepository stores the instructions to get and set moves from the database
+type MoveRepository struct {
+	db Database
+}
+
+// NewMoveRepository returns a new MoveRepository instance
+func NewMoveRepository(db Database) *MoveRepository {
+	mr := new(MoveRepository)
+	mr.db = db
+	return mr
+}
+
+// FindByCampaign retrieves the moves of a campaign from the database by the campaign ID.
+func (mr *MoveRepository) FindByCampaign(ctx context.Context, id string) ([]*domains.Move, error) {
+	query := "SELECT id, campaign, name, stat, hit, partial, miss FROM moves WHERE campaign = $1 ORDER BY lower(name)"
+	cid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	rows, err := mr.db.Conn().QueryContext(ctx, query, cid)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get moves")
+	}
+	defer rows.Close()
+	moves := make([]*domains.Move, 0)
+	for rows.Next() {
+		var m domains.Move
+		var id, campaign int64
+		err := rows.Scan(&id, &campaign, &m.Name, &m.Stat, &m.Hit, &m.Partial, &m.Miss)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan move")
+		}
+		sid, scid := snowflake.ID(id), snowflake.ID(campaign)
+		m.ID, m.Campaign = &sid, &scid
+		moves = append(moves, &m)
+	}
+	return moves, nil
+}
+
+// Store saves a move to the database.
+// If the move does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (mr *MoveRepository) Store(ctx context.Context, m *domains.Move) error {
+	if m.ID == nil {
+		m.ID = mr.db.ID()
+	}
+	if m.Campaign == nil {
+		return errors.New("move has no campaign")
+	}
+	query := "INSERT INTO moves (id, campaign, name, stat, hit, partial, miss) VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, stat = EXCLUDED.stat, hit = EXCLUDED.hit, " +
+		"partial = EXCLUDED.partial, miss = EXCLUDED.miss"
+	_, err := mr.db.Conn().ExecContext(ctx, query, m.ID.Int64(), m.Campaign.Int64(), m.Name, m.Stat, m.Hit, m.Partial, m.Miss)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert move")
+	}
+	return nil
+}
+
+// Delete removes a move from the database by ID.
+func (mr *MoveRepository) Delete(ctx context.Context, id string) error {
+	mid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse id")
+	}
+	_, err = mr.db.Conn().ExecContext(ctx, "DELETE FROM moves WHERE id = $1", mid)
+	if err != nil {
+		return errors.Wrap(err, "could not delete move")
+	}
+	return nil
+}
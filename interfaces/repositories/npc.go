@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The NPCRepository stores the instructions to get and set npcs from the database
+type NPCRepository struct {
+	db Database
+}
+
+// NewNPCRepository returns a new NPCRepository instance
+func NewNPCRepository(db Database) *NPCRepository {
+	nr := new(NPCRepository)
+	nr.db = db
+	return nr
+}
+
+// FindByCampaign retrieves the npcs of a campaign from the database by the campaign ID.
+func (nr *NPCRepository) FindByCampaign(ctx context.Context, id string) ([]*domains.NPC, error) {
+	query := "SELECT id, campaign, name, pools, health, defense, notes, hidden FROM npcs WHERE campaign = $1 ORDER BY lower(name)"
+	cid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	rows, err := nr.db.Conn().QueryContext(ctx, query, cid)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get npcs")
+	}
+	defer rows.Close()
+	npcs := make([]*domains.NPC, 0)
+	for rows.Next() {
+		var n domains.NPC
+		var id, campaign int64
+		var pools []byte
+		err := rows.Scan(&id, &campaign, &n.Name, &pools, &n.Health, &n.Defense, &n.Notes, &n.Hidden)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan npc")
+		}
+		err = json.Unmarshal(pools, &n.Pools)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal npc pools")
+		}
+		sid, scid := snowflake.ID(id), snowflake.ID(campaign)
+		n.ID, n.Campaign = &sid, &scid
+		npcs = append(npcs, &n)
+	}
+	return npcs, nil
+}
+
+// Store saves an npc to the database.
+// If the npc does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (nr *NPCRepository) Store(ctx context.Context, n *domains.NPC) error {
+	if n.ID == nil {
+		n.ID = nr.db.ID()
+	}
+	if n.Campaign == nil {
+		return errors.New("npc has no campaign")
+	}
+	pools, err := json.Marshal(n.Pools)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal npc pools")
+	}
+	query := "INSERT INTO npcs (id, campaign, name, pools, health, defense, notes, hidden) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) " +
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, pools = EXCLUDED.pools, health = EXCLUDED.health, " +
+		"defense = EXCLUDED.defense, notes = EXCLUDED.notes, hidden = EXCLUDED.hidden"
+	_, err = nr.db.Conn().ExecContext(ctx, query, n.ID.Int64(), n.Campaign.Int64(), n.Name, pools, n.Health, n.Defense, n.Notes, n.Hidden)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert npc")
+	}
+	return nil
+}
+
+// Delete removes an npc from the database by ID.
+func (nr *NPCRepository) Delete(ctx context.Context, id string) error {
+	nid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse id")
+	}
+	_, err = nr.db.Conn().ExecContext(ctx, "DELETE FROM npcs WHERE id = $1", nid)
+	if err != nil {
+		return errors.Wrap(err, "could not delete npc")
+	}
+	return nil
+}
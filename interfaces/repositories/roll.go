@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The RollRepository stores the instructions to get and set rolls from the database
+type RollRepository struct {
+	db Database
+}
+
+// NewRollRepository returns a new RollRepository instance
+func NewRollRepository(db Database) *RollRepository {
+	rr := new(RollRepository)
+	rr.db = db
+	return rr
+}
+
+// rollColumns are the columns of a roll, in the order they are scanned
+const rollColumns = "id, guild, channel, player, character, source, system, parameters, visibility, description, outcome, dice, results, proof, drawn_from, revealed, created_at"
+
+// Find retrieves a page of Rolls from the database, newest first.
+func (rr *RollRepository) Find(ctx context.Context, q *domains.RollQuery) ([]*domains.Roll, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	for _, filter := range []struct {
+		condition string
+		value     string
+	}{
+		{"guild = $%d", q.Guild},
+		{"character = $%d", q.Character},
+		{"id < $%d", q.Before},
+	} {
+		if filter.value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(filter.value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse id")
+		}
+		where(filter.condition, n)
+	}
+	if q.Channel != "" {
+		where("channel = $%d", q.Channel)
+	}
+	query := "SELECT " + rollColumns + " FROM rolls"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := rr.db.Conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get rolls")
+	}
+	defer rows.Close()
+	rolls := make([]*domains.Roll, 0)
+	for rows.Next() {
+		r, err := scanRoll(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not scan roll")
+		}
+		rolls = append(rolls, r)
+	}
+	return rolls, nil
+}
+
+// FindByID retrieves a Roll from the database by ID.
+func (rr *RollRepository) FindByID(ctx context.Context, id string) (*domains.Roll, error) {
+	idc, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse id")
+	}
+	row := rr.db.Conn().QueryRowContext(ctx, "SELECT "+rollColumns+" FROM rolls WHERE id = $1", idc)
+	r, err := scanRoll(row)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve roll from the database")
+	}
+	return r, nil
+}
+
+// scanRoll scans the columns of a roll from a row
+func scanRoll(row interface{ Scan(...interface{}) error }) (*domains.Roll, error) {
+	var r domains.Roll
+	var id int64
+	var guild sql.NullString
+	var character sql.NullInt64
+	var results, proof []byte
+	err := row.Scan(&id, &guild, &r.Channel, &r.Player, &character, &r.Source, &r.System, &r.Parameters, &r.Visibility,
+		&r.Description, &r.Outcome, pq.Array(&r.Dice), &results, &proof, &r.DrawnFrom, &r.Revealed, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if proof != nil {
+		r.Proof = new(domains.RollProof)
+		err = json.Unmarshal(proof, r.Proof)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode proof")
+		}
+	}
+	sid := snowflake.ID(id)
+	r.ID = &sid
+	r.Guild = guild.String
+	r.Character = nullID(character)
+	r.Results = results
+	return &r, nil
+}
+
+// Store saves a roll to the database.
+// If the roll does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (rr *RollRepository) Store(ctx context.Context, r *domains.Roll) error {
+	if r.ID == nil {
+		r.ID = rr.db.ID()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	// rolls made in direct messages have no guild
+	var gid sql.NullInt64
+	if r.Guild != "" {
+		n, err := strconv.ParseInt(r.Guild, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "could not parse guild")
+		}
+		gid = sql.NullInt64{Int64: n, Valid: true}
+	}
+	results := []byte(r.Results)
+	if len(results) == 0 {
+		results = []byte("{}")
+	}
+	dice := r.Dice
+	if dice == nil {
+		dice = []int64{}
+	}
+	var proof interface{} // a roll without a proof stores null
+	if r.Proof != nil {
+		encoded, err := json.Marshal(r.Proof)
+		if err != nil {
+			return errors.Wrap(err, "could not encode proof")
+		}
+		proof = encoded
+	}
+	query := "INSERT INTO rolls (" + rollColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) " +
+		"ON CONFLICT (id) DO UPDATE SET revealed = EXCLUDED.revealed"
+	_, err := rr.db.Conn().ExecContext(ctx, query, r.ID.Int64(), gid, r.Channel, r.Player, idValue(r.Character), string(r.Source),
+		r.System, r.Parameters, string(r.Visibility), r.Description, r.Outcome, pq.Array(dice), results, proof, r.DrawnFrom, r.Revealed, r.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert roll")
+	}
+	return nil
+}
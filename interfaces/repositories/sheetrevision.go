@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The SheetRevisionRepository stores the instructions to set sheet revisions in the database
+type SheetRevisionRepository struct {
+	db Database
+}
+
+// NewSheetRevisionRepository returns a new SheetRevisionRepository instance
+func NewSheetRevisionRepository(db Database) *SheetRevisionRepository {
+	rr := new(SheetRevisionRepository)
+	rr.db = db
+	return rr
+}
+
+// Store saves a sheet revision to the database.
+// If the revision does not yet have an ID (e.g. if it is new) it will create one at this point.
+func (rr *SheetRevisionRepository) Store(ctx context.Context, r *domains.SheetRevision) error {
+	if r.ID == nil {
+		r.ID = rr.db.ID()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	sh, err := json.Marshal(r.Sheet)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal sheet")
+	}
+	query := "INSERT INTO sheet_revisions (id, character, player, reason, sheet, created_at) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err = rr.db.Conn().ExecContext(ctx, query, r.ID.Int64(), r.Character.Int64(), r.Player, r.Reason, sh, r.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "could not insert sheet revision")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The SignedBatchRepository stores the instructions to get and set signed batches from the database
+type SignedBatchRepository struct {
+	db Database
+}
+
+// NewSignedBatchRepository returns a new SignedBatchRepository instance
+func NewSignedBatchRepository(db Database) *SignedBatchRepository {
+	br := new(SignedBatchRepository)
+	br.db = db
+	return br
+}
+
+// FindByID retrieves a signed batch from the database by ID.  A batch which was never kept is nil.
+func (br *SignedBatchRepository) FindByID(ctx context.Context, id string) (*domains.SignedBatch, error) {
+	var b domains.SignedBatch
+	var random []byte
+	query := "SELECT id, random, signature, published FROM signed_batches WHERE id = $1"
+	err := br.db.Conn().QueryRowContext(ctx, query, id).Scan(&b.ID, &random, &b.Signature, &b.Published)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve signed batch from the database")
+	}
+	b.Random = random
+	return &b, nil
+}
+
+// Store saves a signed batch to the database.  A batch which has been published stays published.
+func (br *SignedBatchRepository) Store(ctx context.Context, b *domains.SignedBatch) error {
+	query := "INSERT INTO signed_batches (id, random, signature, published) VALUES ($1, $2, $3, $4) " +
+		"ON CONFLICT (id) DO UPDATE SET published = signed_batches.published OR EXCLUDED.published"
+	_, err := br.db.Conn().ExecContext(ctx, query, b.ID, []byte(b.Random), b.Signature, b.Published)
+	if err != nil {
+		return errors.Wrap(err, "could not upsert signed batch")
+	}
+	return nil
+}
+
+// PublishAll publishes every signed batch, for when the dice which remain in them will never be drawn.
+func (br *SignedBatchRepository) PublishAll(ctx context.Context) error {
+	_, err := br.db.Conn().ExecContext(ctx, "UPDATE signed_batches SET published = TRUE WHERE NOT published")
+	if err != nil {
+		return errors.Wrap(err, "could not publish signed batches")
+	}
+	return nil
+}
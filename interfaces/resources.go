@@ -0,0 +1,136 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/pkg/errors"
+)
+
+// ErrResourceArgs is thrown when a resource is spent or gained without naming it
+var ErrResourceArgs = errors.New("name the resource and the points, such as willpower 1")
+
+// resourceFlags declares the flags accepted by the spend and gain commands
+func resourceFlags(fs *flag.FlagSet) {
+	fs.String("character", "", "the character whose resource changes; your active character by default")
+}
+
+// Spend spends points of a resource of the player's active character
+func (bs *BotServiceHandler) Spend(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	return bs.adjustResource(ctx, msg, "spend", fields, -1)
+}
+
+// Gain gains points of a resource of the player's active character
+func (bs *BotServiceHandler) Gain(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	return bs.adjustResource(ctx, msg, "gain", fields, 1)
+}
+
+// adjustResource spends or gains points of a resource named in the fields, in either order, such
+// as willpower 2 or 2 willpower.  A point is spent or gained if no number is given.
+func (bs *BotServiceHandler) adjustResource(ctx context.Context, msg *discordgo.MessageCreate, name string, fields []string, sign int) (*BotResponse, error) {
+	fs := newFlagSet(name)
+	resourceFlags(fs)
+	args, err := parseInterleaved(fs, fields)
+	if err != nil {
+		return nil, bs.usageError(msg, name, err)
+	}
+	resource := ""
+	points := 1
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err == nil {
+			points = n
+			continue
+		}
+		resource = arg
+	}
+	if resource == "" || len(args) > 2 {
+		return nil, bs.usageError(msg, name, ErrResourceArgs)
+	}
+	char, err := bs.activeCharacter(ctx, msg, fs.Lookup("character").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	revisions := bs.db.Repository("revision").(domains.SheetRevisionRepository)
+	r, err := sheet.Adjust(ctx, bs.db.Repository("character").(domains.CharacterRepository), revisions, char, resource, sign*points, msg.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	verb := "gained"
+	if sign < 0 {
+		verb = "spent"
+	}
+	return &BotResponse{Content: fmt.Sprintf("%s %s %d %s, and has %d of %d left.", char.Name, verb, points, strings.Title(strings.ToLower(resource)), r.Current, r.Max)}, nil
+}
+
+// activeCharacter finds the character the author is playing in the channel, or the named one of
+// their characters
+func (bs *BotServiceHandler) activeCharacter(ctx context.Context, msg *discordgo.MessageCreate, name string) (*domains.Character, error) {
+	campaigns := bs.db.Repository("campaign").(domains.CampaignRepository)
+	chars := bs.db.Repository("character").(domains.CharacterRepository)
+	return campaign.Active(ctx, campaigns, chars, msg.GuildID, msg.ChannelID, msg.Author.ID, name)
+}
+
+// optionalCharacter finds the character the author is playing in the channel, for commands which
+// work without one.  Outside a guild, or without a single character to play, there is none.
+func (bs *BotServiceHandler) optionalCharacter(ctx context.Context, msg *discordgo.MessageCreate) (*domains.Character, error) {
+	if msg.GuildID == "" {
+		return nil, nil
+	}
+	char, err := bs.activeCharacter(ctx, msg, "")
+	if err == sheet.ErrCharacterNotFound || err == sheet.ErrAmbiguousCharacter {
+		return nil, nil
+	}
+	return char, err
+}
+
+// willpowerCharacter finds the named character, or the author's active character, to spend a point
+// of Willpower for a roll, making sure there is a point left to spend before the dice are drawn
+func (bs *BotServiceHandler) willpowerCharacter(ctx context.Context, msg *discordgo.MessageCreate, name string) (*domains.Character, error) {
+	char, err := bs.activeCharacter(ctx, msg, name)
+	if err != nil {
+		return nil, err
+	}
+	wp, err := sheet.Resource(char.Sheet, "willpower")
+	if err != nil {
+		return nil, err
+	}
+	if wp.Current < 1 {
+		return nil, sheet.ErrResourceSpent
+	}
+	return char, nil
+}
+
+// spendWillpower spends a point of Willpower for a roll which has been kept
+func (bs *BotServiceHandler) spendWillpower(ctx context.Context, msg *discordgo.MessageCreate, char *domains.Character) error {
+	revisions := bs.db.Repository("revision").(domains.SheetRevisionRepository)
+	_, err := sheet.Adjust(ctx, bs.db.Repository("character").(domains.CharacterRepository), revisions, char, "willpower", -1, msg.Author.ID)
+	return err
+}
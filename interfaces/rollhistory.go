@@ -0,0 +1,188 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrRollQuery is thrown when the roll history is requested without saying whose
+var ErrRollQuery = errors.New("rolls can only be listed for a channel or a character")
+
+// embedDescriptionLimit is the maximum length of the description of a discord embed
+const embedDescriptionLimit = 4096
+
+// Rolls lists the latest rolls made in the channel
+func (bs *BotServiceHandler) Rolls(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*BotResponse, error) {
+	limit := roll.DefaultHistory
+	switch {
+	case len(fields) == 0:
+	case len(fields) == 2 && fields[0] == "last":
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 {
+			return nil, bs.usageError(msg, "rolls", flag.ErrHelp)
+		}
+		limit = n
+	default:
+		return nil, bs.usageError(msg, "rolls", flag.ErrHelp)
+	}
+	repo := bs.db.Repository("roll").(domains.RollRepository)
+	rolls, err := roll.History(ctx, repo, &domains.RollQuery{Channel: msg.ChannelID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if len(rolls) == 0 {
+		return &BotResponse{Content: "nobody has rolled in this channel yet"}, nil
+	}
+	return &BotResponse{Embeds: []*discordgo.MessageEmbed{historyEmbed(rolls)}}, nil
+}
+
+// historyEmbed lists kept rolls, oldest first, as a discord embed.  The results of hidden rolls
+// are left out, since the list is shown to the whole channel.
+func historyEmbed(rolls []*domains.Roll) *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(rolls))
+	for i := len(rolls) - 1; i >= 0; i-- {
+		r := rolls[i]
+		description := r.Description
+		switch {
+		case roll.Hidden(r) && r.Visibility == domains.RollGM:
+			description = "rolled for the storytellers"
+		case roll.Hidden(r):
+			description = "rolled secretly"
+		}
+		lines = append(lines, fmt.Sprintf("<t:%d:t> `%s` <@%s> %s", r.CreatedAt.Unix(), r.ID, r.Player, description))
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("The last %d rolls", len(rolls)),
+		Description: util.Truncate(strings.Join(lines, "\n"), embedDescriptionLimit),
+	}
+}
+
+// A RollPage is a page of the roll history, and the id to continue the history from
+type RollPage struct {
+	Rolls []*domains.Roll `json:"rolls"`
+	Next  string          `json:"next,omitempty"`
+}
+
+// Rolls lists the rolls made in a channel or for a character, newest first.  The results of hidden
+// rolls are left out for those who may not see them.
+func (ws *WebServiceHandler) Rolls(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	params := req.URL.Query()
+	q := &domains.RollQuery{Channel: params.Get("channel"), Character: params.Get("character"), Before: params.Get("before")}
+	if q.Channel == "" && q.Character == "" {
+		http.Error(res, ErrRollQuery.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit := params.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// find the guild the rolls were made in, and the campaign they belong to
+	var c *domains.Campaign
+	if q.Channel != "" {
+		ch, err := ws.bot.Channel(q.Channel)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q.Guild = ch.GuildID
+		c, err = campaign.ForChannel(req.Context(), ws.db.Repository("campaign").(domains.CampaignRepository), ch.GuildID, ch.ID)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if q.Character != "" {
+		char, err := ws.db.Repository("character").(domains.CharacterRepository).FindByID(req.Context(), q.Character)
+		if err != nil {
+			http.Error(res, sheet.ErrCharacterNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if q.Guild != "" && q.Guild != char.Guild {
+			http.Error(res, ErrRollQuery.Error(), http.StatusBadRequest)
+			return
+		}
+		q.Guild = char.Guild
+		if c == nil && char.Campaign != nil {
+			c, err = ws.db.Repository("campaign").(domains.CampaignRepository).FindByID(req.Context(), char.Campaign.String())
+			if err != nil {
+				http.Error(res, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	role, err := roleOf(req.Context(), ws.bot, ws.db, q.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if role < domains.RolePlayer {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	role = permission.InCampaign(role, uid, c)
+	rolls, err := roll.History(req.Context(), ws.db.Repository("roll").(domains.RollRepository), q)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page := &RollPage{Rolls: rolls}
+	for _, r := range rolls {
+		if !permission.CanReveal(role, uid, r) {
+			roll.Redact(r)
+		}
+	}
+	if len(rolls) == q.Limit {
+		page.Next = rolls[len(rolls)-1].ID.String()
+	}
+	err = json.NewEncoder(res).Encode(page)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/campaign"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/util"
+)
+
+// A WebCampaign is a campaign along with the characters attached to it
+type WebCampaign struct {
+	*domains.Campaign
+	Characters []*domains.Character `json:"characters"`
+}
+
+// A CampaignRequest creates a campaign, or joins one with a character
+type CampaignRequest struct {
+	Guild     string `json:"guild"`
+	Name      string `json:"name"`
+	System    string `json:"system"`
+	Character string `json:"character"`
+}
+
+// Campaigns lists the campaigns of a guild
+func (ws *WebServiceHandler) Campaigns(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	guild := req.URL.Query().Get("guild")
+	role, err := roleOf(req.Context(), ws.bot, ws.db, guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if role < domains.RolePlayer {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	campaigns, err := ws.db.Repository("campaign").(domains.CampaignRepository).FindByGuild(req.Context(), guild)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(res).Encode(campaigns)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Campaign describes a campaign and the characters attached to it
+func (ws *WebServiceHandler) Campaign(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	c, ok := ws.findCampaign(res, req, uid)
+	if !ok {
+		return
+	}
+	chars, err := campaign.Characters(req.Context(), ws.db.Repository("character").(domains.CharacterRepository), c)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(res).Encode(&WebCampaign{c, chars})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CreateCampaign creates a new campaign, run by the storyteller who created it
+func (ws *WebServiceHandler) CreateCampaign(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer req.Body.Close()
+	body, err := util.Decodejson(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var cr CampaignRequest
+	err = json.Unmarshal(body, &cr)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	role, err := roleOf(req.Context(), ws.bot, ws.db, cr.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !permission.Can(role, uid, permission.ManageCampaign, nil) {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	if cr.System == "" {
+		gs, err := config.Get(req.Context(), ws.db.Repository("guild").(domains.GuildSettingsRepository), cr.Guild)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cr.System = gs.SheetSystem
+	}
+	repo := ws.db.Repository("campaign").(domains.CampaignRepository)
+	c, err := campaign.Create(req.Context(), repo, cr.Guild, cr.Name, cr.System, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(res).Encode(c)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// JoinCampaign joins a campaign, optionally attaching one of the player's characters to it
+func (ws *WebServiceHandler) JoinCampaign(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	c, ok := ws.findCampaign(res, req, uid)
+	if !ok {
+		return
+	}
+	defer req.Body.Close()
+	body, err := util.Decodejson(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var cr CampaignRequest
+	err = json.Unmarshal(body, &cr)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repo := ws.db.Repository("campaign").(domains.CampaignRepository)
+	if cr.Character == "" {
+		err = campaign.Join(req.Context(), repo, c, uid)
+	} else {
+		chars := ws.db.Repository("character").(domains.CharacterRepository)
+		var char *domains.Character
+		char, err = chars.FindByID(req.Context(), cr.Character)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// only a character's own player can attach it to a campaign
+		if char.Player != uid {
+			http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+			return
+		}
+		err = campaign.Attach(req.Context(), repo, chars, c, char)
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(res).Encode(c)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// findCampaign finds the campaign named by the route, and checks the user is in its guild.  If the
+// campaign could not be found, the error has already been written.
+func (ws *WebServiceHandler) findCampaign(res http.ResponseWriter, req *http.Request, uid string) (*domains.Campaign, bool) {
+	c, err := ws.db.Repository("campaign").(domains.CampaignRepository).FindByID(req.Context(), chi.URLParam(req, "ID"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	role, err := roleOf(req.Context(), ws.bot, ws.db, c.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if role < domains.RolePlayer {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	return c, true
+}
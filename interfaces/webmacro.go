@@ -0,0 +1,254 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/go-chi/chi"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/macro"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrMacroChannel is thrown when a macro is rolled from the web into a channel outside the character's guild
+var ErrMacroChannel = errors.New("macros can only be rolled in a channel of the character's guild")
+
+// A WebMacro is a macro along with the names of its parameters, so that the sheet page can ask for them
+type WebMacro struct {
+	*domains.Macro
+	Parameters []string `json:"parameters"`
+}
+
+// A MacroRequest saves a macro for a character, or for every character
+type MacroRequest struct {
+	Name  string `json:"name"`
+	Roll  string `json:"roll"`
+	Every bool   `json:"every"` // save the macro for every character rather than this one
+}
+
+// A MacroRoll rolls a macro in a channel, with its parameters as arguments like those of the m command
+type MacroRoll struct {
+	Channel string   `json:"channel"`
+	Args    []string `json:"args"`
+}
+
+// A MacroResult is what the channel was sent when a macro was rolled
+type MacroResult struct {
+	Content string                    `json:"content"`
+	Embeds  []*discordgo.MessageEmbed `json:"embeds"`
+}
+
+// Macros lists the macros the user may roll from a character's sheet
+func (ws *WebServiceHandler) Macros(res http.ResponseWriter, req *http.Request) {
+	uid, char, ok := ws.macroCharacter(res, req)
+	if !ok {
+		return
+	}
+	macros, err := macro.List(req.Context(), ws.db.Repository("macro").(domains.MacroRepository), uid, char.ID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	web := make([]*WebMacro, 0, len(macros))
+	for _, m := range macros {
+		web = append(web, &WebMacro{m, macro.Parameters(m)})
+	}
+	err = json.NewEncoder(res).Encode(web)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SaveMacro saves a macro from a character's sheet
+func (ws *WebServiceHandler) SaveMacro(res http.ResponseWriter, req *http.Request) {
+	uid, char, ok := ws.macroCharacter(res, req)
+	if !ok {
+		return
+	}
+	defer req.Body.Close()
+	body, err := util.Decodejson(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var mr MacroRequest
+	err = json.Unmarshal(body, &mr)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := char.ID
+	if mr.Every {
+		id = nil
+	}
+	m, err := macro.Save(req.Context(), ws.db.Repository("macro").(domains.MacroRepository), uid, id, mr.Name, mr.Roll)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(res).Encode(&WebMacro{m, macro.Parameters(m)})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteMacro deletes a macro from a character's sheet, or one for every character if the
+// character has none by that name
+func (ws *WebServiceHandler) DeleteMacro(res http.ResponseWriter, req *http.Request) {
+	uid, char, ok := ws.macroCharacter(res, req)
+	if !ok {
+		return
+	}
+	repo := ws.db.Repository("macro").(domains.MacroRepository)
+	name := chi.URLParam(req, "Name")
+	m, err := macro.Delete(req.Context(), repo, uid, char.ID, name)
+	if err == macro.ErrMacroNotFound {
+		m, err = macro.Delete(req.Context(), repo, uid, nil, name)
+	}
+	if err == macro.ErrMacroNotFound {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(res).Encode(m)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RollMacro rolls a macro from a character's sheet, as the m command would in the channel
+func (ws *WebServiceHandler) RollMacro(res http.ResponseWriter, req *http.Request) {
+	uid, char, ok := ws.macroCharacter(res, req)
+	if !ok {
+		return
+	}
+	defer req.Body.Close()
+	body, err := util.Decodejson(req.Body)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var mr MacroRoll
+	err = json.Unmarshal(body, &mr)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m, err := macro.Find(req.Context(), ws.db.Repository("macro").(domains.MacroRepository), uid, char.ID, chi.URLParam(req, "Name"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return
+	}
+	fields, err := macro.Expand(m, mr.Args)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ch, err := ws.bot.Channel(mr.Channel)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ch.GuildID == "" || ch.GuildID != char.Guild {
+		http.Error(res, ErrMacroChannel.Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := roleOf(req.Context(), ws.bot, ws.db, ch.GuildID, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if role < domains.RolePlayer {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	// roll as though the user had sent the roll to the channel
+	msg := &discordgo.MessageCreate{Message: &discordgo.Message{
+		ChannelID: ch.ID,
+		GuildID:   ch.GuildID,
+		Author:    &discordgo.User{ID: uid},
+	}}
+	response, err := NewBotServiceHandler(ws.bot, ws.db, ws.rand).Roll(req.Context(), msg, fields)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if response.Content != "" {
+		response.Content = fmt.Sprintf("From the web: <@%s> %s", uid, response.Content)
+		err = ws.bot.SendMessage(ch.ID, response.Content)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, embed := range response.Embeds {
+		embed.Description = fmt.Sprintf("From the web: <@%s> %s", uid, embed.Description)
+		err = ws.bot.SendEmbed(ch.ID, embed)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	err = json.NewEncoder(res).Encode(&MacroResult{response.Content, response.Embeds})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// macroCharacter finds the character whose sheet a macro request was made from, if the user may
+// see it
+func (ws *WebServiceHandler) macroCharacter(res http.ResponseWriter, req *http.Request) (string, *domains.Character, bool) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return "", nil, false
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	uid := strconv.FormatInt(user.ID, 10)
+	char, err := ws.db.Repository("character").(domains.CharacterRepository).FindByID(req.Context(), chi.URLParam(req, "ID"))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusNotFound)
+		return "", nil, false
+	}
+	role, err := roleOf(req.Context(), ws.bot, ws.db, char.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	if !permission.Can(role, uid, permission.ViewSheet, char) {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return "", nil, false
+	}
+	return uid, char, true
+}
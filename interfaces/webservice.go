@@ -27,9 +27,12 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/interfaces/repositories"
+	"github.com/kkragenbrink/slate/usecases/config"
+	"github.com/kkragenbrink/slate/usecases/permission"
 	"github.com/kkragenbrink/slate/usecases/roll"
 	"github.com/kkragenbrink/slate/usecases/sheet"
 	"github.com/kkragenbrink/slate/util"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -102,8 +105,11 @@ func (ws *WebServiceHandler) Channels(res http.ResponseWriter, req *http.Request
 
 // A Roll is a roll command, and is used to determine the system
 type Roll struct {
-	System  string `json:"system"`
-	Channel string `json:"channel"`
+	System    string `json:"system"`
+	Channel   string `json:"channel"`
+	Secret    bool   `json:"secret"`
+	GM        bool   `json:"gm"`
+	Character string `json:"character"` // the character rolled for, if any
 }
 
 // Roll handles the roll usecase from the web.
@@ -131,13 +137,77 @@ func (ws *WebServiceHandler) Roll(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, err.Error(), http.StatusBadRequest)
 		return
 	}
+	// check the guild allows rolls in the channel
+	ch, err := ws.bot.Channel(r.Channel)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	gs, err := config.Get(req.Context(), ws.db.Repository("guild").(domains.GuildSettingsRepository), ch.GuildID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !config.RollAllowed(gs, r.Channel) {
+		http.Error(res, ErrRollChannel.Error(), http.StatusForbidden)
+		return
+	}
+	if r.System == "" {
+		r.System = gs.RollSystem
+	}
 	// get a roller
 	rs, err := roll.NewRoller(r.System, body)
 	if err != nil {
 		http.Error(res, roll.ErrInvalidRollSystem.Error(), http.StatusBadRequest)
 		return
 	}
-	rs.SetRand(ws.rand.Rand)
+	player := strconv.FormatInt(user.ID, 10)
+	visibility := roll.Visibility(r.Secret, r.GM)
+	if visibility != domains.RollPublic && ch.GuildID == "" {
+		http.Error(res, ErrHiddenRollGuild.Error(), http.StatusBadRequest)
+		return
+	}
+	if visibility == domains.RollGM {
+		_, err = hiddenRollRecipients(req.Context(), ws.bot, ws.db, ch.GuildID, r.Channel, player, visibility)
+		if err == ErrNoStorytellers {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	// rolls are only kept for characters of the channel's guild, by their players or storytellers
+	var charID *snowflake.ID
+	if r.Character != "" {
+		char, err := ws.db.Repository("character").(domains.CharacterRepository).FindByID(req.Context(), r.Character)
+		if err != nil {
+			http.Error(res, sheet.ErrCharacterNotFound.Error(), http.StatusBadRequest)
+			return
+		}
+		if char.Guild != ch.GuildID {
+			http.Error(res, sheet.ErrCharacterNotFound.Error(), http.StatusBadRequest)
+			return
+		}
+		role, err := roleOf(req.Context(), ws.bot, ws.db, ch.GuildID, player)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !permission.Can(role, player, permission.EditSheet, char) {
+			http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+			return
+		}
+		charID = char.ID
+	}
+	id := ws.db.ID()
+	rec, err := diceFor(req.Context(), ws.db, ws.rand, r.Channel, id.String(), visibility)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rs.SetRand(rec.Rand)
 
 	// roll
 	err = rs.Roll(req.Context(), nil)
@@ -145,8 +215,34 @@ func (ws *WebServiceHandler) Roll(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	kept, err := roll.Record(rs, r.System, visibility)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	kept.ID = id
+	kept.Proof = rec.Proof()
+	kept.DrawnFrom = rec.DrawnFrom()
+	kept.Guild = ch.GuildID
+	kept.Channel = r.Channel
+	kept.Player = player
+	kept.Source = domains.RollFromWeb
+	kept.Parameters = string(body)
+	kept.Character = charID
+	if visibility != domains.RollPublic {
+		ws.hiddenRoll(res, req, rs, kept)
+		return
+	}
+	err = roll.Keep(req.Context(), ws.db.Repository("roll").(domains.RollRepository), kept)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	// send it to discord
-	err = ws.bot.SendMessage(r.Channel, fmt.Sprintf("From the web: <@%s> %s", strconv.FormatInt(user.ID, 10), rs.ToString()))
+	embed := rollEmbed(rs)
+	embed.Description = fmt.Sprintf("From the web: <@%s> %s", kept.Player, embed.Description)
+	embed.Footer = rollFooter(kept)
+	err = ws.bot.SendEmbed(r.Channel, embed)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 	}
@@ -157,6 +253,28 @@ func (ws *WebServiceHandler) Roll(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// hiddenRoll keeps a secret or gm roll from the web, and tells the channel it was rolled.  The
+// results of a gm roll are not sent back to the roller.
+func (ws *WebServiceHandler) hiddenRoll(res http.ResponseWriter, req *http.Request, rs roll.System, kept *domains.Roll) {
+	notice, err := keepHiddenRoll(req.Context(), ws.bot, ws.db, kept, rollEmbed(rs))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = ws.bot.SendMessage(kept.Channel, fmt.Sprintf("From the web: <@%s> %s", kept.Player, notice))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if kept.Visibility == domains.RollGM {
+		roll.Redact(kept)
+	}
+	err = json.NewEncoder(res).Encode(kept)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // A WebCharacter allows easy inspection of a character before unmarshalling the sheet.
 type WebCharacter struct {
 	Name  string          `json:"name"`
@@ -174,8 +292,25 @@ func (ws *WebServiceHandler) Characters(res http.ResponseWriter, req *http.Reque
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	uid := strconv.FormatInt(user.ID, 10)
 	repo := ws.db.Repository("character").(domains.CharacterRepository)
-	chars, err := repo.FindByPlayer(req.Context(), strconv.FormatInt(user.ID, 10))
+	var chars []*domains.Character
+	if guild := req.URL.Query().Get("guild"); guild != "" {
+		// storytellers can list every character in their guild
+		var role domains.Role
+		role, err = roleOf(req.Context(), ws.bot, ws.db, guild, uid)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !permission.Can(role, uid, permission.ViewGuildSheets, nil) {
+			http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+			return
+		}
+		chars, err = repo.FindByGuild(req.Context(), guild)
+	} else {
+		chars, err = repo.FindByPlayer(req.Context(), uid)
+	}
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
@@ -193,6 +328,10 @@ func (ws *WebServiceHandler) Sheet(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	rawid := chi.URLParam(req, "ID")
 	parsed, err := strconv.ParseInt(rawid, 10, 64)
 	if err != nil {
@@ -206,11 +345,30 @@ func (ws *WebServiceHandler) Sheet(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	uid := strconv.FormatInt(user.ID, 10)
+	action := permission.ViewSheet
 	if req.Method == http.MethodPost {
-		if strconv.FormatInt(user.ID, 10) != char.Player {
-			http.Error(res, err.Error(), http.StatusForbidden)
+		action = permission.EditSheet
+	}
+	role, err := roleOf(req.Context(), ws.bot, ws.db, char.Guild, uid)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if char.Campaign != nil {
+		// the storytellers of a character's campaign may act on it as guild storytellers would
+		c, err := ws.db.Repository("campaign").(domains.CampaignRepository).FindByID(req.Context(), char.Campaign.String())
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		role = permission.InCampaign(role, uid, c)
+	}
+	if !permission.Can(role, uid, action, char) {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	if req.Method == http.MethodPost {
 		defer req.Body.Close()
 		body, err := ioutil.ReadAll(req.Body)
 		if err != nil {
@@ -247,6 +405,69 @@ func (ws *WebServiceHandler) Sheet(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// An Import is the result of importing a character
+type Import struct {
+	Character *domains.Character  `json:"character"`
+	Report    *sheet.ImportReport `json:"report"`
+}
+
+// Import creates a new character from an exported bundle
+func (ws *WebServiceHandler) Import(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := ws.auth.GetAuthorization(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q := req.URL.Query()
+	guild, err := strconv.ParseInt(q.Get("guild"), 10, 64)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// characters can only be imported into a guild the user belongs to
+	role, err := roleOf(req.Context(), ws.bot, ws.db, q.Get("guild"), strconv.FormatInt(user.ID, 10))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if role < domains.RolePlayer {
+		http.Error(res, permission.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxImportSize))
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	repo := ws.db.Repository("character").(domains.CharacterRepository)
+	char, report, err := sheet.Import(req.Context(), repo, body, q.Get("name"), q.Get("system"), guild, user.ID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(res).Encode(&Import{char, report})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Metrics reports the load on the bot's command queues to those who have logged in
+func (ws *WebServiceHandler) Metrics(res http.ResponseWriter, req *http.Request) {
+	if !ws.auth.IsAuthorized(req) {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	err := json.NewEncoder(res).Encode(ws.bot.Stats())
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // Auth describes the interface for authorization of this application
 type Auth interface {
 	BeginAuthorization(http.ResponseWriter, *http.Request)
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/services"
 	"github.com/kkragenbrink/slate/settings"
 )
@@ -36,13 +37,14 @@ func main() {
 	// Create Services
 	db := services.NewDatabaseService(set)
 	rand := services.NewRandom(set)
+	rand.KeepBatches(db.Repository("batch").(domains.SignedBatchRepository))
 	auth := services.NewAuthService(set)
 	bot, err := services.NewBot(set, db, rand)
 	handleError(err, 1)
 	ws := services.NewWebService(set, auth, bot, db, rand)
 
 	// Start services
-	sm := NewServicesManager(db, bot, ws)
+	sm := NewServicesManager(db, rand, bot, ws)
 	sm.Start()
 }
 
@@ -0,0 +1,10 @@
+-- guild_settings holds the per-guild configuration of the bot; empty values use the bot's defaults
+CREATE TABLE IF NOT EXISTS guild_settings (
+    guild         BIGINT PRIMARY KEY,
+    prefix        TEXT    NOT NULL DEFAULT '',
+    roll_system   TEXT    NOT NULL DEFAULT '',
+    sheet_system  TEXT    NOT NULL DEFAULT '',
+    verbose       BOOLEAN NOT NULL DEFAULT FALSE,
+    roll_channels TEXT[]  NOT NULL DEFAULT '{}',
+    locale        TEXT    NOT NULL DEFAULT ''
+);
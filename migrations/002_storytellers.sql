@@ -0,0 +1,2 @@
+-- storytellers holds the discord roles whose members are storytellers in the guild
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS storytellers TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,13 @@
+-- campaigns are games run in a guild, which characters and players can join
+CREATE TABLE IF NOT EXISTS campaigns (
+    id           BIGINT PRIMARY KEY,
+    name         TEXT   NOT NULL,
+    guild        BIGINT NOT NULL,
+    system       TEXT   NOT NULL,
+    storytellers TEXT[] NOT NULL DEFAULT '{}',
+    members      TEXT[] NOT NULL DEFAULT '{}',
+    channels     TEXT[] NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS campaigns_guild ON campaigns (guild);
+
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS campaign BIGINT REFERENCES campaigns (id) ON DELETE SET NULL;
@@ -0,0 +1,15 @@
+-- rolls keeps the rolls which may be revealed or reviewed later, such as secret and gm-only rolls
+CREATE TABLE IF NOT EXISTS rolls (
+    id          BIGINT PRIMARY KEY,
+    guild       BIGINT      NOT NULL,
+    channel     TEXT        NOT NULL,
+    player      TEXT        NOT NULL,
+    system      TEXT        NOT NULL,
+    visibility  TEXT        NOT NULL DEFAULT 'public',
+    description TEXT        NOT NULL DEFAULT '',
+    outcome     INTEGER     NOT NULL DEFAULT 0,
+    results     JSONB       NOT NULL DEFAULT '{}',
+    revealed    BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS rolls_channel ON rolls (channel, created_at);
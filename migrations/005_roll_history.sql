@@ -0,0 +1,7 @@
+-- every roll is kept, along with what made it, so that disputes and session recaps can be checked
+ALTER TABLE rolls ALTER COLUMN guild DROP NOT NULL;
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS character  BIGINT   REFERENCES characters (id) ON DELETE SET NULL;
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS source     TEXT     NOT NULL DEFAULT 'bot';
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS parameters TEXT     NOT NULL DEFAULT '';
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS dice       BIGINT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS rolls_character ON rolls (character, created_at);
@@ -0,0 +1,14 @@
+-- fair_sessions hold the secret seeds which dice are derived from while a fair session runs
+CREATE TABLE IF NOT EXISTS fair_sessions (
+    id         BIGINT PRIMARY KEY,
+    guild      BIGINT,
+    channel    TEXT        NOT NULL,
+    hash       TEXT        NOT NULL,
+    seed       TEXT        NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ended_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS fair_sessions_active ON fair_sessions (channel) WHERE ended_at IS NULL;
+
+-- the proof records how the dice of a roll were drawn, so that the roll can be verified
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS proof JSONB;
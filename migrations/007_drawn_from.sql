@@ -0,0 +1,2 @@
+-- drawn_from records where the dice of a roll came from, such as random.org or crypto/rand when random.org was unavailable
+ALTER TABLE rolls ADD COLUMN IF NOT EXISTS drawn_from TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,23 @@
+-- extended_actions track the successes accumulated over the rolls of chronicles of darkness extended actions
+CREATE TABLE IF NOT EXISTS extended_actions (
+    id               BIGINT PRIMARY KEY,
+    guild            BIGINT,
+    channel          TEXT        NOT NULL,
+    player           TEXT        NOT NULL,
+    name             TEXT        NOT NULL,
+    target           INTEGER     NOT NULL,
+    pool             INTEGER     NOT NULL,
+    skill            INTEGER     NOT NULL DEFAULT 0,
+    roll_limit       INTEGER     NOT NULL,
+    interval_seconds BIGINT      NOT NULL DEFAULT 0,
+    again            INTEGER     NOT NULL DEFAULT 10,
+    rote             BOOLEAN     NOT NULL DEFAULT FALSE,
+    successes        INTEGER     NOT NULL DEFAULT 0,
+    rolls            INTEGER     NOT NULL DEFAULT 0,
+    exceptional      BOOLEAN     NOT NULL DEFAULT FALSE,
+    choosing         BOOLEAN     NOT NULL DEFAULT FALSE,
+    status           TEXT        NOT NULL DEFAULT 'active',
+    started_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS extended_actions_active ON extended_actions (channel, player) WHERE status = 'active';
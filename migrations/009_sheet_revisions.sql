@@ -0,0 +1,10 @@
+-- sheet_revisions keep a copy of a character's sheet whenever the bot changes it, such as when resources are spent
+CREATE TABLE IF NOT EXISTS sheet_revisions (
+    id         BIGINT PRIMARY KEY,
+    character  BIGINT      NOT NULL REFERENCES characters (id) ON DELETE CASCADE,
+    player     TEXT        NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    sheet      JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS sheet_revisions_character ON sheet_revisions (character, created_at);
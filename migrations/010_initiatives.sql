@@ -0,0 +1,15 @@
+-- initiatives hold the turn order of a combat in a channel, and the pinned message which shows it
+CREATE TABLE IF NOT EXISTS initiatives (
+    id         BIGINT PRIMARY KEY,
+    guild      BIGINT,
+    channel    TEXT        NOT NULL,
+    system     TEXT        NOT NULL,
+    message    TEXT        NOT NULL DEFAULT '',
+    round      INTEGER     NOT NULL DEFAULT 0,
+    turn       INTEGER     NOT NULL DEFAULT 0,
+    entries    JSONB       NOT NULL DEFAULT '[]',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ended_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS initiatives_active ON initiatives (channel) WHERE ended_at IS NULL;
@@ -0,0 +1,12 @@
+-- npcs are the quick stat blocks of the non-player characters a campaign's storytellers run
+CREATE TABLE IF NOT EXISTS npcs (
+    id       BIGINT PRIMARY KEY,
+    campaign BIGINT  NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    name     TEXT    NOT NULL,
+    pools    JSONB   NOT NULL DEFAULT '{}',
+    health   INTEGER NOT NULL DEFAULT 0,
+    defense  INTEGER NOT NULL DEFAULT 0,
+    notes    TEXT    NOT NULL DEFAULT '',
+    hidden   BOOLEAN NOT NULL DEFAULT TRUE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS npcs_name ON npcs (campaign, lower(name));
@@ -0,0 +1,10 @@
+-- macros are rolls players save to make again by name, for all of their characters or for one
+CREATE TABLE IF NOT EXISTS macros (
+    id         BIGINT PRIMARY KEY,
+    player     TEXT        NOT NULL,
+    character  BIGINT      REFERENCES characters (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    roll       TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS macros_name ON macros (player, COALESCE(character, 0), lower(name));
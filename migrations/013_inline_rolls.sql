@@ -0,0 +1,2 @@
+-- inline_rolls lets the members of a guild roll [[...]] expressions within ordinary messages
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS inline_rolls BOOLEAN NOT NULL DEFAULT FALSE;
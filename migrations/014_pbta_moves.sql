@@ -0,0 +1,19 @@
+-- moves are the Powered by the Apocalypse moves a campaign's storytellers define, with the text of
+-- each tier of result
+CREATE TABLE IF NOT EXISTS moves (
+    id       BIGINT PRIMARY KEY,
+    campaign BIGINT NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    name     TEXT   NOT NULL,
+    stat     TEXT   NOT NULL DEFAULT '',
+    hit      TEXT   NOT NULL DEFAULT '',
+    partial  TEXT   NOT NULL DEFAULT '',
+    miss     TEXT   NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS moves_name ON moves (campaign, lower(name));
+
+-- roll_modifiers are the forward and ongoing modifiers characters carry into their moves
+CREATE TABLE IF NOT EXISTS roll_modifiers (
+    character BIGINT PRIMARY KEY REFERENCES characters (id) ON DELETE CASCADE,
+    forward   INTEGER NOT NULL DEFAULT 0,
+    ongoing   INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,8 @@
+-- signed_batches hold the batches random.org signed, which are only published once all of their dice are drawn
+CREATE TABLE IF NOT EXISTS signed_batches (
+    id         TEXT PRIMARY KEY,
+    random     JSONB       NOT NULL,
+    signature  TEXT        NOT NULL,
+    published  BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+-- locale was never used, so it is dropped until responses can be translated
+ALTER TABLE guild_settings DROP COLUMN IF EXISTS locale;
@@ -54,7 +54,19 @@ func (dbs *DatabaseService) Conn() *sql.DB {
 
 func (dbs *DatabaseService) initModels() {
 	dbs.repos = make(map[string]interface{})
+	dbs.repos["batch"] = repositories.NewSignedBatchRepository(dbs)
+	dbs.repos["campaign"] = repositories.NewCampaignRepository(dbs)
 	dbs.repos["character"] = repositories.NewCharacterRepository(dbs)
+	dbs.repos["extended"] = repositories.NewExtendedActionRepository(dbs)
+	dbs.repos["fair"] = repositories.NewFairSessionRepository(dbs)
+	dbs.repos["guild"] = repositories.NewGuildSettingsRepository(dbs)
+	dbs.repos["initiative"] = repositories.NewInitiativeRepository(dbs)
+	dbs.repos["macro"] = repositories.NewMacroRepository(dbs)
+	dbs.repos["modifiers"] = repositories.NewRollModifiersRepository(dbs)
+	dbs.repos["move"] = repositories.NewMoveRepository(dbs)
+	dbs.repos["npc"] = repositories.NewNPCRepository(dbs)
+	dbs.repos["roll"] = repositories.NewRollRepository(dbs)
+	dbs.repos["revision"] = repositories.NewSheetRevisionRepository(dbs)
 }
 
 // Repository retrieves a specific repository by name
@@ -23,27 +23,49 @@ package services
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/interfaces"
 	"github.com/kkragenbrink/slate/settings"
+	"github.com/kkragenbrink/slate/usecases/permission"
+	"github.com/kkragenbrink/slate/util"
 	"github.com/pkg/errors"
 )
 
 var errDuplicateHandler = errors.New("duplicate handler already exists")
 
+// busyMessage is the reply to a command which could not be queued
+const busyMessage = "I'm too busy to handle that right now; please try again in a moment."
+
 // A DiscordSession contains instructions for communicating with discord.
 type DiscordSession interface {
 	AddHandler(handler interface{}) func()
-	Channel(string) (*discordgo.Channel, error)
-	ChannelMessageSend(string, string) (*discordgo.Message, error)
+	ApplicationCommandBulkOverwrite(string, string, []*discordgo.ApplicationCommand, ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+	Channel(string, ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageEditEmbed(string, string, *discordgo.MessageEmbed, ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessagePin(string, string, ...discordgo.RequestOption) error
+	ChannelMessageSend(string, string, ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(string, *discordgo.MessageSend, ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(string, *discordgo.MessageEmbed, ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageUnpin(string, string, ...discordgo.RequestOption) error
 	Close() error
-	GuildChannels(string) ([]*discordgo.Channel, error)
+	FollowupMessageCreate(*discordgo.Interaction, bool, *discordgo.WebhookParams, ...discordgo.RequestOption) (*discordgo.Message, error)
+	Guild(string, ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildChannels(string, ...discordgo.RequestOption) ([]*discordgo.Channel, error)
+	GuildMember(string, string, ...discordgo.RequestOption) (*discordgo.Member, error)
+	GuildMembers(string, string, int, ...discordgo.RequestOption) ([]*discordgo.Member, error)
+	InteractionRespond(*discordgo.Interaction, *discordgo.InteractionResponse, ...discordgo.RequestOption) error
+	InteractionResponseDelete(*discordgo.Interaction, ...discordgo.RequestOption) error
+	InteractionResponseEdit(*discordgo.Interaction, *discordgo.WebhookEdit, ...discordgo.RequestOption) (*discordgo.Message, error)
+	MessageReactionAdd(string, string, string, ...discordgo.RequestOption) error
 	Open() error
-	User(string) (*discordgo.User, error)
+	User(string, ...discordgo.RequestOption) (*discordgo.User, error)
+	UserChannelCreate(string, ...discordgo.RequestOption) (*discordgo.Channel, error)
 }
 
 // The Bot contains the connection to discord as well as the message handlers.
@@ -52,14 +74,19 @@ type Bot struct {
 	settings   *settings.Settings
 	handlers   []*BotMessageHandler
 	svchandler *interfaces.BotServiceHandler
+	inline     interfaces.BotResponseHandler // rolls the inline rolls of ordinary messages
+	db         *DatabaseService
 	session    DiscordSession
-	mutex      *sync.Mutex
+	dispatcher *Dispatcher
+	mutex      sync.RWMutex // guards handlers
 }
 
 // A BotMessageHandler is a command name and a handler function
 type BotMessageHandler struct {
 	command string
-	handle  interfaces.BotHandler
+	handle  interfaces.BotResponseHandler
+	spec    *interfaces.BotCommand
+	silent  bool // errors are only logged, for messages which were not commands
 }
 
 // NewBot returns a new Discord bot, which will be used by the application for
@@ -71,36 +98,60 @@ func NewBot(set *settings.Settings, db *DatabaseService, rand *RandomService) (*
 	if err != nil {
 		return nil, errors.Wrap(err, "could not establish discord session")
 	}
+	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages |
+		discordgo.IntentsDirectMessages | discordgo.IntentMessageContent
 
 	bot := new(Bot)
 	bot.logger = NewSlateLogger()
 	bot.initServiceHandler(db, rand)
+	bot.db = db
 	bot.settings = set
 	bot.session = session
-	bot.mutex = &sync.Mutex{}
+	bot.dispatcher = NewDispatcher(set.DispatchWorkers, set.DispatchQueueSize)
 
 	return bot, nil
 }
 
 func (bot *Bot) initServiceHandler(db *DatabaseService, rand *RandomService) {
 	bs := interfaces.NewBotServiceHandler(bot, db, rand)
-	bot.AddHandler("sheet", bs.Sheet)
-	bot.AddHandler("roll", bs.Roll)
+	for _, cmd := range bs.Commands() {
+		bot.AddCommand(cmd)
+	}
 	bot.svchandler = bs
+	bot.inline = bs.InlineRolls
 }
 
-// AddHandler adds a new BotHandler to the bot.
-func (bot *Bot) AddHandler(command string, handle interfaces.BotHandler) error {
-	if bot.hasHandler(command) {
+// AddCommand adds a new BotCommand to the bot.
+func (bot *Bot) AddCommand(cmd *interfaces.BotCommand) error {
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
+	if bot.findHandler(cmd.Name) != nil {
 		return errDuplicateHandler
 	}
 	handler := new(BotMessageHandler)
-	handler.command = command
-	handler.handle = handle
+	handler.command = cmd.Name
+	handler.handle = cmd.Handle
+	handler.spec = cmd
 	bot.handlers = append(bot.handlers, handler)
 	return nil
 }
 
+// AddHandler adds a new BotHandler to the bot.
+func (bot *Bot) AddHandler(command string, handle interfaces.BotHandler) error {
+	return bot.AddResponseHandler(command, func(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*interfaces.BotResponse, error) {
+		results, err := handle(ctx, msg, fields)
+		if err != nil {
+			return nil, err
+		}
+		return &interfaces.BotResponse{Content: results}, nil
+	})
+}
+
+// AddResponseHandler adds a new BotResponseHandler to the bot.
+func (bot *Bot) AddResponseHandler(command string, handle interfaces.BotResponseHandler) error {
+	return bot.AddCommand(&interfaces.BotCommand{Name: command, Handle: handle})
+}
+
 // Channel gets a channel object by ID
 func (bot *Bot) Channel(id string) (*discordgo.Channel, error) {
 	ch, err := bot.session.Channel(id)
@@ -126,6 +177,112 @@ func (bot *Bot) Channels(id string) ([]*discordgo.Channel, error) {
 	return channels, nil
 }
 
+// Member describes a user's standing in a guild.  Users who are not in the guild have none.
+func (bot *Bot) Member(guild, user string) (*permission.Member, error) {
+	g, err := bot.session.Guild(guild)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find guild by id")
+	}
+	m, err := bot.session.GuildMember(guild, user)
+	if rerr, ok := err.(*discordgo.RESTError); ok && rerr.Response != nil && rerr.Response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find member by id")
+	}
+	member := &permission.Member{ID: user, Roles: m.Roles, Admin: g.OwnerID == user}
+	for _, role := range g.Roles {
+		if role.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) == 0 {
+			continue
+		}
+		// every member has the @everyone role, which shares the guild's id
+		if role.ID == guild || util.ContainsString(m.Roles, role.ID) {
+			member.Admin = true
+		}
+	}
+	return member, nil
+}
+
+// membersPage is the most members discord lists at once
+const membersPage = 1000
+
+// RoleMembers lists the users of a guild who hold any of the given roles.  Listing members needs
+// the bot's server members intent to be enabled.
+func (bot *Bot) RoleMembers(guild string, roles []string) ([]string, error) {
+	users := make([]string, 0)
+	if len(roles) == 0 {
+		return users, nil
+	}
+	after := ""
+	for {
+		members, err := bot.session.GuildMembers(guild, after, membersPage)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list guild members")
+		}
+		for _, m := range members {
+			after = m.User.ID
+			for _, role := range m.Roles {
+				if util.ContainsString(roles, role) {
+					users = append(users, m.User.ID)
+					break
+				}
+			}
+		}
+		if len(members) < membersPage {
+			return users, nil
+		}
+	}
+}
+
+// SendEmbed sends an embed to a specified channel
+func (bot *Bot) SendEmbed(id string, embed *discordgo.MessageEmbed) error {
+	_, err := bot.session.ChannelMessageSendEmbed(id, embed)
+	return err
+}
+
+// SendPinnedEmbed sends an embed to a specified channel and pins it, returning the ID of the
+// message.  The message is still sent if the bot may not pin messages in the channel.
+func (bot *Bot) SendPinnedEmbed(id string, embed *discordgo.MessageEmbed) (string, error) {
+	msg, err := bot.session.ChannelMessageSendEmbed(id, embed)
+	if err != nil {
+		return "", err
+	}
+	err = bot.session.ChannelMessagePin(id, msg.ID)
+	if err != nil {
+		bot.logger.Logger.Printf("could not pin message %s in %s: %s", msg.ID, id, err)
+	}
+	return msg.ID, nil
+}
+
+// EditEmbed replaces the embed of a message the bot sent
+func (bot *Bot) EditEmbed(channel, message string, embed *discordgo.MessageEmbed) error {
+	_, err := bot.session.ChannelMessageEditEmbed(channel, message, embed)
+	return err
+}
+
+// Unpin unpins a message
+func (bot *Bot) Unpin(channel, message string) error {
+	return bot.session.ChannelMessageUnpin(channel, message)
+}
+
+// SendDirectEmbed sends an embed to a user's direct messages
+func (bot *Bot) SendDirectEmbed(user string, embed *discordgo.MessageEmbed) error {
+	ch, err := bot.session.UserChannelCreate(user)
+	if err != nil {
+		return errors.Wrap(err, "could not open direct message channel")
+	}
+	_, err = bot.session.ChannelMessageSendEmbed(ch.ID, embed)
+	return err
+}
+
+// Stats reports the load on the bot's command queues
+func (bot *Bot) Stats() *interfaces.BotStats {
+	if bot.dispatcher == nil {
+		return new(interfaces.BotStats)
+	}
+	return bot.dispatcher.Stats()
+}
+
 // SendMessage sends a message to a specified channel
 func (bot *Bot) SendMessage(id string, message string) error {
 	_, err := bot.session.ChannelMessageSend(id, message)
@@ -142,12 +299,23 @@ func (bot *Bot) User(id string) (*discordgo.User, error) {
 }
 
 func (bot *Bot) hasHandler(command string) bool {
+	return bot.handler(command) != nil
+}
+
+func (bot *Bot) handler(command string) *BotMessageHandler {
+	bot.mutex.RLock()
+	defer bot.mutex.RUnlock()
+	return bot.findHandler(command)
+}
+
+// findHandler finds a handler by command; the caller must hold the mutex
+func (bot *Bot) findHandler(command string) *BotMessageHandler {
 	for _, handler := range bot.handlers {
 		if handler.command == command {
-			return true
+			return handler
 		}
 	}
-	return false
+	return nil
 }
 
 func (bot *Bot) handleMessageCreateInterface(session *discordgo.Session, msg *discordgo.MessageCreate) {
@@ -156,47 +324,131 @@ func (bot *Bot) handleMessageCreateInterface(session *discordgo.Session, msg *di
 
 func (bot *Bot) handleMessageCreate(session DiscordSession, msg *discordgo.MessageCreate) {
 	start := time.Now()
-	if !strings.HasPrefix(msg.Content, bot.settings.CommandPrefix) {
+	prefix := bot.prefix(msg.GuildID)
+	if !strings.HasPrefix(msg.Content, prefix) {
+		bot.handleInlineRolls(msg, start)
 		return // this is not our command to handle
 	}
 
-	cmd := strings.Trim(msg.Content[len(bot.settings.CommandPrefix):], " ")
+	cmd := strings.Trim(msg.Content[len(prefix):], " ")
+	if cmd == "" {
+		return // nor is this
+	}
 	name := strings.Fields(cmd)[0]
 	fields := strings.Fields(cmd)[1:]
 
-	if !bot.hasHandler(name) {
+	handler := bot.handler(name)
+	if handler == nil {
 		return // still not our command to handle
 	}
 	log := bot.logger.NewDiscordLogEntry(msg, name, fields)
 
-	// todo: this should be a setting instead of a magic number
-	timeout, err := time.ParseDuration("5s")
+	err := bot.dispatcher.Dispatch(msg.ChannelID, func() {
+		bot.runMessageHandler(handler, msg, fields, log, start)
+	})
 	if err != nil {
-		bot.session.ChannelMessageSend(msg.ChannelID, "Woops, Something went wrong!")
-		log.Write(LogError, 0, time.Since(start))
+		response := fmt.Sprintf("%s %s", msg.Author.Mention(), busyMessage)
+		bot.session.ChannelMessageSend(msg.ChannelID, response)
+		log.Write(LogWarn, len(response), time.Since(start))
+	}
+}
+
+// handleInlineRolls queues the inline rolls of an ordinary message, such as [[1d20+5]].  Whether
+// the guild allows them is left to the handler, and no one is told if the queue is full or the rolls
+// fail, since the message was not a command.
+func (bot *Bot) handleInlineRolls(msg *discordgo.MessageCreate, start time.Time) {
+	if bot.inline == nil || msg.Author == nil || msg.Author.Bot || !interfaces.HasInlineRolls(msg.Content) {
 		return
 	}
-	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	handler := &BotMessageHandler{command: "inline", handle: bot.inline, silent: true}
+	log := bot.logger.NewDiscordLogEntry(msg, handler.command, nil)
+	err := bot.dispatcher.Dispatch(msg.ChannelID, func() {
+		bot.runMessageHandler(handler, msg, nil, log, start)
+	})
+	if err != nil {
+		log.Write(LogWarn, 0, time.Since(start))
+	}
+}
+
+// prefix finds the command prefix for a guild
+func (bot *Bot) prefix(guild string) string {
+	if guild == "" || bot.db == nil {
+		return bot.settings.CommandPrefix
+	}
+	repo, ok := bot.db.Repository("guild").(domains.GuildSettingsRepository)
+	if !ok {
+		return bot.settings.CommandPrefix
+	}
+	// todo: this should be a setting instead of a magic number
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second)
 	defer cancelFunc()
+	s, err := repo.FindByGuild(ctx, guild)
+	if err != nil || s.Prefix == "" {
+		return bot.settings.CommandPrefix
+	}
+	return s.Prefix
+}
 
-	bot.mutex.Lock()
-	defer bot.mutex.Unlock()
-	for _, handler := range bot.handlers {
-		if handler.command == name {
-			// handle it
-			results, err := handler.handle(ctx, msg, fields)
-			if err != nil {
-				response := fmt.Sprintf("%s %s", msg.Author.Mention(), err.Error())
-				bot.session.ChannelMessageSend(msg.ChannelID, response)
-				log.Write(LogWarn, len(response), time.Since(start))
-			} else {
-				response := fmt.Sprintf("%s %s", msg.Author.Mention(), results)
-				bot.session.ChannelMessageSend(msg.ChannelID, response)
-				log.Write(LogInfo, len(response), time.Since(start))
-			}
-			return
+// runMessageHandler runs a command on one of the dispatcher's workers and sends the response
+func (bot *Bot) runMessageHandler(handler *BotMessageHandler, msg *discordgo.MessageCreate, fields []string, log LogEntry, start time.Time) {
+	// todo: this should be a setting instead of a magic number
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc()
+
+	results, err := handler.handle(ctx, msg, fields)
+	if err != nil && handler.silent {
+		log.Write(LogWarn, 0, time.Since(start))
+		return
+	}
+	if err != nil {
+		response := fmt.Sprintf("%s %s", msg.Author.Mention(), err.Error())
+		bot.session.ChannelMessageSend(msg.ChannelID, response)
+		log.Write(LogWarn, len(response), time.Since(start))
+		return
+	}
+	size, err := bot.sendResponse(msg, results)
+	if err != nil {
+		log.Write(LogError, size, time.Since(start))
+		return
+	}
+	log.Write(LogInfo, size, time.Since(start))
+}
+
+// sendResponse sends a handler's response to the channel (or the author) and returns the size of
+// the content sent.
+func (bot *Bot) sendResponse(msg *discordgo.MessageCreate, res *interfaces.BotResponse) (int, error) {
+	for _, reaction := range res.Reactions {
+		err := bot.session.MessageReactionAdd(msg.ChannelID, msg.ID, reaction)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not add reaction")
+		}
+	}
+	if res.Content == "" && len(res.Embeds) == 0 && len(res.Files) == 0 {
+		return 0, nil // nothing to send
+	}
+
+	channel := msg.ChannelID
+	content := res.Content
+	if res.Direct || res.Ephemeral {
+		ch, err := bot.session.UserChannelCreate(msg.Author.ID)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not open direct message channel")
 		}
+		channel = ch.ID
+	} else {
+		content = strings.TrimSpace(fmt.Sprintf("%s %s", msg.Author.Mention(), content))
+	}
+
+	if len(res.Embeds) == 0 && len(res.Files) == 0 && !res.Quiet {
+		_, err := bot.session.ChannelMessageSend(channel, content)
+		return len(content), err
+	}
+	send := &discordgo.MessageSend{Content: content, Embeds: res.Embeds, Files: res.Files}
+	if res.Quiet {
+		send.AllowedMentions = &discordgo.MessageAllowedMentions{Users: []string{msg.Author.ID}}
 	}
+	_, err := bot.session.ChannelMessageSendComplex(channel, send)
+	return len(content), err
 }
 
 // Start establishes the connection to discord and adds the message handler.
@@ -206,15 +458,20 @@ func (bot *Bot) Start() error {
 		return errors.Wrap(err, "could not connect to discord")
 	}
 	bot.session.AddHandler(bot.handleMessageCreateInterface)
+	bot.session.AddHandler(bot.handleInteractionCreateInterface)
+	bot.session.AddHandler(bot.handleReadyInterface)
 
 	return nil
 }
 
-// Stop closes the connection to discord.
+// Stop closes the connection to discord, and waits for queued commands to finish.
 func (bot *Bot) Stop() error {
 	err := bot.session.Close()
 	if err != nil {
 		return errors.Wrap(err, "error disconnecting from discord")
 	}
+	if bot.dispatcher != nil {
+		bot.dispatcher.Stop()
+	}
 	return nil
 }
@@ -22,10 +22,14 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/interfaces"
 	"github.com/kkragenbrink/slate/services/mocks"
 	"github.com/kkragenbrink/slate/settings"
@@ -95,6 +99,203 @@ func (suite *BotSuite) TestHandleMessageCreate() {
 	session.EXPECT().ChannelMessageSend(gomock.Eq(channel), gomock.Eq("<@a1> test"))
 	bot.session = session
 	bot.handleMessageCreate(session, message)
+	bot.dispatcher.Stop()
+}
+
+func (suite *BotSuite) TestHandleMessageCreateGuildPrefix() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	guilds := domains.NewMockGuildSettingsRepository(ctrl)
+	guilds.EXPECT().FindByGuild(gomock.Any(), "g1").Return(&domains.GuildSettings{Guild: "g1", Prefix: "!"}, nil).Times(2)
+	db := new(DatabaseService)
+	db.repos = map[string]interface{}{"guild": guilds}
+	bot, _ := NewBot(set, db, NewRandom(set))
+	session := mocks.NewMockDiscordSession(ctrl)
+	bot.AddHandler("test", genMockHandler("test"))
+	session.EXPECT().ChannelMessageSend(gomock.Eq("c1"), gomock.Eq("<@a1> test"))
+	bot.session = session
+	ignored := genMockMessage("a1", "c1", "$test")
+	ignored.GuildID = "g1"
+	bot.handleMessageCreate(session, ignored)
+	message := genMockMessage("a1", "c1", "!test")
+	message.GuildID = "g1"
+	bot.handleMessageCreate(session, message)
+	bot.dispatcher.Stop()
+}
+
+func (suite *BotSuite) TestMember() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	session := mocks.NewMockDiscordSession(ctrl)
+	guild := &discordgo.Guild{ID: "g1", OwnerID: "o1", Roles: []*discordgo.Role{
+		{ID: "g1", Permissions: discordgo.PermissionSendMessages},
+		{ID: "r1", Permissions: discordgo.PermissionManageServer},
+		{ID: "r2", Permissions: discordgo.PermissionSendMessages},
+	}}
+	session.EXPECT().Guild(gomock.Eq("g1")).Return(guild, nil).Times(3)
+	session.EXPECT().GuildMember(gomock.Eq("g1"), gomock.Eq("a1")).Return(&discordgo.Member{Roles: []string{"r1"}}, nil)
+	session.EXPECT().GuildMember(gomock.Eq("g1"), gomock.Eq("a2")).Return(&discordgo.Member{Roles: []string{"r2"}}, nil)
+	session.EXPECT().GuildMember(gomock.Eq("g1"), gomock.Eq("a3")).Return(nil, &discordgo.RESTError{Response: &http.Response{StatusCode: http.StatusNotFound}})
+	bot.session = session
+	member, err := bot.Member("g1", "a1")
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), member.Admin)
+	member, err = bot.Member("g1", "a2")
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), member.Admin)
+	assert.Equal(suite.T(), []string{"r2"}, member.Roles)
+	member, err = bot.Member("g1", "a3")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), member)
+}
+
+func (suite *BotSuite) TestRoleMembers() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	session := mocks.NewMockDiscordSession(ctrl)
+	page := make([]*discordgo.Member, membersPage)
+	for i := range page {
+		page[i] = &discordgo.Member{User: &discordgo.User{ID: fmt.Sprintf("u%d", i)}}
+	}
+	page[0].Roles = []string{"r2", "r1"}
+	session.EXPECT().GuildMembers(gomock.Eq("g1"), gomock.Eq(""), gomock.Eq(membersPage)).Return(page, nil)
+	session.EXPECT().GuildMembers(gomock.Eq("g1"), gomock.Eq(fmt.Sprintf("u%d", membersPage-1)), gomock.Eq(membersPage)).Return([]*discordgo.Member{
+		{User: &discordgo.User{ID: "a1"}, Roles: []string{"r3"}},
+		{User: &discordgo.User{ID: "a2"}, Roles: []string{"r1"}},
+	}, nil)
+	bot.session = session
+	users, err := bot.RoleMembers("g1", []string{"r1"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"u0", "a2"}, users)
+	users, err = bot.RoleMembers("g1", nil)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), users)
+}
+
+func (suite *BotSuite) TestSendEmbed() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	embed := &discordgo.MessageEmbed{Title: "test"}
+	session := mocks.NewMockDiscordSession(ctrl)
+	session.EXPECT().ChannelMessageSendEmbed(gomock.Eq("c1"), gomock.Eq(embed))
+	bot.session = session
+	err := bot.SendEmbed("c1", embed)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *BotSuite) TestSendPinnedEmbed() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	bot.logger = NewSlateLogger()
+	embed := &discordgo.MessageEmbed{Title: "test"}
+	session := mocks.NewMockDiscordSession(ctrl)
+	session.EXPECT().ChannelMessageSendEmbed(gomock.Eq("c1"), gomock.Eq(embed)).Return(&discordgo.Message{ID: "m1"}, nil)
+	session.EXPECT().ChannelMessagePin(gomock.Eq("c1"), gomock.Eq("m1")).Return(errors.New("missing permissions"))
+	bot.session = session
+	id, err := bot.SendPinnedEmbed("c1", embed)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "m1", id)
+}
+
+func (suite *BotSuite) TestSendDirectEmbed() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	embed := &discordgo.MessageEmbed{Title: "test"}
+	session := mocks.NewMockDiscordSession(ctrl)
+	session.EXPECT().UserChannelCreate(gomock.Eq("u1")).Return(&discordgo.Channel{ID: "d1"}, nil)
+	session.EXPECT().ChannelMessageSendEmbed(gomock.Eq("d1"), gomock.Eq(embed))
+	bot.session = session
+	err := bot.SendDirectEmbed("u1", embed)
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *BotSuite) TestHandleMessageCreateResponse() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	rand := NewRandom(set)
+	bot, _ := NewBot(set, suite.mockdb, rand)
+	session := mocks.NewMockDiscordSession(ctrl)
+	message := genMockMessage("a1", "c1", "$test")
+	message.ID = "m1"
+	embeds := []*discordgo.MessageEmbed{{Title: "one"}, {Title: "two"}}
+	bot.AddResponseHandler("test", func(ctx context.Context, msg *discordgo.MessageCreate, s []string) (*interfaces.BotResponse, error) {
+		return &interfaces.BotResponse{Content: "test", Embeds: embeds, Reactions: []string{"🎉"}}, nil
+	})
+	session.EXPECT().MessageReactionAdd(gomock.Eq("c1"), gomock.Eq("m1"), gomock.Eq("🎉"))
+	session.EXPECT().ChannelMessageSendComplex(gomock.Eq("c1"), gomock.Eq(&discordgo.MessageSend{Content: "<@a1> test", Embeds: embeds}))
+	bot.session = session
+	bot.handleMessageCreate(session, message)
+	bot.dispatcher.Stop()
+}
+
+func (suite *BotSuite) TestHandleMessageCreateDirect() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	rand := NewRandom(set)
+	bot, _ := NewBot(set, suite.mockdb, rand)
+	session := mocks.NewMockDiscordSession(ctrl)
+	message := genMockMessage("a1", "c1", "$test")
+	bot.AddResponseHandler("test", func(ctx context.Context, msg *discordgo.MessageCreate, s []string) (*interfaces.BotResponse, error) {
+		return &interfaces.BotResponse{Content: "secret", Direct: true}, nil
+	})
+	session.EXPECT().UserChannelCreate(gomock.Eq("a1")).Return(&discordgo.Channel{ID: "dm1"}, nil)
+	session.EXPECT().ChannelMessageSend(gomock.Eq("dm1"), gomock.Eq("secret"))
+	bot.session = session
+	bot.handleMessageCreate(session, message)
+	bot.dispatcher.Stop()
+}
+
+func (suite *BotSuite) TestHandleMessageCreateInline() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, suite.mockdb, NewRandom(set))
+	session := mocks.NewMockDiscordSession(ctrl)
+	calls := 0
+	bot.inline = func(ctx context.Context, msg *discordgo.MessageCreate, s []string) (*interfaces.BotResponse, error) {
+		calls++
+		return &interfaces.BotResponse{Content: "I swing **17**"}, nil
+	}
+	session.EXPECT().ChannelMessageSend(gomock.Eq("c1"), gomock.Eq("<@a1> I swing **17**"))
+	bot.session = session
+	bot.handleMessageCreate(session, genMockMessage("a1", "c1", "I swing [[1d20+5]]"))
+	bot.handleMessageCreate(session, genMockMessage("a1", "c1", "I swing [sword]"))
+	fromBot := genMockMessage("b1", "c1", "I swing [[1d20+5]]")
+	fromBot.Author.Bot = true
+	bot.handleMessageCreate(session, fromBot)
+	bot.dispatcher.Stop()
+	assert.Equal(suite.T(), 1, calls)
+}
+
+func (suite *BotSuite) TestHandleMessageCreateInlineQuiet() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, suite.mockdb, NewRandom(set))
+	session := mocks.NewMockDiscordSession(ctrl)
+	bot.inline = func(ctx context.Context, msg *discordgo.MessageCreate, s []string) (*interfaces.BotResponse, error) {
+		if strings.Contains(msg.Content, "wiki") {
+			return nil, errSampleError
+		}
+		return &interfaces.BotResponse{Content: "@everyone I swing **17**", Quiet: true}, nil
+	}
+	// only the author may be mentioned by what they wrote, and failures go unanswered
+	session.EXPECT().ChannelMessageSendComplex(gomock.Eq("c1"), gomock.Eq(&discordgo.MessageSend{
+		Content:         "<@a1> @everyone I swing **17**",
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{"a1"}},
+	}))
+	bot.session = session
+	bot.handleMessageCreate(session, genMockMessage("a1", "c1", "@everyone I swing [[1d20+5]]"))
+	bot.handleMessageCreate(session, genMockMessage("a1", "c1", "see [[wiki link]]"))
+	bot.dispatcher.Stop()
 }
 
 func (suite *BotSuite) TestStart() {
@@ -103,7 +304,7 @@ func (suite *BotSuite) TestStart() {
 	bot := new(Bot)
 	session := mocks.NewMockDiscordSession(ctrl)
 	session.EXPECT().Open()
-	session.EXPECT().AddHandler(gomock.Any())
+	session.EXPECT().AddHandler(gomock.Any()).Times(3)
 	bot.session = session
 	bot.Start()
 }
@@ -0,0 +1,287 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/kkragenbrink/slate/interfaces"
+	"github.com/kkragenbrink/slate/util"
+)
+
+const (
+	// argsOption is the name of the slash command option holding a command's positional arguments
+	argsOption = "args"
+
+	// maxChoices is the most autocomplete choices discord will accept
+	maxChoices = 25
+
+	// descriptionLimit is the longest description of a command or option discord will accept
+	descriptionLimit = 100
+)
+
+func (bot *Bot) handleReadyInterface(session *discordgo.Session, ready *discordgo.Ready) {
+	bot.handleReady(session, ready)
+}
+
+// handleReady registers the bot's commands as application (slash) commands once connected.
+func (bot *Bot) handleReady(session DiscordSession, ready *discordgo.Ready) {
+	if ready.Application == nil {
+		return
+	}
+	_, err := bot.session.ApplicationCommandBulkOverwrite(ready.Application.ID, "", bot.applicationCommands())
+	if err != nil {
+		bot.logger.Logger.Printf("could not register application commands: %s", err)
+	}
+}
+
+// applicationCommands converts the bot's commands into application commands, with typed options
+// derived from each command's flags.
+func (bot *Bot) applicationCommands() []*discordgo.ApplicationCommand {
+	bot.mutex.RLock()
+	defer bot.mutex.RUnlock()
+	commands := make([]*discordgo.ApplicationCommand, 0, len(bot.handlers))
+	for _, handler := range bot.handlers {
+		spec := handler.spec
+		command := &discordgo.ApplicationCommand{
+			Name:        spec.Name,
+			Description: util.Truncate(spec.Description, descriptionLimit),
+		}
+		if command.Description == "" {
+			command.Description = fmt.Sprintf("the %s command", spec.Name)
+		}
+		if spec.Flags != nil {
+			fs := flag.NewFlagSet(spec.Name, flag.ContinueOnError)
+			spec.Flags(fs)
+			fs.VisitAll(func(f *flag.Flag) {
+				command.Options = append(command.Options, &discordgo.ApplicationCommandOption{
+					Type:         optionType(f),
+					Name:         f.Name,
+					Description:  util.Truncate(f.Usage, descriptionLimit),
+					Autocomplete: spec.Complete[f.Name] != nil,
+				})
+			})
+		}
+		if spec.Args != "" {
+			command.Options = append(command.Options, &discordgo.ApplicationCommandOption{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         argsOption,
+				Description:  util.Truncate(spec.Args, descriptionLimit),
+				Autocomplete: spec.Complete[argsOption] != nil,
+			})
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// optionType determines the type of application command option for a flag
+func optionType(f *flag.Flag) discordgo.ApplicationCommandOptionType {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return discordgo.ApplicationCommandOptionString
+	}
+	switch getter.Get().(type) {
+	case bool:
+		return discordgo.ApplicationCommandOptionBoolean
+	case int, int64, uint, uint64:
+		return discordgo.ApplicationCommandOptionInteger
+	case float64:
+		return discordgo.ApplicationCommandOptionNumber
+	}
+	return discordgo.ApplicationCommandOptionString
+}
+
+func (bot *Bot) handleInteractionCreateInterface(session *discordgo.Session, i *discordgo.InteractionCreate) {
+	bot.handleInteractionCreate(session, i)
+}
+
+// handleInteractionCreate routes application command interactions to the same handlers used for
+// messages.
+func (bot *Bot) handleInteractionCreate(session DiscordSession, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return // not a command
+	}
+	data := i.ApplicationCommandData()
+	handler := bot.handler(data.Name)
+	if handler == nil {
+		return // not our command to handle
+	}
+	msg, fields := interactionMessage(i)
+
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		bot.dispatcher.Dispatch(i.ChannelID, func() {
+			bot.handleAutocomplete(i, msg, handler.spec)
+		})
+		return
+	}
+
+	start := time.Now()
+	log := bot.logger.NewDiscordLogEntry(msg, data.Name, fields)
+	// acknowledge the interaction before discord gives up on us
+	err := bot.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Write(LogError, 0, time.Since(start))
+		return
+	}
+
+	err = bot.dispatcher.Dispatch(i.ChannelID, func() {
+		bot.runInteractionHandler(i, handler, msg, fields, log, start)
+	})
+	if err != nil {
+		size, _ := bot.sendInteractionResponse(i, msg, &interfaces.BotResponse{Content: busyMessage, Ephemeral: true})
+		log.Write(LogWarn, size, time.Since(start))
+	}
+}
+
+// runInteractionHandler runs a command on one of the dispatcher's workers and completes the
+// deferred interaction with its response
+func (bot *Bot) runInteractionHandler(i *discordgo.InteractionCreate, handler *BotMessageHandler, msg *discordgo.MessageCreate, fields []string, log LogEntry, start time.Time) {
+	// todo: this should be a setting instead of a magic number
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc()
+
+	results, err := handler.handle(ctx, msg, fields)
+	if err != nil {
+		results = &interfaces.BotResponse{Content: err.Error(), Ephemeral: true}
+	}
+	size, rerr := bot.sendInteractionResponse(i, msg, results)
+	switch {
+	case rerr != nil:
+		log.Write(LogError, size, time.Since(start))
+	case err != nil:
+		log.Write(LogWarn, size, time.Since(start))
+	default:
+		log.Write(LogInfo, size, time.Since(start))
+	}
+}
+
+// handleAutocomplete suggests values for the focused option of a partially typed command
+func (bot *Bot) handleAutocomplete(i *discordgo.InteractionCreate, msg *discordgo.MessageCreate, spec *interfaces.BotCommand) {
+	// discord only waits three seconds for suggestions
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFunc()
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	for _, option := range i.ApplicationCommandData().Options {
+		if !option.Focused || spec.Complete[option.Name] == nil {
+			continue
+		}
+		values, err := spec.Complete[option.Name](ctx, msg, fmt.Sprint(option.Value))
+		if err != nil {
+			break
+		}
+		for _, value := range values {
+			if len(choices) == maxChoices {
+				break
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+		}
+	}
+	bot.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// sendInteractionResponse completes a deferred interaction with a handler's response, and returns
+// the size of the content sent.
+func (bot *Bot) sendInteractionResponse(i *discordgo.InteractionCreate, msg *discordgo.MessageCreate, res *interfaces.BotResponse) (int, error) {
+	if res.Direct {
+		ch, err := bot.session.UserChannelCreate(msg.Author.ID)
+		if err != nil {
+			return 0, err
+		}
+		_, err = bot.session.ChannelMessageSendComplex(ch.ID, &discordgo.MessageSend{
+			Content: res.Content,
+			Embeds:  res.Embeds,
+			Files:   res.Files,
+		})
+		if err != nil {
+			return 0, err
+		}
+		res = &interfaces.BotResponse{Content: "I have sent you a direct message.", Ephemeral: true}
+	}
+	if res.Ephemeral {
+		// a deferred response cannot become ephemeral, so replace it with an ephemeral followup
+		err := bot.session.InteractionResponseDelete(i.Interaction)
+		if err != nil {
+			return 0, err
+		}
+		_, err = bot.session.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: res.Content,
+			Embeds:  res.Embeds,
+			Files:   res.Files,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return len(res.Content), err
+	}
+	content := res.Content
+	embeds := res.Embeds
+	sent, err := bot.session.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+		Embeds:  &embeds,
+		Files:   res.Files,
+	})
+	if err != nil {
+		return len(content), err
+	}
+	for _, reaction := range res.Reactions {
+		err = bot.session.MessageReactionAdd(sent.ChannelID, sent.ID, reaction)
+		if err != nil {
+			return len(content), err
+		}
+	}
+	return len(content), nil
+}
+
+// interactionMessage adapts an application command interaction to the message and fields which
+// would have invoked the same command.
+func interactionMessage(i *discordgo.InteractionCreate) (*discordgo.MessageCreate, []string) {
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	fields := make([]string, 0)
+	var args []string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == argsOption {
+			args = strings.Fields(fmt.Sprint(option.Value))
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("-%s=%v", option.Name, option.Value))
+	}
+	fields = append(fields, args...)
+	msg := &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    user,
+		Content:   strings.Join(append([]string{i.ApplicationCommandData().Name}, fields...), " "),
+	}
+	return &discordgo.MessageCreate{Message: msg}, fields
+}
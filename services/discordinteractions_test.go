@@ -0,0 +1,241 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/interfaces"
+	"github.com/kkragenbrink/slate/services/mocks"
+	"github.com/kkragenbrink/slate/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type InteractionSuite struct {
+	suite.Suite
+}
+
+func TestInteractionSuite(t *testing.T) {
+	suite.Run(t, new(InteractionSuite))
+}
+
+func genMockCommand(handle interfaces.BotResponseHandler) *interfaces.BotCommand {
+	return &interfaces.BotCommand{
+		Name:        "test",
+		Description: "a test command",
+		Args:        "things to test",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("loud", false, "whether to shout")
+			fs.Int("times", 1, "how many times")
+			fs.String("system", "", "which system")
+		},
+		Complete: map[string]interfaces.BotComplete{
+			"system": func(ctx context.Context, msg *discordgo.MessageCreate, partial string) ([]string, error) {
+				return []string{partial + "1", partial + "2"}, nil
+			},
+		},
+		Handle: handle,
+	}
+}
+
+func genMockInteraction(t discordgo.InteractionType, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "i1",
+		Type:      t,
+		ChannelID: "c1",
+		GuildID:   "g1",
+		Member:    &discordgo.Member{User: &discordgo.User{ID: "a1"}},
+		Data:      discordgo.ApplicationCommandInteractionData{Name: "test", Options: options},
+	}}
+}
+
+func (suite *InteractionSuite) TestApplicationCommands() {
+	bot := new(Bot)
+	bot.AddCommand(genMockCommand(nil))
+	cmds := bot.applicationCommands()
+	assert.Equal(suite.T(), 1, len(cmds))
+	assert.Equal(suite.T(), "test", cmds[0].Name)
+	assert.Equal(suite.T(), "a test command", cmds[0].Description)
+	opts := cmds[0].Options
+	assert.Equal(suite.T(), 4, len(opts))
+	assert.Equal(suite.T(), discordgo.ApplicationCommandOptionBoolean, opts[0].Type)
+	assert.Equal(suite.T(), discordgo.ApplicationCommandOptionString, opts[1].Type)
+	assert.True(suite.T(), opts[1].Autocomplete)
+	assert.Equal(suite.T(), discordgo.ApplicationCommandOptionInteger, opts[2].Type)
+	assert.Equal(suite.T(), "args", opts[3].Name)
+}
+
+func (suite *InteractionSuite) TestApplicationCommandsTruncated() {
+	bot := new(Bot)
+	cmd := genMockCommand(nil)
+	cmd.Args = strings.Repeat("●", 120)
+	bot.AddCommand(cmd)
+	opts := bot.applicationCommands()[0].Options
+	args := opts[len(opts)-1].Description
+	assert.Equal(suite.T(), descriptionLimit, utf8.RuneCountInString(args))
+	assert.True(suite.T(), utf8.ValidString(args))
+}
+
+func (suite *InteractionSuite) TestCommandDescriptions() {
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, new(DatabaseService), NewRandom(set))
+	assert.NotEmpty(suite.T(), bot.handlers)
+	// every description must fit within discord's limit as written, without being cut short
+	for _, handler := range bot.handlers {
+		spec := handler.spec
+		assert.True(suite.T(), utf8.RuneCountInString(spec.Description) <= descriptionLimit, spec.Name)
+		assert.True(suite.T(), utf8.RuneCountInString(spec.Args) <= descriptionLimit, spec.Name+" args")
+		if spec.Flags != nil {
+			fs := flag.NewFlagSet(spec.Name, flag.ContinueOnError)
+			spec.Flags(fs)
+			fs.VisitAll(func(f *flag.Flag) {
+				assert.True(suite.T(), utf8.RuneCountInString(f.Usage) <= descriptionLimit, spec.Name+" -"+f.Name)
+			})
+		}
+	}
+	for _, cmd := range bot.applicationCommands() {
+		assert.True(suite.T(), utf8.RuneCountInString(cmd.Description) <= descriptionLimit, cmd.Name)
+		for _, opt := range cmd.Options {
+			assert.True(suite.T(), utf8.RuneCountInString(opt.Description) <= descriptionLimit, cmd.Name+" "+opt.Name)
+		}
+	}
+}
+
+func (suite *InteractionSuite) TestCommandUsage() {
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, new(DatabaseService), NewRandom(set))
+	var help *BotMessageHandler
+	for _, handler := range bot.handlers {
+		if handler.spec.Name == "help" {
+			help = handler
+		}
+	}
+	// the usage of every command must fit in a message along with the mention of its author, as written
+	mention := "<@18446744073709551615> "
+	for _, handler := range bot.handlers {
+		name := handler.spec.Name
+		msg := &discordgo.MessageCreate{Message: &discordgo.Message{Content: "$help " + name, Author: &discordgo.User{ID: "a1"}}}
+		res, err := help.handle(context.Background(), msg, []string{name})
+		assert.Nil(suite.T(), err)
+		assert.True(suite.T(), utf8.RuneCountInString(mention+res.Content) <= 2000, name)
+		assert.False(suite.T(), strings.HasSuffix(res.Content, "..."), name)
+	}
+}
+
+func (suite *InteractionSuite) TestInteractionMessage() {
+	i := genMockInteraction(discordgo.InteractionApplicationCommand,
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "args", Type: discordgo.ApplicationCommandOptionString, Value: "3 -2"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "loud", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+	)
+	msg, fields := interactionMessage(i)
+	assert.Equal(suite.T(), []string{"-loud=true", "3", "-2"}, fields)
+	assert.Equal(suite.T(), "a1", msg.Author.ID)
+	assert.Equal(suite.T(), "g1", msg.GuildID)
+}
+
+func (suite *InteractionSuite) TestHandleInteractionCreate() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, new(DatabaseService), NewRandom(set))
+	session := mocks.NewMockDiscordSession(ctrl)
+	var got []string
+	bot.AddCommand(genMockCommand(func(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*interfaces.BotResponse, error) {
+		got = fields
+		return &interfaces.BotResponse{Content: "test", Reactions: []string{"🎉"}}, nil
+	}))
+	i := genMockInteraction(discordgo.InteractionApplicationCommand,
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "times", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(2)},
+	)
+	content := "test"
+	embeds := []*discordgo.MessageEmbed(nil)
+	session.EXPECT().InteractionRespond(gomock.Eq(i.Interaction), gomock.Eq(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}))
+	session.EXPECT().InteractionResponseEdit(gomock.Eq(i.Interaction), gomock.Eq(&discordgo.WebhookEdit{Content: &content, Embeds: &embeds})).
+		Return(&discordgo.Message{ID: "m1", ChannelID: "c1"}, nil)
+	session.EXPECT().MessageReactionAdd(gomock.Eq("c1"), gomock.Eq("m1"), gomock.Eq("🎉"))
+	bot.session = session
+	bot.handleInteractionCreate(session, i)
+	bot.dispatcher.Stop()
+	assert.Equal(suite.T(), []string{"-times=2"}, got)
+}
+
+func (suite *InteractionSuite) TestHandleInteractionCreateEphemeral() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	set := &settings.Settings{CommandPrefix: "$"}
+	bot, _ := NewBot(set, new(DatabaseService), NewRandom(set))
+	session := mocks.NewMockDiscordSession(ctrl)
+	bot.AddCommand(genMockCommand(func(ctx context.Context, msg *discordgo.MessageCreate, fields []string) (*interfaces.BotResponse, error) {
+		return nil, errSampleError
+	}))
+	i := genMockInteraction(discordgo.InteractionApplicationCommand)
+	session.EXPECT().InteractionRespond(gomock.Any(), gomock.Any())
+	session.EXPECT().InteractionResponseDelete(gomock.Eq(i.Interaction))
+	session.EXPECT().FollowupMessageCreate(gomock.Eq(i.Interaction), gomock.Eq(true), gomock.Eq(&discordgo.WebhookParams{
+		Content: "sample",
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}))
+	bot.session = session
+	bot.handleInteractionCreate(session, i)
+	bot.dispatcher.Stop()
+}
+
+func (suite *InteractionSuite) TestHandleAutocomplete() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	bot.dispatcher = NewDispatcher(1, 1)
+	session := mocks.NewMockDiscordSession(ctrl)
+	bot.AddCommand(genMockCommand(nil))
+	i := genMockInteraction(discordgo.InteractionApplicationCommandAutocomplete,
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "system", Type: discordgo.ApplicationCommandOptionString, Value: "co", Focused: true},
+	)
+	session.EXPECT().InteractionRespond(gomock.Eq(i.Interaction), gomock.Eq(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "co1", Value: "co1"},
+			{Name: "co2", Value: "co2"},
+		}},
+	}))
+	bot.session = session
+	bot.handleInteractionCreate(session, i)
+	bot.dispatcher.Stop()
+}
+
+func (suite *InteractionSuite) TestHandleReady() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	bot := new(Bot)
+	session := mocks.NewMockDiscordSession(ctrl)
+	bot.AddCommand(genMockCommand(nil))
+	session.EXPECT().ApplicationCommandBulkOverwrite(gomock.Eq("app"), gomock.Eq(""), gomock.Any())
+	bot.session = session
+	bot.handleReady(session, &discordgo.Ready{Application: &discordgo.Application{ID: "app"}})
+}
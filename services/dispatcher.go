@@ -0,0 +1,138 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"hash/fnv"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kkragenbrink/slate/interfaces"
+	"github.com/pkg/errors"
+)
+
+var errQueueFull = errors.New("the command queue is full")
+var errDispatcherStopped = errors.New("the dispatcher has stopped")
+
+// The Dispatcher runs bot commands on a bounded pool of workers.  Each worker has its own queue,
+// and every command for a channel is queued to the same worker, so commands in a channel are
+// handled in the order they arrive while other channels carry on.
+type Dispatcher struct {
+	queues     []chan func()
+	wg         sync.WaitGroup
+	mutex      sync.RWMutex
+	stopped    bool
+	dispatched uint64
+	completed  uint64
+	rejected   uint64
+}
+
+// NewDispatcher creates a new Dispatcher and starts its workers
+func NewDispatcher(workers, size int) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 1 {
+		size = 1
+	}
+	d := new(Dispatcher)
+	d.queues = make([]chan func(), workers)
+	for i := range d.queues {
+		d.queues[i] = make(chan func(), size)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *Dispatcher) work(queue chan func()) {
+	defer d.wg.Done()
+	for job := range queue {
+		d.run(job)
+		atomic.AddUint64(&d.completed, 1)
+	}
+}
+
+// run runs a job, logging rather than crashing should it panic, so one bad command cannot take
+// down the other channels
+func (d *Dispatcher) run(job func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("dispatched job panicked: %v\n%s", p, debug.Stack())
+		}
+	}()
+	job()
+}
+
+// Dispatch queues a job behind the other jobs for the same key.  If that queue is full, the job is
+// rejected rather than blocking the caller.
+func (d *Dispatcher) Dispatch(key string, job func()) error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.stopped {
+		return errDispatcherStopped
+	}
+	select {
+	case d.queues[d.shard(key)] <- job:
+		atomic.AddUint64(&d.dispatched, 1)
+		return nil
+	default:
+		atomic.AddUint64(&d.rejected, 1)
+		return errQueueFull
+	}
+}
+
+func (d *Dispatcher) shard(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.queues)))
+}
+
+// Stats reports the depth of the queues and the number of jobs handled
+func (d *Dispatcher) Stats() *interfaces.BotStats {
+	stats := new(interfaces.BotStats)
+	stats.Workers = len(d.queues)
+	stats.QueueDepth = make([]int, len(d.queues))
+	for i, queue := range d.queues {
+		stats.QueueDepth[i] = len(queue)
+		stats.Queued += len(queue)
+		stats.Capacity += cap(queue)
+	}
+	stats.Dispatched = atomic.LoadUint64(&d.dispatched)
+	stats.Completed = atomic.LoadUint64(&d.completed)
+	stats.Rejected = atomic.LoadUint64(&d.rejected)
+	return stats
+}
+
+// Stop stops accepting jobs and waits for the queued jobs to finish
+func (d *Dispatcher) Stop() {
+	d.mutex.Lock()
+	if !d.stopped {
+		d.stopped = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mutex.Unlock()
+	d.wg.Wait()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DispatcherSuite struct {
+	suite.Suite
+}
+
+func TestDispatcherSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherSuite))
+}
+
+func (suite *DispatcherSuite) TestDispatchOrder() {
+	d := NewDispatcher(4, 100)
+	var mutex sync.Mutex
+	seen := make(map[string][]int)
+	for n := 0; n < 50; n++ {
+		for _, key := range []string{"c1", "c2", "c3"} {
+			key, n := key, n
+			err := d.Dispatch(key, func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				seen[key] = append(seen[key], n)
+			})
+			assert.Nil(suite.T(), err)
+		}
+	}
+	d.Stop()
+	for _, key := range []string{"c1", "c2", "c3"} {
+		assert.Equal(suite.T(), 50, len(seen[key]))
+		for n, got := range seen[key] {
+			assert.Equal(suite.T(), n, got)
+		}
+	}
+	stats := d.Stats()
+	assert.Equal(suite.T(), uint64(150), stats.Dispatched)
+	assert.Equal(suite.T(), uint64(150), stats.Completed)
+}
+
+func (suite *DispatcherSuite) TestDispatchFull() {
+	d := NewDispatcher(1, 1)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	assert.Nil(suite.T(), d.Dispatch("c1", func() {
+		close(started)
+		<-block
+	}))
+	<-started
+	assert.Nil(suite.T(), d.Dispatch("c1", func() {}))
+	assert.Equal(suite.T(), errQueueFull, d.Dispatch("c1", func() {}))
+
+	stats := d.Stats()
+	assert.Equal(suite.T(), 1, stats.Workers)
+	assert.Equal(suite.T(), 1, stats.Queued)
+	assert.Equal(suite.T(), []int{1}, stats.QueueDepth)
+	assert.Equal(suite.T(), uint64(1), stats.Rejected)
+
+	close(block)
+	d.Stop()
+	assert.Equal(suite.T(), errDispatcherStopped, d.Dispatch("c1", func() {}))
+}
+
+func (suite *DispatcherSuite) TestDispatchPanic() {
+	d := NewDispatcher(1, 2)
+	ran := false
+	assert.Nil(suite.T(), d.Dispatch("c1", func() {
+		panic("boom")
+	}))
+	assert.Nil(suite.T(), d.Dispatch("c1", func() {
+		ran = true
+	}))
+	d.Stop()
+	assert.True(suite.T(), ran)
+	assert.Equal(suite.T(), uint64(2), d.Stats().Completed)
+}
@@ -45,30 +45,146 @@ func (mr *MockDiscordSessionMockRecorder) AddHandler(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHandler", reflect.TypeOf((*MockDiscordSession)(nil).AddHandler), arg0)
 }
 
+// ApplicationCommandBulkOverwrite mocks base method
+func (m *MockDiscordSession) ApplicationCommandBulkOverwrite(arg0, arg1 string, arg2 []*discordgo.ApplicationCommand, arg3 ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ApplicationCommandBulkOverwrite", varargs...)
+	ret0, _ := ret[0].([]*discordgo.ApplicationCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplicationCommandBulkOverwrite indicates an expected call of ApplicationCommandBulkOverwrite
+func (mr *MockDiscordSessionMockRecorder) ApplicationCommandBulkOverwrite(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplicationCommandBulkOverwrite", reflect.TypeOf((*MockDiscordSession)(nil).ApplicationCommandBulkOverwrite), varargs...)
+}
+
 // Channel mocks base method
-func (m *MockDiscordSession) Channel(arg0 string) (*discordgo.Channel, error) {
-	ret := m.ctrl.Call(m, "Channel", arg0)
+func (m *MockDiscordSession) Channel(arg0 string, arg1 ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Channel", varargs...)
 	ret0, _ := ret[0].(*discordgo.Channel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Channel indicates an expected call of Channel
-func (mr *MockDiscordSessionMockRecorder) Channel(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Channel", reflect.TypeOf((*MockDiscordSession)(nil).Channel), arg0)
+func (mr *MockDiscordSessionMockRecorder) Channel(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Channel", reflect.TypeOf((*MockDiscordSession)(nil).Channel), varargs...)
+}
+
+// ChannelMessageEditEmbed mocks base method
+func (m *MockDiscordSession) ChannelMessageEditEmbed(arg0, arg1 string, arg2 *discordgo.MessageEmbed, arg3 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessageEditEmbed", varargs...)
+	ret0, _ := ret[0].(*discordgo.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChannelMessageEditEmbed indicates an expected call of ChannelMessageEditEmbed
+func (mr *MockDiscordSessionMockRecorder) ChannelMessageEditEmbed(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageEditEmbed", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageEditEmbed), varargs...)
+}
+
+// ChannelMessagePin mocks base method
+func (m *MockDiscordSession) ChannelMessagePin(arg0, arg1 string, arg2 ...discordgo.RequestOption) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessagePin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChannelMessagePin indicates an expected call of ChannelMessagePin
+func (mr *MockDiscordSessionMockRecorder) ChannelMessagePin(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessagePin", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessagePin), varargs...)
 }
 
 // ChannelMessageSend mocks base method
-func (m *MockDiscordSession) ChannelMessageSend(arg0, arg1 string) (*discordgo.Message, error) {
-	ret := m.ctrl.Call(m, "ChannelMessageSend", arg0, arg1)
+func (m *MockDiscordSession) ChannelMessageSend(arg0, arg1 string, arg2 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessageSend", varargs...)
 	ret0, _ := ret[0].(*discordgo.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ChannelMessageSend indicates an expected call of ChannelMessageSend
-func (mr *MockDiscordSessionMockRecorder) ChannelMessageSend(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageSend", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageSend), arg0, arg1)
+func (mr *MockDiscordSessionMockRecorder) ChannelMessageSend(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageSend", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageSend), varargs...)
+}
+
+// ChannelMessageSendComplex mocks base method
+func (m *MockDiscordSession) ChannelMessageSendComplex(arg0 string, arg1 *discordgo.MessageSend, arg2 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessageSendComplex", varargs...)
+	ret0, _ := ret[0].(*discordgo.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChannelMessageSendComplex indicates an expected call of ChannelMessageSendComplex
+func (mr *MockDiscordSessionMockRecorder) ChannelMessageSendComplex(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageSendComplex", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageSendComplex), varargs...)
+}
+
+// ChannelMessageSendEmbed mocks base method
+func (m *MockDiscordSession) ChannelMessageSendEmbed(arg0 string, arg1 *discordgo.MessageEmbed, arg2 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessageSendEmbed", varargs...)
+	ret0, _ := ret[0].(*discordgo.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChannelMessageSendEmbed indicates an expected call of ChannelMessageSendEmbed
+func (mr *MockDiscordSessionMockRecorder) ChannelMessageSendEmbed(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageSendEmbed", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageSendEmbed), varargs...)
+}
+
+// ChannelMessageUnpin mocks base method
+func (m *MockDiscordSession) ChannelMessageUnpin(arg0, arg1 string, arg2 ...discordgo.RequestOption) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChannelMessageUnpin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChannelMessageUnpin indicates an expected call of ChannelMessageUnpin
+func (mr *MockDiscordSessionMockRecorder) ChannelMessageUnpin(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelMessageUnpin", reflect.TypeOf((*MockDiscordSession)(nil).ChannelMessageUnpin), varargs...)
 }
 
 // Close mocks base method
@@ -83,17 +199,163 @@ func (mr *MockDiscordSessionMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDiscordSession)(nil).Close))
 }
 
+// FollowupMessageCreate mocks base method
+func (m *MockDiscordSession) FollowupMessageCreate(arg0 *discordgo.Interaction, arg1 bool, arg2 *discordgo.WebhookParams, arg3 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FollowupMessageCreate", varargs...)
+	ret0, _ := ret[0].(*discordgo.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowupMessageCreate indicates an expected call of FollowupMessageCreate
+func (mr *MockDiscordSessionMockRecorder) FollowupMessageCreate(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowupMessageCreate", reflect.TypeOf((*MockDiscordSession)(nil).FollowupMessageCreate), varargs...)
+}
+
+// Guild mocks base method
+func (m *MockDiscordSession) Guild(arg0 string, arg1 ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Guild", varargs...)
+	ret0, _ := ret[0].(*discordgo.Guild)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Guild indicates an expected call of Guild
+func (mr *MockDiscordSessionMockRecorder) Guild(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Guild", reflect.TypeOf((*MockDiscordSession)(nil).Guild), varargs...)
+}
+
 // GuildChannels mocks base method
-func (m *MockDiscordSession) GuildChannels(arg0 string) ([]*discordgo.Channel, error) {
-	ret := m.ctrl.Call(m, "GuildChannels", arg0)
+func (m *MockDiscordSession) GuildChannels(arg0 string, arg1 ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GuildChannels", varargs...)
 	ret0, _ := ret[0].([]*discordgo.Channel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GuildChannels indicates an expected call of GuildChannels
-func (mr *MockDiscordSessionMockRecorder) GuildChannels(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuildChannels", reflect.TypeOf((*MockDiscordSession)(nil).GuildChannels), arg0)
+func (mr *MockDiscordSessionMockRecorder) GuildChannels(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuildChannels", reflect.TypeOf((*MockDiscordSession)(nil).GuildChannels), varargs...)
+}
+
+// GuildMember mocks base method
+func (m *MockDiscordSession) GuildMember(arg0, arg1 string, arg2 ...discordgo.RequestOption) (*discordgo.Member, error) {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GuildMember", varargs...)
+	ret0, _ := ret[0].(*discordgo.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GuildMember indicates an expected call of GuildMember
+func (mr *MockDiscordSessionMockRecorder) GuildMember(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuildMember", reflect.TypeOf((*MockDiscordSession)(nil).GuildMember), varargs...)
+}
+
+// GuildMembers mocks base method
+func (m *MockDiscordSession) GuildMembers(arg0, arg1 string, arg2 int, arg3 ...discordgo.RequestOption) ([]*discordgo.Member, error) {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GuildMembers", varargs...)
+	ret0, _ := ret[0].([]*discordgo.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GuildMembers indicates an expected call of GuildMembers
+func (mr *MockDiscordSessionMockRecorder) GuildMembers(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuildMembers", reflect.TypeOf((*MockDiscordSession)(nil).GuildMembers), varargs...)
+}
+
+// InteractionRespond mocks base method
+func (m *MockDiscordSession) InteractionRespond(arg0 *discordgo.Interaction, arg1 *discordgo.InteractionResponse, arg2 ...discordgo.RequestOption) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InteractionRespond", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InteractionRespond indicates an expected call of InteractionRespond
+func (mr *MockDiscordSessionMockRecorder) InteractionRespond(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InteractionRespond", reflect.TypeOf((*MockDiscordSession)(nil).InteractionRespond), varargs...)
+}
+
+// InteractionResponseDelete mocks base method
+func (m *MockDiscordSession) InteractionResponseDelete(arg0 *discordgo.Interaction, arg1 ...discordgo.RequestOption) error {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InteractionResponseDelete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InteractionResponseDelete indicates an expected call of InteractionResponseDelete
+func (mr *MockDiscordSessionMockRecorder) InteractionResponseDelete(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InteractionResponseDelete", reflect.TypeOf((*MockDiscordSession)(nil).InteractionResponseDelete), varargs...)
+}
+
+// InteractionResponseEdit mocks base method
+func (m *MockDiscordSession) InteractionResponseEdit(arg0 *discordgo.Interaction, arg1 *discordgo.WebhookEdit, arg2 ...discordgo.RequestOption) (*discordgo.Message, error) {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InteractionResponseEdit", varargs...)
+	ret0, _ := ret[0].(*discordgo.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InteractionResponseEdit indicates an expected call of InteractionResponseEdit
+func (mr *MockDiscordSessionMockRecorder) InteractionResponseEdit(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InteractionResponseEdit", reflect.TypeOf((*MockDiscordSession)(nil).InteractionResponseEdit), varargs...)
+}
+
+// MessageReactionAdd mocks base method
+func (m *MockDiscordSession) MessageReactionAdd(arg0, arg1, arg2 string, arg3 ...discordgo.RequestOption) error {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MessageReactionAdd", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MessageReactionAdd indicates an expected call of MessageReactionAdd
+func (mr *MockDiscordSessionMockRecorder) MessageReactionAdd(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageReactionAdd", reflect.TypeOf((*MockDiscordSession)(nil).MessageReactionAdd), varargs...)
 }
 
 // Open mocks base method
@@ -109,14 +371,37 @@ func (mr *MockDiscordSessionMockRecorder) Open() *gomock.Call {
 }
 
 // User mocks base method
-func (m *MockDiscordSession) User(arg0 string) (*discordgo.User, error) {
-	ret := m.ctrl.Call(m, "User", arg0)
+func (m *MockDiscordSession) User(arg0 string, arg1 ...discordgo.RequestOption) (*discordgo.User, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "User", varargs...)
 	ret0, _ := ret[0].(*discordgo.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // User indicates an expected call of User
-func (mr *MockDiscordSessionMockRecorder) User(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockDiscordSession)(nil).User), arg0)
+func (mr *MockDiscordSessionMockRecorder) User(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockDiscordSession)(nil).User), varargs...)
+}
+
+// UserChannelCreate mocks base method
+func (m *MockDiscordSession) UserChannelCreate(arg0 string, arg1 ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UserChannelCreate", varargs...)
+	ret0, _ := ret[0].(*discordgo.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserChannelCreate indicates an expected call of UserChannelCreate
+func (mr *MockDiscordSessionMockRecorder) UserChannelCreate(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserChannelCreate", reflect.TypeOf((*MockDiscordSession)(nil).UserChannelCreate), varargs...)
 }
@@ -21,58 +21,148 @@
 package services
 
 import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
 	"math/rand"
+	"sync"
 
+	"github.com/kkragenbrink/slate/domains"
 	"github.com/kkragenbrink/slate/settings"
-	"github.com/sgade/randomorg"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/pkg/errors"
 )
 
-type mode int8
+// errRandomOrgDisabled is thrown when random.org is needed but no API key is configured
+var errRandomOrgDisabled = errors.New("random.org is not configured")
 
-const (
-	modeMathRandom mode = 1
-	modeRandomOrg  mode = 2
-)
+// A randomSource draws dice between min and max, inclusive
+type randomSource interface {
+	Draw(times int, min, max int64) (*domains.RollDraw, error)
+}
 
 // The RandomService is responsible for generating random numbers
 type RandomService struct {
 	settings *settings.Settings
-	mode     mode
-	random   *randomorg.Random
+	source   randomSource
+	random   *randomOrgClient
+	pool     *randomOrgPool
 }
 
 // NewRandom instantiates and configures the random service
 func NewRandom(set *settings.Settings) *RandomService {
 	rand := new(RandomService)
 	rand.settings = set
-	rand.mode = modeMathRandom
 
 	if rand.settings.RandomOrgAPIKey != "" {
-		rand.mode = modeRandomOrg
-		rand.random = randomorg.NewRandom(rand.settings.RandomOrgAPIKey)
+		rand.random = newRandomOrgClient(rand.settings.RandomOrgAPIKey)
+	}
+
+	switch {
+	case set.RandomSource == settings.RandomSourceSeeded:
+		rand.source = newSeededSource(set.RandomSeed)
+	case set.RandomSource == settings.RandomSourceCrypto, rand.random == nil:
+		rand.source = new(cryptoSource)
+	default:
+		rand.pool = newRandomOrgPool(rand.random, new(cryptoSource))
+		rand.source = rand.pool
 	}
 
 	return rand
 }
 
+// KeepBatches sets where the batches signed by random.org are kept until they are published, so
+// that the dice drawn from them can be verified.  The pool fetches nothing until it is set.
+func (r *RandomService) KeepBatches(batches domains.SignedBatchRepository) {
+	if r.pool != nil {
+		r.pool.keep = batches
+	}
+}
+
+// Start begins prefetching from random.org, when it is the source of the dice
+func (r *RandomService) Start() error {
+	if r.pool != nil {
+		r.pool.Start()
+	}
+	return nil
+}
+
+// Stop stops prefetching from random.org
+func (r *RandomService) Stop() error {
+	if r.pool != nil {
+		r.pool.Stop()
+	}
+	return nil
+}
+
 // Rand generates a slice of random numbers between min and max.
 func (r *RandomService) Rand(times int, min, max int64) ([]int64, error) {
-	if r.mode == modeRandomOrg {
-		rolls, err := r.random.GenerateIntegers(times, min, max)
-		if err != nil {
-			return nil, err
-		}
-		return rolls, nil
+	d, err := r.Draw(times, min, max)
+	if err != nil {
+		return nil, err
 	}
+	return d.Dice, nil
+}
 
-	return mathRand(times, min, max), nil
+// Draw generates a slice of random numbers between min and max, along with where they came from
+// and random.org's signature when it generated them.
+func (r *RandomService) Draw(times int, min, max int64) (*domains.RollDraw, error) {
+	if max < min {
+		return nil, roll.ErrInvalidRange
+	}
+	return r.source.Draw(times, min, max)
 }
 
-func mathRand(times int, min, max int64) []int64 {
-	var results []int64
-	for i := 0; i < times; i++ {
-		roll := rand.Int63n(max) + min
-		results = append(results, roll)
+// VerifyDraw asks random.org whether it signed a draw
+func (r *RandomService) VerifyDraw(ctx context.Context, d *domains.RollDraw) (bool, error) {
+	if r.random == nil {
+		return false, errRandomOrgDisabled
 	}
-	return results
+	return r.random.verifySignature(ctx, d.Random, d.Signature)
+}
+
+// The cryptoSource draws dice from the operating system's secure random number generator
+type cryptoSource struct{}
+
+func (s *cryptoSource) Draw(times int, min, max int64) (*domains.RollDraw, error) {
+	dice, err := roll.Uniform(times, min, max, s.next)
+	if err != nil {
+		return nil, err
+	}
+	return &domains.RollDraw{Times: times, Min: min, Max: max, Dice: dice, Source: domains.RandomCrypto}, nil
+}
+
+func (s *cryptoSource) next() (uint64, error) {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return 0, errors.Wrap(err, "unable to read random bytes")
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// The seededSource draws the same dice every time it is given the same seed, for reproducible
+// tests and replays
+type seededSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newSeededSource(seed int64) *seededSource {
+	s := new(seededSource)
+	s.rng = rand.New(rand.NewSource(seed))
+	return s
+}
+
+func (s *seededSource) Draw(times int, min, max int64) (*domains.RollDraw, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dice, err := roll.Uniform(times, min, max, s.next)
+	if err != nil {
+		return nil, err
+	}
+	return &domains.RollDraw{Times: times, Min: min, Max: max, Dice: dice, Source: domains.RandomSeeded}, nil
+}
+
+func (s *seededSource) next() (uint64, error) {
+	return s.rng.Uint64(), nil
 }
@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/settings"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RandomSuite struct {
+	suite.Suite
+}
+
+func TestRandomSuite(t *testing.T) {
+	suite.Run(t, new(RandomSuite))
+}
+
+func (suite *RandomSuite) TestSource() {
+	r := NewRandom(&settings.Settings{})
+	assert.IsType(suite.T(), new(cryptoSource), r.source)
+	r = NewRandom(&settings.Settings{RandomOrgAPIKey: "key"})
+	assert.IsType(suite.T(), new(randomOrgPool), r.source)
+	r = NewRandom(&settings.Settings{RandomOrgAPIKey: "key", RandomSource: settings.RandomSourceCrypto})
+	assert.IsType(suite.T(), new(cryptoSource), r.source)
+	assert.NotNil(suite.T(), r.random)
+	r = NewRandom(&settings.Settings{RandomSource: settings.RandomSourceSeeded})
+	assert.IsType(suite.T(), new(seededSource), r.source)
+}
+
+func (suite *RandomSuite) TestCrypto() {
+	r := NewRandom(&settings.Settings{RandomSource: settings.RandomSourceCrypto})
+	seen := make(map[int64]bool)
+	for i := 0; i < 50; i++ {
+		d, err := r.Draw(10, 3, 5)
+		assert.Nil(suite.T(), err)
+		assert.Len(suite.T(), d.Dice, 10)
+		for _, die := range d.Dice {
+			assert.True(suite.T(), 3 <= die && die <= 5, "%d is out of range", die)
+			seen[die] = true
+		}
+		assert.Equal(suite.T(), domains.RandomCrypto, d.Source)
+		assert.Empty(suite.T(), d.Signature)
+	}
+	assert.Len(suite.T(), seen, 3)
+}
+
+func (suite *RandomSuite) TestSeeded() {
+	set := &settings.Settings{RandomSource: settings.RandomSourceSeeded, RandomSeed: 42}
+	first, err := NewRandom(set).Rand(20, -10, 10)
+	assert.Nil(suite.T(), err)
+	second, err := NewRandom(set).Rand(20, -10, 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), first, second)
+	for _, die := range first {
+		assert.True(suite.T(), -10 <= die && die <= 10, "%d is out of range", die)
+	}
+
+	set.RandomSeed = 43
+	third, err := NewRandom(set).Rand(20, -10, 10)
+	assert.Nil(suite.T(), err)
+	assert.NotEqual(suite.T(), first, third)
+}
+
+func (suite *RandomSuite) TestInvalidRange() {
+	_, err := NewRandom(&settings.Settings{}).Draw(1, 6, 1)
+	assert.Equal(suite.T(), roll.ErrInvalidRange, err)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// randomOrgURL is the endpoint of the random.org JSON-RPC API
+const randomOrgURL = "https://api.random.org/json-rpc/4/invoke"
+
+// randomOrgTimeout bounds each request to random.org
+// todo: this should be a setting instead of a magic number
+const randomOrgTimeout = 3 * time.Second
+
+// A randomOrgClient calls the signed methods of the random.org JSON-RPC API
+type randomOrgClient struct {
+	apiKey string
+	url    string
+	client *http.Client
+	id     uint64
+}
+
+// newRandomOrgClient creates a new client for the random.org API
+func newRandomOrgClient(apiKey string) *randomOrgClient {
+	c := new(randomOrgClient)
+	c.apiKey = apiKey
+	c.url = randomOrgURL
+	c.client = &http.Client{Timeout: randomOrgTimeout}
+	return c
+}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+	ID      uint64      `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("random.org error %d: %s", e.Code, e.Message)
+}
+
+// signedIntegers is the result of generateSignedIntegers
+type signedIntegers struct {
+	Random       json.RawMessage `json:"random"`
+	Signature    string          `json:"signature"`
+	BitsLeft     int64           `json:"bitsLeft"`
+	RequestsLeft int64           `json:"requestsLeft"`
+	data         []int64
+}
+
+// randomOrgUsage is the quota left on an API key, as reported by getUsage
+type randomOrgUsage struct {
+	Status       string `json:"status"`
+	BitsLeft     int64  `json:"bitsLeft"`
+	RequestsLeft int64  `json:"requestsLeft"`
+}
+
+// call invokes a method of the API and decodes its result
+func (c *randomOrgClient) call(ctx context.Context, method string, params, result interface{}) error {
+	body, err := json.Marshal(&rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: atomic.AddUint64(&c.id, 1)})
+	if err != nil {
+		return errors.Wrap(err, "could not encode request")
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "could not reach random.org")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("random.org responded %s", res.Status)
+	}
+	var rpc rpcResponse
+	err = json.NewDecoder(res.Body).Decode(&rpc)
+	if err != nil {
+		return errors.Wrap(err, "could not decode response")
+	}
+	if rpc.Error != nil {
+		return rpc.Error
+	}
+	return errors.Wrap(json.Unmarshal(rpc.Result, result), "could not decode result")
+}
+
+// generateSignedIntegers draws n integers between min and max, signed by random.org
+func (c *randomOrgClient) generateSignedIntegers(ctx context.Context, n int, min, max int64) (*signedIntegers, error) {
+	params := map[string]interface{}{"apiKey": c.apiKey, "n": n, "min": min, "max": max, "replacement": true}
+	result := new(signedIntegers)
+	err := c.call(ctx, "generateSignedIntegers", params, result)
+	if err != nil {
+		return nil, err
+	}
+	var random struct {
+		Data []int64 `json:"data"`
+	}
+	err = json.Unmarshal(result.Random, &random)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode random")
+	}
+	if len(random.Data) != n {
+		return nil, errors.Errorf("random.org sent %d integers instead of %d", len(random.Data), n)
+	}
+	result.data = random.Data
+	return result, nil
+}
+
+// verifySignature asks random.org whether it signed a random object
+func (c *randomOrgClient) verifySignature(ctx context.Context, random json.RawMessage, signature string) (bool, error) {
+	params := map[string]interface{}{"random": random, "signature": signature}
+	var result struct {
+		Authenticity bool `json:"authenticity"`
+	}
+	err := c.call(ctx, "verifySignature", params, &result)
+	if err != nil {
+		return false, err
+	}
+	return result.Authenticity, nil
+}
+
+// getUsage asks random.org how much of the API key's quota is left
+func (c *randomOrgClient) getUsage(ctx context.Context) (*randomOrgUsage, error) {
+	params := map[string]interface{}{"apiKey": c.apiKey}
+	result := new(randomOrgUsage)
+	err := c.call(ctx, "getUsage", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
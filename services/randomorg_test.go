@@ -0,0 +1,323 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RandomOrgSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	mutex    sync.Mutex
+	requests []string
+	bitsLeft int64
+	down     bool
+	batches  *keptBatches
+}
+
+// keptBatches keeps signed batches in memory
+type keptBatches struct {
+	mutex   sync.Mutex
+	batches map[string]domains.SignedBatch
+}
+
+func (k *keptBatches) FindByID(ctx context.Context, id string) (*domains.SignedBatch, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	b, ok := k.batches[id]
+	if !ok {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (k *keptBatches) Store(ctx context.Context, b *domains.SignedBatch) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	b.Published = b.Published || k.batches[b.ID].Published
+	k.batches[b.ID] = *b
+	return nil
+}
+
+func (k *keptBatches) PublishAll(ctx context.Context) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	for id, b := range k.batches {
+		b.Published = true
+		k.batches[id] = b
+	}
+	return nil
+}
+
+func TestRandomOrgSuite(t *testing.T) {
+	suite.Run(t, new(RandomOrgSuite))
+}
+
+// SetupTest starts a fake random.org which signs integers counting up from min
+func (suite *RandomOrgSuite) SetupTest() {
+	suite.requests = nil
+	suite.bitsLeft = 250000
+	suite.down = false
+	suite.batches = &keptBatches{batches: make(map[string]domains.SignedBatch)}
+	suite.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var rpc struct {
+			Method string                     `json:"method"`
+			Params map[string]json.RawMessage `json:"params"`
+			ID     uint64                     `json:"id"`
+		}
+		json.NewDecoder(req.Body).Decode(&rpc)
+		suite.mutex.Lock()
+		defer suite.mutex.Unlock()
+		suite.requests = append(suite.requests, rpc.Method)
+		if suite.down {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var result interface{}
+		switch rpc.Method {
+		case "generateSignedIntegers":
+			var n, min, max int64
+			json.Unmarshal(rpc.Params["n"], &n)
+			json.Unmarshal(rpc.Params["min"], &min)
+			json.Unmarshal(rpc.Params["max"], &max)
+			data := make([]int64, 0, n)
+			for i := int64(0); i < n; i++ {
+				data = append(data, min+i%(max-min+1))
+			}
+			suite.bitsLeft -= n * 4
+			random := map[string]interface{}{"method": rpc.Method, "n": n, "min": min, "max": max, "data": data}
+			result = map[string]interface{}{"random": random, "signature": "signed", "bitsLeft": suite.bitsLeft, "requestsLeft": 1000}
+		case "verifySignature":
+			var signature string
+			json.Unmarshal(rpc.Params["signature"], &signature)
+			result = map[string]interface{}{"authenticity": signature == "signed"}
+		case "getUsage":
+			result = map[string]interface{}{"status": "running", "bitsLeft": suite.bitsLeft, "requestsLeft": 1000}
+		default:
+			json.NewEncoder(res).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"error":   map[string]interface{}{"code": 32000, "message": "unknown method"},
+				"id":      rpc.ID,
+			})
+			return
+		}
+		json.NewEncoder(res).Encode(map[string]interface{}{"jsonrpc": "2.0", "result": result, "id": rpc.ID})
+	}))
+}
+
+func (suite *RandomOrgSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *RandomOrgSuite) random() *RandomService {
+	r := NewRandom(&settings.Settings{RandomOrgAPIKey: "key"})
+	r.random.url = suite.server.URL
+	r.KeepBatches(suite.batches)
+	return r
+}
+
+func (suite *RandomOrgSuite) sent() []string {
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+	return append([]string(nil), suite.requests...)
+}
+
+func (suite *RandomOrgSuite) TestDraw() {
+	r := suite.random()
+	// the pool starts empty, so the first dice of a range fall back
+	d, err := r.Draw(3, 1, 10)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), d.Dice, 3)
+	assert.Equal(suite.T(), domains.RandomCrypto, d.Source)
+	assert.Empty(suite.T(), d.Signature)
+
+	r.pool.fill()
+	d, err = r.Draw(3, 1, 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{1, 2, 3}, d.Dice)
+	assert.Equal(suite.T(), domains.RandomOrg, d.Source)
+	assert.Equal(suite.T(), batchID("signed"), d.Batch)
+	assert.Equal(suite.T(), 0, d.Offset)
+	assert.Empty(suite.T(), d.Random)
+	assert.Empty(suite.T(), d.Signature)
+	dice, err := r.Rand(2, 1, 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{4, 5}, dice)
+
+	b, _ := suite.batches.FindByID(context.Background(), d.Batch)
+	signed := *d
+	signed.Random = b.Random
+	signed.Signature = b.Signature
+	ok, err := r.VerifyDraw(context.Background(), &signed)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), ok)
+	signed.Signature = "forged"
+	ok, err = r.VerifyDraw(context.Background(), &signed)
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), []string{"getUsage", "generateSignedIntegers", "verifySignature", "verifySignature"}, suite.sent())
+}
+
+func (suite *RandomOrgSuite) TestWithheldBatch() {
+	r := suite.random()
+	r.Draw(1, 1, 1000)
+	r.pool.fill()
+	d, err := r.Draw(3, 1, 1000)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{1, 2, 3}, d.Dice)
+
+	// the proof of a draw holds none of the integers which are yet to be drawn
+	proof, _ := json.Marshal(d)
+	var fields map[string]interface{}
+	json.Unmarshal(proof, &fields)
+	for _, n := range numbers(fields) {
+		assert.False(suite.T(), n > 3 && n <= poolBatch, "%v is yet to be drawn", n)
+	}
+	b, _ := suite.batches.FindByID(context.Background(), d.Batch)
+	assert.False(suite.T(), b.Published)
+
+	// and the batch is published once every integer in it is drawn
+	r.Draw(poolBatch-3, 1, 1000)
+	r.pool.fill()
+	b, _ = suite.batches.FindByID(context.Background(), d.Batch)
+	assert.True(suite.T(), b.Published)
+}
+
+// numbers lists every number within a decoded JSON value
+func numbers(value interface{}) []float64 {
+	switch v := value.(type) {
+	case float64:
+		return []float64{v}
+	case []interface{}:
+		ns := make([]float64, 0)
+		for _, e := range v {
+			ns = append(ns, numbers(e)...)
+		}
+		return ns
+	case map[string]interface{}:
+		ns := make([]float64, 0)
+		for _, e := range v {
+			ns = append(ns, numbers(e)...)
+		}
+		return ns
+	}
+	return nil
+}
+
+func (suite *RandomOrgSuite) TestDrawOffset() {
+	r := suite.random()
+	r.Draw(1, 1, 6)
+	r.pool.fill()
+	r.Draw(40, 1, 6)
+	d, _ := r.Draw(15, 1, 6)
+	assert.Equal(suite.T(), 40, d.Offset)
+	assert.Equal(suite.T(), []int64{5, 6, 1, 2, 3}, d.Dice[:5])
+
+	// the pool is low, so another batch is fetched, and dice which don't fit in what is left of the
+	// first batch are drawn from the second
+	r.pool.fill()
+	d, _ = r.Draw(60, 1, 6)
+	assert.Equal(suite.T(), domains.RandomOrg, d.Source)
+	assert.Equal(suite.T(), 0, d.Offset)
+	assert.Equal(suite.T(), []string{"getUsage", "generateSignedIntegers", "generateSignedIntegers"}, suite.sent())
+
+	// more dice than a batch holds always fall back
+	d, _ = r.Draw(poolBatch+1, 1, 6)
+	assert.Equal(suite.T(), domains.RandomCrypto, d.Source)
+}
+
+func (suite *RandomOrgSuite) TestFallback() {
+	suite.down = true
+	r := suite.random()
+	r.Draw(1, 1, 10)
+	r.pool.fill()
+	d, err := r.Draw(2, 1, 10)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), domains.RandomCrypto, d.Source)
+
+	// random.org answers again, but the pool waits before trying again
+	suite.down = false
+	r.pool.fill()
+	assert.Equal(suite.T(), []string{"getUsage"}, suite.sent())
+	r.pool.failed = time.Time{}
+	r.pool.fill()
+	d, _ = r.Draw(2, 1, 10)
+	assert.Equal(suite.T(), domains.RandomOrg, d.Source)
+}
+
+func (suite *RandomOrgSuite) TestQuota() {
+	suite.bitsLeft = 100
+	r := suite.random()
+	r.Draw(1, 1, 10)
+	r.pool.fill()
+	r.pool.fill()
+	d, _ := r.Draw(1, 1, 10)
+	assert.Equal(suite.T(), domains.RandomCrypto, d.Source)
+	assert.Equal(suite.T(), []string{"getUsage"}, suite.sent())
+
+	// the quota is checked again once the usage interval passes
+	suite.bitsLeft = 1000
+	r.pool.checked = time.Now().Add(-usageInterval)
+	r.pool.fill()
+	assert.Equal(suite.T(), []string{"getUsage", "getUsage", "generateSignedIntegers"}, suite.sent())
+	assert.Equal(suite.T(), int64(600), r.pool.usage.BitsLeft)
+}
+
+func (suite *RandomOrgSuite) TestBackground() {
+	r := suite.random()
+	assert.Nil(suite.T(), r.Start())
+	defer r.Stop()
+	r.Draw(1, 1, 20)
+	deadline := time.Now().Add(time.Second)
+	for {
+		d, err := r.Draw(1, 1, 20)
+		assert.Nil(suite.T(), err)
+		if d.Source == domains.RandomOrg || time.Now().After(deadline) {
+			assert.Equal(suite.T(), domains.RandomOrg, d.Source)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (suite *RandomOrgSuite) TestError() {
+	r := suite.random()
+	err := r.random.call(context.Background(), "generateNothing", nil, nil)
+	assert.EqualError(suite.T(), err, "random.org error 32000: unknown method")
+}
+
+func (suite *RandomOrgSuite) TestVerifyDisabled() {
+	r := NewRandom(&settings.Settings{})
+	_, err := r.VerifyDraw(context.Background(), nil)
+	assert.Equal(suite.T(), errRandomOrgDisabled, err)
+}
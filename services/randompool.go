@@ -0,0 +1,291 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/kkragenbrink/slate/domains"
+)
+
+// poolBatch is how many integers are fetched from random.org for a range of dice at a time
+const poolBatch = 100
+
+// poolLow is how few integers may be left for a range of dice before another batch is fetched
+const poolLow = poolBatch / 2
+
+// randomOrgLimit bounds the integers random.org will generate
+const randomOrgLimit = 1e9
+
+// retryInterval is how long the pool waits to fetch integers again after random.org failed
+// todo: these should be settings instead of magic numbers
+const retryInterval = time.Minute
+
+// usageInterval is how long the pool waits to check the quota again after it ran out
+const usageInterval = 10 * time.Minute
+
+// A poolRange is a range of dice which the pool keeps integers for
+type poolRange struct {
+	min, max int64
+}
+
+// A signedBatch is a batch of integers signed by random.org, and how many of them have been drawn
+type signedBatch struct {
+	*signedIntegers
+	id    string
+	drawn int
+}
+
+// batchID names a signed batch by the hash of its signature, which random.org makes unique
+func batchID(signature string) string {
+	sum := sha256.Sum256([]byte(signature))
+	return hex.EncodeToString(sum[:])
+}
+
+// The randomOrgPool keeps integers prefetched from random.org for each range of dice which has
+// been rolled, so that rolls needn't wait on random.org.  While the pool is empty, or random.org is
+// failing or out of quota, dice are drawn from the fallback source instead.
+//
+// A draw only names the batch its dice came from.  The signed batch holds the integers which are
+// yet to be drawn, so it is kept unpublished until all of them have been drawn or thrown away.
+type randomOrgPool struct {
+	client   *randomOrgClient
+	fallback randomSource
+	keep     domains.SignedBatchRepository
+	mutex    sync.Mutex
+	batches  map[poolRange][]*signedBatch
+	retired  []*signedBatch
+	usage    *randomOrgUsage
+	checked  time.Time
+	failed   time.Time
+	wake     chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// newRandomOrgPool creates a new, empty pool of integers from random.org
+func newRandomOrgPool(client *randomOrgClient, fallback randomSource) *randomOrgPool {
+	p := new(randomOrgPool)
+	p.client = client
+	p.fallback = fallback
+	p.batches = make(map[poolRange][]*signedBatch)
+	p.wake = make(chan struct{}, 1)
+	return p
+}
+
+// Draw takes dice from the pool, or from the fallback source when the pool has too few
+func (p *randomOrgPool) Draw(times int, min, max int64) (*domains.RollDraw, error) {
+	if min < -randomOrgLimit || max > randomOrgLimit || times > poolBatch {
+		return p.fallback.Draw(times, min, max)
+	}
+	p.mutex.Lock()
+	d := p.take(times, poolRange{min, max})
+	p.mutex.Unlock()
+	// let the pool top itself up without holding up the roll
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	if d == nil {
+		return p.fallback.Draw(times, min, max)
+	}
+	return d, nil
+}
+
+// take draws dice from the oldest batch which has enough left for them.  Whatever is left of the
+// batches before it is thrown away, since the dice of a draw must come from a single batch, and
+// batches which are used up or thrown away are retired to be published.
+func (p *randomOrgPool) take(times int, r poolRange) *domains.RollDraw {
+	batches, ok := p.batches[r]
+	if !ok {
+		// start keeping integers for the range
+		p.batches[r] = nil
+		return nil
+	}
+	for len(batches) > 0 && len(batches[0].data)-batches[0].drawn < times {
+		p.retired = append(p.retired, batches[0])
+		batches = batches[1:]
+	}
+	p.batches[r] = batches
+	if len(batches) == 0 {
+		return nil
+	}
+	b := batches[0]
+	d := &domains.RollDraw{Times: times, Min: r.min, Max: r.max, Source: domains.RandomOrg}
+	d.Dice = append([]int64(nil), b.data[b.drawn:b.drawn+times]...)
+	d.Batch = b.id
+	d.Offset = b.drawn
+	b.drawn += times
+	if b.drawn == len(b.data) {
+		p.retired = append(p.retired, b)
+		p.batches[r] = batches[1:]
+	}
+	return d
+}
+
+// Start fetches integers in the background whenever the pool runs low.  Batches left over from
+// before are published first, since the integers left in them were discarded.
+func (p *randomOrgPool) Start() {
+	p.publishAll()
+	p.stop = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		retry := time.NewTicker(retryInterval)
+		defer retry.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-p.wake:
+			case <-retry.C:
+			}
+			p.fill()
+		}
+	}()
+}
+
+// Stop stops fetching integers and publishes every batch, since what is left in them is discarded
+func (p *randomOrgPool) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+	p.publishAll()
+}
+
+// publishAll publishes every batch which has been kept
+func (p *randomOrgPool) publishAll() {
+	if p.keep == nil {
+		return
+	}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), randomOrgTimeout)
+	defer cancelFunc()
+	p.keep.PublishAll(ctx)
+}
+
+// publish publishes the batches which have been retired, keeping any which could not be stored to
+// try again
+func (p *randomOrgPool) publish() {
+	p.mutex.Lock()
+	retired := p.retired
+	p.retired = nil
+	p.mutex.Unlock()
+	failed := make([]*signedBatch, 0)
+	for _, b := range retired {
+		if err := p.store(b, true); err != nil {
+			failed = append(failed, b)
+		}
+	}
+	p.mutex.Lock()
+	p.retired = append(p.retired, failed...)
+	p.mutex.Unlock()
+}
+
+// store keeps a batch, and whether it has been published
+func (p *randomOrgPool) store(b *signedBatch, published bool) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), randomOrgTimeout)
+	defer cancelFunc()
+	return p.keep.Store(ctx, &domains.SignedBatch{ID: b.id, Random: b.Random, Signature: b.Signature, Published: published})
+}
+
+// fill publishes the batches which have been retired, then fetches a batch of integers for each
+// range which is running low, for as long as random.org answers and the quota lasts.  Nothing is
+// fetched until there is somewhere to keep the batches, since their draws couldn't be verified.
+func (p *randomOrgPool) fill() {
+	if p.keep == nil {
+		return
+	}
+	p.publish()
+	if time.Since(p.failed) < retryInterval {
+		return
+	}
+	for _, r := range p.low() {
+		ok, err := p.affordable(r)
+		if err != nil {
+			p.failed = time.Now()
+			return
+		}
+		if !ok {
+			return
+		}
+		ctx, cancelFunc := context.WithTimeout(context.Background(), randomOrgTimeout)
+		signed, err := p.client.generateSignedIntegers(ctx, poolBatch, r.min, r.max)
+		cancelFunc()
+		if err != nil {
+			// the dice fall back until random.org answers again
+			p.failed = time.Now()
+			return
+		}
+		p.usage.BitsLeft = signed.BitsLeft
+		p.usage.RequestsLeft = signed.RequestsLeft
+		b := &signedBatch{signedIntegers: signed, id: batchID(signed.Signature)}
+		if err := p.store(b, false); err != nil {
+			// dice mustn't be drawn from a batch which can't be verified later
+			p.failed = time.Now()
+			return
+		}
+		p.mutex.Lock()
+		p.batches[r] = append(p.batches[r], b)
+		p.mutex.Unlock()
+	}
+}
+
+// low lists the ranges of dice which have too few integers left
+func (p *randomOrgPool) low() []poolRange {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ranges := make([]poolRange, 0)
+	for r, batches := range p.batches {
+		left := 0
+		for _, b := range batches {
+			left += len(b.data) - b.drawn
+		}
+		if left < poolLow {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// affordable determines whether the quota left covers a batch of integers for a range.  The
+// quota is checked with random.org when the pool starts, and again every so often once it runs out.
+func (p *randomOrgPool) affordable(r poolRange) (bool, error) {
+	bits := int64(math.Ceil(poolBatch * math.Log2(float64(r.max-r.min+1))))
+	enough := func() bool {
+		return p.usage.Status == "running" && p.usage.RequestsLeft > 0 && p.usage.BitsLeft >= bits
+	}
+	if p.usage != nil && (enough() || time.Since(p.checked) < usageInterval) {
+		return enough(), nil
+	}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), randomOrgTimeout)
+	defer cancelFunc()
+	usage, err := p.client.getUsage(ctx)
+	if err != nil {
+		return false, err
+	}
+	p.usage = usage
+	p.checked = time.Now()
+	return enough(), nil
+}
@@ -53,11 +53,24 @@ func initHandler(router chi.Router, auth *AuthService, bot *Bot, db *DatabaseSer
 	router.Get("/auth", handler.Auth)
 	router.Get("/auth/begin", handler.AuthBegin)
 	router.Get("/auth/complete", handler.AuthComplete)
+	router.Get("/campaigns", handler.Campaigns)
+	router.Post("/campaigns", handler.CreateCampaign)
+	router.Get("/campaigns/{ID}", handler.Campaign)
+	router.Post("/campaigns/{ID}/join", handler.JoinCampaign)
 	router.Post("/channels", handler.Channels)
 	router.Get("/characters", handler.Characters)
+	router.Post("/import", handler.Import)
+	router.Get("/metrics", handler.Metrics)
+	router.Get("/odds", handler.Odds)
 	router.Post("/roll", handler.Roll)
+	router.Get("/rolls", handler.Rolls)
+	router.Get("/rolls/{ID}/verify", handler.VerifyRoll)
 	router.Get("/sheets/{ID}", handler.Sheet)
 	router.Post("/sheets/{ID}", handler.Sheet)
+	router.Get("/sheets/{ID}/macros", handler.Macros)
+	router.Post("/sheets/{ID}/macros", handler.SaveMacro)
+	router.Delete("/sheets/{ID}/macros/{Name}", handler.DeleteMacro)
+	router.Post("/sheets/{ID}/macros/{Name}/roll", handler.RollMacro)
 	return handler
 }
 
@@ -34,6 +34,9 @@ var ErrNoDiscordToken = errors.New("$DISCORD_TOKEN is required")
 // ErrDatabaseInfo is thrown when the environment variables for the database aren't set
 var ErrDatabaseInfo = errors.New("$DATABASE_HOST, $DATABASE_PORT, $DATABASE_USER, $DATABASE_PASS, and $DATABASE_NAME are required")
 
+// ErrDispatch is thrown when an invalid dispatcher size is submitted
+var ErrDispatch = errors.New("$DISPATCH_WORKERS and $DISPATCH_QUEUE_SIZE must be positive integers")
+
 // ErrNodeID is thrown when an invalid node ID is submitted
 var ErrNodeID = errors.New("$NODE_ID must be an integer")
 
@@ -43,9 +46,23 @@ var ErrOAuthInfo = errors.New("$OAUTH_CLIENT_ID and $OAUTH_CLIENT_SECRET are req
 // ErrPort is thrown when an invalid port is submitted
 var ErrPort = errors.New("$PORT must be an integer")
 
+// ErrRandomSource is thrown when an unknown random source is submitted, or random.org is chosen
+// without an API key
+var ErrRandomSource = errors.New("$RANDOM_SOURCE must be crypto, seeded, or random.org, and random.org requires $RANDOM_ORG_API_KEY")
+
+// ErrRandomSeed is thrown when an invalid random seed is submitted
+var ErrRandomSeed = errors.New("$RANDOM_SEED must be an integer")
+
 // ErrNoSessionSecret is thrown when the environment variable isn't set
 var ErrNoSessionSecret = errors.New("$SESSION_SECRET is required")
 
+// The sources of random numbers which slate can roll dice with
+const (
+	RandomSourceCrypto    = "crypto"
+	RandomSourceSeeded    = "seeded"
+	RandomSourceRandomOrg = "random.org"
+)
+
 // Database holds configuration information for the database connection
 type Database struct {
 	Host string
@@ -68,10 +85,14 @@ type Settings struct {
 	CommandPrefix       string
 	Database            *Database
 	DiscordToken        string
+	DispatchQueueSize   int
+	DispatchWorkers     int
 	NodeID              int
 	OAuth               *OAuth
 	Port                int
 	RandomOrgAPIKey     string
+	RandomSeed          int64
+	RandomSource        string
 	SessionSecret       string
 }
 
@@ -93,6 +114,12 @@ func Init() (*Settings, error) {
 		return nil, err
 	}
 
+	// Initialize the command dispatcher
+	dispatchWorkers, dispatchQueueSize, err := initDispatch()
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the host
 	applicationHostname := initApplicationHostname()
 
@@ -117,6 +144,12 @@ func Init() (*Settings, error) {
 	// Initialize the random.org API key
 	randomOrgAPIKey := initRandomOrgAPIKey()
 
+	// Initialize the source of random numbers
+	randomSource, randomSeed, err := initRandomSource(randomOrgAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the SessionSecret
 	sessionSecret, err := initSessionSecret()
 	if err != nil {
@@ -129,10 +162,14 @@ func Init() (*Settings, error) {
 		CommandPrefix:       commandPrefix,
 		DiscordToken:        discordToken,
 		Database:            database,
+		DispatchQueueSize:   dispatchQueueSize,
+		DispatchWorkers:     dispatchWorkers,
 		NodeID:              nodeID,
 		OAuth:               oauth,
 		Port:                port,
 		RandomOrgAPIKey:     randomOrgAPIKey,
+		RandomSeed:          randomSeed,
+		RandomSource:        randomSource,
 		SessionSecret:       sessionSecret,
 	}
 
@@ -175,6 +212,33 @@ func initDiscordToken() (string, error) {
 	return token, nil
 }
 
+func initDispatch() (int, int, error) {
+	workers, err := positiveInt("DISPATCH_WORKERS", 8)
+	if err != nil {
+		return 0, 0, ErrDispatch
+	}
+	size, err := positiveInt("DISPATCH_QUEUE_SIZE", 64)
+	if err != nil {
+		return 0, 0, ErrDispatch
+	}
+	return workers, size, nil
+}
+
+func positiveInt(name string, def int) (int, error) {
+	str := os.Getenv(name)
+	if str == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, errors.New("must be positive")
+	}
+	return n, nil
+}
+
 func initApplicationHostname() string {
 	host := os.Getenv("APPLICATION_HOSTNAME")
 	if host == "" {
@@ -224,6 +288,34 @@ func initRandomOrgAPIKey() string {
 	return os.Getenv("RANDOM_ORG_API_KEY")
 }
 
+func initRandomSource(apiKey string) (string, int64, error) {
+	source := os.Getenv("RANDOM_SOURCE")
+	switch source {
+	case "":
+		source = RandomSourceCrypto
+		if apiKey != "" {
+			source = RandomSourceRandomOrg
+		}
+	case RandomSourceCrypto, RandomSourceSeeded:
+	case RandomSourceRandomOrg:
+		if apiKey == "" {
+			return "", 0, ErrRandomSource
+		}
+	default:
+		return "", 0, ErrRandomSource
+	}
+
+	seedstr := os.Getenv("RANDOM_SEED")
+	if seedstr == "" {
+		return source, 1, nil
+	}
+	seed, err := strconv.ParseInt(seedstr, 10, 64)
+	if err != nil {
+		return "", 0, ErrRandomSeed
+	}
+	return source, seed, nil
+}
+
 func initSessionSecret() (string, error) {
 	token := os.Getenv("SESSION_SECRET")
 	if token == "" {
@@ -191,3 +191,76 @@ func TestPort_Error(t *testing.T) {
 	// teardown
 	os.Setenv("PORT", envport)
 }
+
+func TestDispatch_Default(t *testing.T) {
+	workers, size, err := initDispatch()
+	assert.Equal(t, 8, workers)
+	assert.Equal(t, 64, size)
+	assert.Nil(t, err)
+}
+
+func TestDispatch_Error(t *testing.T) {
+	// setup
+	envworkers := os.Getenv("DISPATCH_WORKERS")
+	os.Setenv("DISPATCH_WORKERS", "0")
+
+	// run tests
+	_, _, err := initDispatch()
+	assert.Equal(t, ErrDispatch, err)
+
+	// teardown
+	os.Setenv("DISPATCH_WORKERS", envworkers)
+}
+
+func TestRandomSource_Default(t *testing.T) {
+	source, seed, err := initRandomSource("")
+	assert.Equal(t, RandomSourceCrypto, source)
+	assert.Equal(t, int64(1), seed)
+	assert.Nil(t, err)
+
+	source, _, err = initRandomSource("key")
+	assert.Equal(t, RandomSourceRandomOrg, source)
+	assert.Nil(t, err)
+}
+
+func TestRandomSource_Seeded(t *testing.T) {
+	// setup
+	envsource := os.Getenv("RANDOM_SOURCE")
+	envseed := os.Getenv("RANDOM_SEED")
+	os.Setenv("RANDOM_SOURCE", RandomSourceSeeded)
+	os.Setenv("RANDOM_SEED", "-42")
+
+	// run tests
+	source, seed, err := initRandomSource("key")
+	assert.Equal(t, RandomSourceSeeded, source)
+	assert.Equal(t, int64(-42), seed)
+	assert.Nil(t, err)
+
+	// teardown
+	os.Setenv("RANDOM_SOURCE", envsource)
+	os.Setenv("RANDOM_SEED", envseed)
+}
+
+func TestRandomSource_Error(t *testing.T) {
+	// setup
+	envsource := os.Getenv("RANDOM_SOURCE")
+	envseed := os.Getenv("RANDOM_SEED")
+
+	// run tests
+	os.Setenv("RANDOM_SOURCE", "dice")
+	_, _, err := initRandomSource("key")
+	assert.Equal(t, ErrRandomSource, err)
+
+	os.Setenv("RANDOM_SOURCE", RandomSourceRandomOrg)
+	_, _, err = initRandomSource("")
+	assert.Equal(t, ErrRandomSource, err)
+
+	os.Setenv("RANDOM_SOURCE", RandomSourceSeeded)
+	os.Setenv("RANDOM_SEED", "five")
+	_, _, err = initRandomSource("")
+	assert.Equal(t, ErrRandomSeed, err)
+
+	// teardown
+	os.Setenv("RANDOM_SOURCE", envsource)
+	os.Setenv("RANDOM_SEED", envseed)
+}
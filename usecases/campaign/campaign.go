@@ -0,0 +1,238 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package campaign
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrCampaignNotFound is thrown when a campaign could not be found by name
+var ErrCampaignNotFound = errors.New("could not find a campaign by that name")
+
+// ErrAmbiguousCampaign is thrown when a campaign was not named and more than one could be meant
+var ErrAmbiguousCampaign = errors.New("this server runs more than one campaign; please specify a name")
+
+// ErrDuplicateCampaign is thrown when a campaign is created with the name of another in the guild
+var ErrDuplicateCampaign = errors.New("this server already has a campaign by that name")
+
+// ErrInvalidCampaign is thrown when a campaign is created without a name
+var ErrInvalidCampaign = errors.New("a campaign needs a name")
+
+// ErrWrongGuild is thrown when a character from another guild is attached to a campaign
+var ErrWrongGuild = errors.New("that character is not from this campaign's server")
+
+// Create creates a new campaign in a guild, run by the player who created it
+func Create(ctx context.Context, db domains.CampaignRepository, guild, name, system, storyteller string) (*domains.Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCampaign
+	}
+	if !util.ContainsString(sheet.Systems, system) {
+		return nil, sheet.ErrInvalidSheetSystem
+	}
+	_, err := FindByName(ctx, db, guild, name)
+	switch {
+	case err == nil:
+		return nil, ErrDuplicateCampaign
+	case err != ErrCampaignNotFound:
+		return nil, err
+	}
+	c := new(domains.Campaign)
+	c.Name = name
+	c.Guild = guild
+	c.System = system
+	c.Storytellers = []string{storyteller}
+	c.Members = []string{storyteller}
+	c.Channels = []string{}
+	err = db.Store(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create a new campaign")
+	}
+	return c, nil
+}
+
+// FindByName finds a campaign in a guild by name.  If the name is empty and the guild runs only
+// one campaign, that campaign is found.
+func FindByName(ctx context.Context, db domains.CampaignRepository, guild, name string) (*domains.Campaign, error) {
+	campaigns, err := db.FindByGuild(ctx, guild)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find campaigns")
+	}
+	var found *domains.Campaign
+	for _, c := range campaigns {
+		if name != "" && !strings.EqualFold(c.Name, name) {
+			continue
+		}
+		if found != nil {
+			return nil, ErrAmbiguousCampaign
+		}
+		found = c
+	}
+	if found == nil {
+		return nil, ErrCampaignNotFound
+	}
+	return found, nil
+}
+
+// ForChannel finds the campaign a channel is linked to.  A channel which is not linked to a
+// campaign has none.
+func ForChannel(ctx context.Context, db domains.CampaignRepository, guild, channel string) (*domains.Campaign, error) {
+	if guild == "" {
+		return nil, nil
+	}
+	campaigns, err := db.FindByGuild(ctx, guild)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find campaigns")
+	}
+	for _, c := range campaigns {
+		if util.ContainsString(c.Channels, channel) {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// Find finds a campaign by name, or if no name is given, the campaign linked to the channel
+func Find(ctx context.Context, db domains.CampaignRepository, guild, channel, name string) (*domains.Campaign, error) {
+	if name == "" {
+		c, err := ForChannel(ctx, db, guild, channel)
+		if err != nil || c != nil {
+			return c, err
+		}
+	}
+	return FindByName(ctx, db, guild, name)
+}
+
+// Join adds a player to a campaign's members
+func Join(ctx context.Context, db domains.CampaignRepository, c *domains.Campaign, player string) error {
+	if util.ContainsString(c.Members, player) {
+		return nil
+	}
+	c.Members = append(c.Members, player)
+	return errors.Wrap(db.Store(ctx, c), "could not join campaign")
+}
+
+// Link links a channel to a campaign, so that commands in the channel apply to the campaign.  A
+// channel can only be linked to one campaign, so it is unlinked from any other.
+func Link(ctx context.Context, db domains.CampaignRepository, c *domains.Campaign, channel string) error {
+	previous, err := ForChannel(ctx, db, c.Guild, channel)
+	if err != nil {
+		return err
+	}
+	if previous != nil && previous.ID.String() != c.ID.String() {
+		channels := make([]string, 0, len(previous.Channels))
+		for _, ch := range previous.Channels {
+			if ch != channel {
+				channels = append(channels, ch)
+			}
+		}
+		previous.Channels = channels
+		err = db.Store(ctx, previous)
+		if err != nil {
+			return errors.Wrap(err, "could not unlink channel")
+		}
+	}
+	if !util.ContainsString(c.Channels, channel) {
+		c.Channels = append(c.Channels, channel)
+	}
+	return errors.Wrap(db.Store(ctx, c), "could not link channel")
+}
+
+// Attach attaches a character to a campaign, and makes the character's player a member
+func Attach(ctx context.Context, db domains.CampaignRepository, chars domains.CharacterRepository, c *domains.Campaign, char *domains.Character) error {
+	if char.Guild != c.Guild {
+		return ErrWrongGuild
+	}
+	char.Campaign = c.ID
+	sheet.SetChronicle(char.Sheet, c.Name)
+	err := chars.Store(ctx, char)
+	if err != nil {
+		return errors.Wrap(err, "could not attach character")
+	}
+	return Join(ctx, db, c, char.Player)
+}
+
+// Characters lists the characters attached to a campaign.  The sheets of the listed characters
+// are not loaded.
+func Characters(ctx context.Context, chars domains.CharacterRepository, c *domains.Campaign) ([]*domains.Character, error) {
+	all, err := chars.FindByGuild(ctx, c.Guild)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find characters")
+	}
+	attached := make([]*domains.Character, 0)
+	for _, char := range all {
+		if char.Campaign != nil && *char.Campaign == *c.ID {
+			attached = append(attached, char)
+		}
+	}
+	return attached, nil
+}
+
+// FindCharacter finds a character attached to a campaign by name
+func FindCharacter(ctx context.Context, chars domains.CharacterRepository, c *domains.Campaign, name string) (*domains.Character, error) {
+	attached, err := Characters(ctx, chars, c)
+	if err != nil {
+		return nil, err
+	}
+	return sheet.FindAmong(ctx, chars, attached, name)
+}
+
+// Active finds the character a player is playing in a channel: the named one of their characters,
+// or if no name is given, their character in the channel's campaign or their only character in
+// the guild
+func Active(ctx context.Context, campaigns domains.CampaignRepository, chars domains.CharacterRepository, guild, channel, player, name string) (*domains.Character, error) {
+	if name == "" {
+		c, err := ForChannel(ctx, campaigns, guild, channel)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			attached, err := Characters(ctx, chars, c)
+			if err != nil {
+				return nil, err
+			}
+			own := make([]*domains.Character, 0)
+			for _, char := range attached {
+				if char.Player == player {
+					own = append(own, char)
+				}
+			}
+			if len(own) > 0 {
+				return sheet.FindAmong(ctx, chars, own, "")
+			}
+		}
+	}
+	g, _ := strconv.ParseInt(guild, 10, 64)
+	p, _ := strconv.ParseInt(player, 10, 64)
+	return sheet.FindByName(ctx, chars, g, p, name)
+}
+
+// IsStoryteller determines whether a player runs a campaign
+func IsStoryteller(c *domains.Campaign, player string) bool {
+	return c != nil && util.ContainsString(c.Storytellers, player)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package campaign
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CampaignSuite struct {
+	suite.Suite
+}
+
+func TestCampaign(t *testing.T) {
+	suite.Run(t, new(CampaignSuite))
+}
+
+func genCampaign(id int64, name string, channels ...string) *domains.Campaign {
+	sid := snowflake.ID(id)
+	return &domains.Campaign{ID: &sid, Name: name, Guild: "1", System: "cofd2e", Channels: channels}
+}
+
+func (suite *CampaignSuite) TestCreate() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCampaignRepository(ctrl)
+	db.EXPECT().FindByGuild(ctx, "1").Return([]*domains.Campaign{genCampaign(1, "Boston")}, nil).Times(2)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	c, err := Create(ctx, db, "1", " Chicago ", "wtf2e", "7")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Chicago", c.Name)
+	assert.Equal(suite.T(), []string{"7"}, c.Storytellers)
+	assert.Equal(suite.T(), []string{"7"}, c.Members)
+
+	_, err = Create(ctx, db, "1", "boston", "wtf2e", "7")
+	assert.Equal(suite.T(), ErrDuplicateCampaign, err)
+	_, err = Create(ctx, db, "1", "Miami", "dnd5e", "7")
+	assert.Equal(suite.T(), sheet.ErrInvalidSheetSystem, err)
+	_, err = Create(ctx, db, "1", "", "wtf2e", "7")
+	assert.Equal(suite.T(), ErrInvalidCampaign, err)
+}
+
+func (suite *CampaignSuite) TestFind() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCampaignRepository(ctrl)
+	boston, chicago := genCampaign(1, "Boston", "c1"), genCampaign(2, "Chicago")
+	db.EXPECT().FindByGuild(ctx, "1").Return([]*domains.Campaign{boston, chicago}, nil).AnyTimes()
+	c, err := Find(ctx, db, "1", "c1", "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), boston, c)
+	c, err = Find(ctx, db, "1", "c1", "chicago")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), chicago, c)
+	_, err = Find(ctx, db, "1", "c2", "")
+	assert.Equal(suite.T(), ErrAmbiguousCampaign, err)
+	_, err = Find(ctx, db, "1", "c2", "Miami")
+	assert.Equal(suite.T(), ErrCampaignNotFound, err)
+}
+
+func (suite *CampaignSuite) TestLink() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCampaignRepository(ctrl)
+	boston, chicago := genCampaign(1, "Boston", "c1", "c2"), genCampaign(2, "Chicago")
+	db.EXPECT().FindByGuild(ctx, "1").Return([]*domains.Campaign{boston, chicago}, nil)
+	db.EXPECT().Store(ctx, boston).Return(nil)
+	db.EXPECT().Store(ctx, chicago).Return(nil)
+	err := Link(ctx, db, chicago, "c1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"c2"}, boston.Channels)
+	assert.Equal(suite.T(), []string{"c1"}, chicago.Channels)
+}
+
+func (suite *CampaignSuite) TestAttach() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCampaignRepository(ctrl)
+	chars := domains.NewMockCharacterRepository(ctrl)
+	c := genCampaign(1, "Boston")
+	sh := sheet.NewCofD2e()
+	char := &domains.Character{Name: "Ada", Guild: "1", Player: "7", Sheet: sh}
+	chars.EXPECT().Store(ctx, char).Return(nil)
+	db.EXPECT().Store(ctx, c).Return(nil)
+	err := Attach(ctx, db, chars, c, char)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), c.ID, char.Campaign)
+	assert.Equal(suite.T(), "Boston", sh.Chronicle)
+	assert.Equal(suite.T(), []string{"7"}, c.Members)
+
+	err = Attach(ctx, db, chars, c, &domains.Character{Guild: "2"})
+	assert.Equal(suite.T(), ErrWrongGuild, err)
+}
+
+func (suite *CampaignSuite) TestActive() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCampaignRepository(ctrl)
+	chars := domains.NewMockCharacterRepository(ctrl)
+	c := genCampaign(1, "Boston", "100")
+	ada, bea, cat := snowflake.ID(41), snowflake.ID(42), snowflake.ID(43)
+	guild := []*domains.Character{
+		{ID: &ada, Name: "Ada", Guild: "1", Player: "7"},
+		{ID: &bea, Name: "Bea", Guild: "1", Player: "7", Campaign: c.ID},
+		{ID: &cat, Name: "Cat", Guild: "1", Player: "8", Campaign: c.ID},
+	}
+	found := &domains.Character{ID: &bea, Name: "Bea"}
+	db.EXPECT().FindByGuild(ctx, "1").Return([]*domains.Campaign{c}, nil).Times(2)
+	chars.EXPECT().FindByGuild(ctx, "1").Return(guild, nil)
+	chars.EXPECT().FindByID(ctx, "42").Return(found, nil)
+	char, err := Active(ctx, db, chars, "1", "100", "7", "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), found, char)
+
+	// outside of the campaign, the player has more than one character
+	chars.EXPECT().FindByPlayer(ctx, "7").Return(guild[:2], nil)
+	_, err = Active(ctx, db, chars, "1", "200", "7", "")
+	assert.Equal(suite.T(), sheet.ErrAmbiguousCharacter, err)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// The keys of the guild settings which can be configured
+const (
+	KeyPrefix       = "prefix"
+	KeyRollSystem   = "roll-system"
+	KeySheetSystem  = "sheet-system"
+	KeyVerbose      = "verbose"
+	KeyInlineRolls  = "inline-rolls"
+	KeyRollChannels = "roll-channels"
+	KeyStorytellers = "storytellers"
+)
+
+// Keys lists the guild settings which can be configured, in display order
+var Keys = []string{KeyPrefix, KeyRollSystem, KeySheetSystem, KeyVerbose, KeyInlineRolls, KeyRollChannels, KeyStorytellers}
+
+// The defaults for guild settings which have not been configured.  The prefix defaults to the
+// bot's $COMMAND_PREFIX.
+const (
+	DefaultRollSystem  = "d20"
+	DefaultSheetSystem = "wtf2e"
+)
+
+// ErrUnknownSetting is thrown when a setting which does not exist is configured
+var ErrUnknownSetting = errors.New("setting must be one of: " + strings.Join(Keys, ", "))
+
+// ErrInvalidSetting is thrown when a setting is configured with an invalid value
+var ErrInvalidSetting = errors.New("invalid value for setting")
+
+var channelRegexp = regexp.MustCompile(`^(?:<#)?(\d+)>?$`)
+var roleRegexp = regexp.MustCompile(`^(?:<@&)?(\d+)>?$`)
+
+// Get retrieves the settings for a guild, with defaults for anything not configured.  Direct
+// messages have no guild, and always use the defaults.
+func Get(ctx context.Context, db domains.GuildSettingsRepository, guild string) (*domains.GuildSettings, error) {
+	s := &domains.GuildSettings{Guild: guild}
+	if guild != "" {
+		var err error
+		s, err = db.FindByGuild(ctx, guild)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not find guild settings")
+		}
+	}
+	if s.RollSystem == "" {
+		s.RollSystem = DefaultRollSystem
+	}
+	if s.SheetSystem == "" {
+		s.SheetSystem = DefaultSheetSystem
+	}
+	return s, nil
+}
+
+// Set configures a single setting for a guild.  Setting a key without values resets it to the
+// default.
+func Set(ctx context.Context, db domains.GuildSettingsRepository, guild, key string, values []string) (*domains.GuildSettings, error) {
+	s, err := db.FindByGuild(ctx, guild)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find guild settings")
+	}
+	value := strings.Join(values, " ")
+	switch key {
+	case KeyPrefix:
+		if strings.ContainsAny(value, " \t\n") || len(value) > 5 {
+			return nil, errors.Wrap(ErrInvalidSetting, "the prefix must be at most 5 characters, without spaces")
+		}
+		s.Prefix = value
+	case KeyRollSystem:
+		if value != "" && !util.ContainsString(roll.Systems, value) {
+			return nil, errors.Wrap(ErrInvalidSetting, "the roll system must be one of: "+strings.Join(roll.Systems, ", "))
+		}
+		s.RollSystem = value
+	case KeySheetSystem:
+		if value != "" && !util.ContainsString(sheet.Systems, value) {
+			return nil, errors.Wrap(ErrInvalidSetting, "the sheet system must be one of: "+strings.Join(sheet.Systems, ", "))
+		}
+		s.SheetSystem = value
+	case KeyVerbose:
+		s.Verbose = false
+		if value != "" {
+			s.Verbose, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidSetting, "verbose must be true or false")
+			}
+		}
+	case KeyInlineRolls:
+		s.InlineRolls = false
+		if value != "" {
+			s.InlineRolls, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidSetting, "inline-rolls must be true or false")
+			}
+		}
+	case KeyRollChannels:
+		channels := make([]string, 0, len(values))
+		for _, v := range values {
+			match := channelRegexp.FindStringSubmatch(v)
+			if match == nil {
+				return nil, errors.Wrap(ErrInvalidSetting, fmt.Sprintf("%s is not a channel", v))
+			}
+			channels = append(channels, match[1])
+		}
+		s.RollChannels = channels
+	case KeyStorytellers:
+		roles := make([]string, 0, len(values))
+		for _, v := range values {
+			match := roleRegexp.FindStringSubmatch(v)
+			if match == nil {
+				return nil, errors.Wrap(ErrInvalidSetting, fmt.Sprintf("%s is not a role", v))
+			}
+			roles = append(roles, match[1])
+		}
+		s.Storytellers = roles
+	default:
+		return nil, ErrUnknownSetting
+	}
+	err = db.Store(ctx, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not store guild settings")
+	}
+	return s, nil
+}
+
+// RollAllowed determines whether dice may be rolled in a channel
+func RollAllowed(s *domains.GuildSettings, channel string) bool {
+	return len(s.RollChannels) == 0 || util.ContainsString(s.RollChannels, channel)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+}
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (suite *ConfigSuite) TestGet() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockGuildSettingsRepository(ctrl)
+	db.EXPECT().FindByGuild(ctx, "1").Return(&domains.GuildSettings{Guild: "1", Prefix: "!", RollSystem: "cofd"}, nil)
+	s, err := Get(ctx, db, "1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "!", s.Prefix)
+	assert.Equal(suite.T(), "cofd", s.RollSystem)
+	assert.Equal(suite.T(), DefaultSheetSystem, s.SheetSystem)
+
+	// direct messages use the defaults without a lookup
+	s, err = Get(ctx, db, "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), DefaultRollSystem, s.RollSystem)
+}
+
+func (suite *ConfigSuite) TestSet() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockGuildSettingsRepository(ctrl)
+	db.EXPECT().FindByGuild(ctx, "1").Return(&domains.GuildSettings{Guild: "1"}, nil).AnyTimes()
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	s, err := Set(ctx, db, "1", KeyRollChannels, []string{"<#123>", "456"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"123", "456"}, s.RollChannels)
+	assert.True(suite.T(), RollAllowed(s, "123"))
+	assert.False(suite.T(), RollAllowed(s, "789"))
+
+	s, err = Set(ctx, db, "1", KeyStorytellers, []string{"<@&42>"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"42"}, s.Storytellers)
+
+	s, err = Set(ctx, db, "1", KeyVerbose, []string{"true"})
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), s.Verbose)
+
+	s, err = Set(ctx, db, "1", KeyInlineRolls, []string{"on"})
+	assert.Equal(suite.T(), ErrInvalidSetting, errors.Cause(err))
+	s, err = Set(ctx, db, "1", KeyInlineRolls, []string{"true"})
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), s.InlineRolls)
+
+	s, err = Set(ctx, db, "1", KeyRollSystem, []string{"cofd"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "cofd", s.RollSystem)
+
+	_, err = Set(ctx, db, "1", KeyRollSystem, []string{"dnd"})
+	assert.Equal(suite.T(), ErrInvalidSetting, errors.Cause(err))
+	_, err = Set(ctx, db, "1", KeyPrefix, []string{"two", "words"})
+	assert.Equal(suite.T(), ErrInvalidSetting, errors.Cause(err))
+	_, err = Set(ctx, db, "1", "locale", []string{"en"})
+	assert.Equal(suite.T(), ErrUnknownSetting, err)
+	_, err = Set(ctx, db, "1", "color", []string{"red"})
+	assert.Equal(suite.T(), ErrUnknownSetting, err)
+}
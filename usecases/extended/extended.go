@@ -0,0 +1,206 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package extended tracks Chronicles of Darkness extended actions, whose successes are accumulated
+// over several rolls.  Each roll after the first loses a die, the rolls are limited to the size of
+// the pool, a dramatic failure loses every success so far, and an exceptional success earns a
+// benefit of the player's choice.
+package extended
+
+import (
+	"context"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrActionActive is thrown when a player starts an extended action while rolling another
+var ErrActionActive = errors.New("you are already rolling an extended action here; finish or abandon it first")
+
+// ErrNoAction is thrown when a player who is not rolling an extended action tries to continue one
+var ErrNoAction = errors.New("you are not rolling an extended action here")
+
+// ErrTarget is thrown when an extended action is started without a target
+var ErrTarget = errors.New("an extended action needs a target of at least one success")
+
+// ErrPool is thrown when an extended action is started with a negative pool or limit
+var ErrPool = errors.New("the dice pool and roll limit of an extended action cannot be negative")
+
+// ErrChoosing is thrown when an action is rolled before the benefit of an exceptional success is chosen
+var ErrChoosing = errors.New("choose time, target or exceptional for your exceptional success before rolling again")
+
+// ErrNotChoosing is thrown when a benefit is chosen without an exceptional success
+var ErrNotChoosing = errors.New("there is no exceptional success to choose a benefit for")
+
+// ErrChoice is thrown when an unknown benefit is chosen
+var ErrChoice = errors.New("the benefit of an exceptional success must be time, target or exceptional")
+
+// ErrNoSkill is thrown when the target of an action started without a skill is chosen to be lowered
+var ErrNoSkill = errors.New("the target can only be lowered for an action started with -skill")
+
+// ErrAgain is thrown when an extended action is started with an again which would never stop rerolling
+var ErrAgain = errors.New("an extended action needs an again of 2 or more")
+
+// The benefits which may be chosen for an exceptional success
+const (
+	ChooseTime        = "time"        // each roll takes a quarter less time
+	ChooseTarget      = "target"      // the target is lowered by the character's skill
+	ChooseExceptional = "exceptional" // the action completes as an exceptional success
+)
+
+// Choices lists the benefits which may be chosen for an exceptional success
+var Choices = []string{ChooseTime, ChooseTarget, ChooseExceptional}
+
+// exceptionalSuccesses is the number of successes on one roll which make it an exceptional success
+const exceptionalSuccesses = 5
+
+// Start starts an extended action.  Its rolls are limited to its pool unless a limit is given.
+func Start(ctx context.Context, db domains.ExtendedActionRepository, a *domains.ExtendedAction) error {
+	if a.Target < 1 {
+		return ErrTarget
+	}
+	if a.Pool < 0 || a.Limit < 0 {
+		return ErrPool
+	}
+	if a.Again != 0 && a.Again < 2 {
+		return ErrAgain
+	}
+	active, err := db.FindActive(ctx, a.Channel, a.Player)
+	if err != nil {
+		return errors.Wrap(err, "could not find extended action")
+	}
+	if active != nil {
+		return ErrActionActive
+	}
+	if a.Limit == 0 {
+		a.Limit = util.Max(a.Pool, 1)
+	}
+	if a.Again == 0 {
+		a.Again = 10
+	}
+	a.Status = domains.ExtendedActive
+	err = db.Store(ctx, a)
+	if err != nil {
+		return errors.Wrap(err, "could not start extended action")
+	}
+	return nil
+}
+
+// Find finds the extended action a player is rolling in a channel
+func Find(ctx context.Context, db domains.ExtendedActionRepository, channel, player string) (*domains.ExtendedAction, error) {
+	a, err := db.FindActive(ctx, channel, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find extended action")
+	}
+	if a == nil {
+		return nil, ErrNoAction
+	}
+	return a, nil
+}
+
+// Dice is the size of the next roll of an action, which loses a die for each roll already made
+func Dice(a *domains.ExtendedAction, modifier int) int {
+	return util.Max(a.Pool-a.Rolls+modifier, 0)
+}
+
+// Roller creates the roll system for the next roll of an action
+func Roller(a *domains.ExtendedAction, modifier int) (*roll.CofDRollSystem, error) {
+	if a.Choosing {
+		return nil, ErrChoosing
+	}
+	rs := new(roll.CofDRollSystem)
+	rs.Again = a.Again
+	rs.Rote = a.Rote
+	rs.Exceptional = exceptionalSuccesses
+	rs.Dice = Dice(a, modifier)
+	return rs, nil
+}
+
+// Apply accumulates the successes of a roll of an action, and completes the action once it reaches
+// its target or runs out of rolls
+func Apply(ctx context.Context, db domains.ExtendedActionRepository, a *domains.ExtendedAction, rs *roll.CofDRollSystem) error {
+	a.Rolls++
+	switch rs.Outcome() {
+	case roll.OutcomeDramaticFailure:
+		a.Successes = 0
+	case roll.OutcomeExceptional:
+		a.Successes += rs.Results.Successes
+		a.Choosing = true
+	default:
+		a.Successes += rs.Results.Successes
+	}
+	settle(a)
+	return store(ctx, db, a)
+}
+
+// Choose applies the benefit chosen for an exceptional success
+func Choose(ctx context.Context, db domains.ExtendedActionRepository, a *domains.ExtendedAction, choice string) error {
+	if !a.Choosing {
+		return ErrNotChoosing
+	}
+	switch choice {
+	case ChooseTime:
+		a.Interval = a.Interval * 3 / 4
+	case ChooseTarget:
+		if a.Skill == 0 {
+			return ErrNoSkill
+		}
+		a.Target = util.Max(a.Target-a.Skill, 1)
+	case ChooseExceptional:
+		a.Exceptional = true
+	default:
+		return ErrChoice
+	}
+	a.Choosing = false
+	settle(a)
+	return store(ctx, db, a)
+}
+
+// Abandon gives up on an action
+func Abandon(ctx context.Context, db domains.ExtendedActionRepository, a *domains.ExtendedAction) error {
+	a.Status = domains.ExtendedAbandoned
+	return store(ctx, db, a)
+}
+
+// settle completes an action which has reached its target, or fails one which has run out of rolls.
+// An exceptional success which completes the action makes it exceptional, since no later roll is left
+// to benefit; otherwise the action waits for the benefit to be chosen, which may lower its target.
+func settle(a *domains.ExtendedAction) {
+	switch {
+	case a.Successes >= a.Target:
+		if a.Choosing {
+			a.Exceptional = true
+			a.Choosing = false
+		}
+		a.Status = domains.ExtendedSucceeded
+	case a.Rolls >= a.Limit && !a.Choosing:
+		a.Status = domains.ExtendedFailed
+	}
+}
+
+func store(ctx context.Context, db domains.ExtendedActionRepository, a *domains.ExtendedAction) error {
+	err := db.Store(ctx, a)
+	if err != nil {
+		return errors.Wrap(err, "could not store extended action")
+	}
+	return nil
+}
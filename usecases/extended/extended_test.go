@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package extended
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExtendedSuite struct {
+	suite.Suite
+}
+
+func TestExtended(t *testing.T) {
+	suite.Run(t, new(ExtendedSuite))
+}
+
+func genAction() *domains.ExtendedAction {
+	return &domains.ExtendedAction{
+		Channel:  "c1",
+		Player:   "7",
+		Name:     "Research the ritual",
+		Target:   10,
+		Pool:     6,
+		Interval: 40 * time.Minute,
+		Status:   domains.ExtendedActive,
+	}
+}
+
+// rolled rolls the next roll of an action with the given dice, in order
+func (suite *ExtendedSuite) rolled(a *domains.ExtendedAction, modifier int, dice ...[]int64) *roll.CofDRollSystem {
+	rs, err := Roller(a, modifier)
+	assert.Nil(suite.T(), err)
+	rs.SetRand(func(times int, min, max int64) ([]int64, error) {
+		d := dice[0]
+		dice = dice[1:]
+		assert.Len(suite.T(), d, times)
+		return d, nil
+	})
+	assert.Nil(suite.T(), rs.Roll(context.Background(), nil))
+	return rs
+}
+
+func (suite *ExtendedSuite) TestStart() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().FindActive(ctx, "c1", "7").Return(nil, nil)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	a := genAction()
+	assert.Nil(suite.T(), Start(ctx, db, a))
+	assert.Equal(suite.T(), domains.ExtendedActive, a.Status)
+	assert.Equal(suite.T(), 6, a.Limit)
+	assert.Equal(suite.T(), int64(10), a.Again)
+
+	db.EXPECT().FindActive(ctx, "c1", "7").Return(a, nil)
+	assert.Equal(suite.T(), ErrActionActive, Start(ctx, db, genAction()))
+
+	invalid := genAction()
+	invalid.Target = 0
+	assert.Equal(suite.T(), ErrTarget, Start(ctx, db, invalid))
+	invalid = genAction()
+	invalid.Pool = -1
+	assert.Equal(suite.T(), ErrPool, Start(ctx, db, invalid))
+	invalid = genAction()
+	invalid.Again = 1
+	assert.Equal(suite.T(), ErrAgain, Start(ctx, db, invalid))
+}
+
+func (suite *ExtendedSuite) TestFind() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().FindActive(ctx, "c1", "7").Return(nil, nil)
+	_, err := Find(ctx, db, "c1", "7")
+	assert.Equal(suite.T(), ErrNoAction, err)
+}
+
+func (suite *ExtendedSuite) TestApply() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	a := genAction()
+	a.Limit = 3
+
+	// each roll loses a die
+	rs := suite.rolled(a, 0, []int64{8, 9, 1, 2, 3, 4})
+	assert.Nil(suite.T(), Apply(ctx, db, a, rs))
+	assert.Equal(suite.T(), 2, a.Successes)
+	assert.Equal(suite.T(), 5, Dice(a, 0))
+	assert.Equal(suite.T(), 3, Dice(a, -2))
+
+	rs = suite.rolled(a, 1, []int64{8, 8, 8, 2, 3, 4})
+	assert.Nil(suite.T(), Apply(ctx, db, a, rs))
+	assert.Equal(suite.T(), 5, a.Successes)
+	assert.Equal(suite.T(), domains.ExtendedActive, a.Status)
+
+	// the rolls run out
+	rs = suite.rolled(a, 0, []int64{1, 2, 3, 4})
+	assert.Nil(suite.T(), Apply(ctx, db, a, rs))
+	assert.Equal(suite.T(), domains.ExtendedFailed, a.Status)
+}
+
+func (suite *ExtendedSuite) TestApplyDramaticFailure() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	a := genAction()
+	a.Pool = 1
+	a.Limit = 4
+	rs := suite.rolled(a, 0, []int64{9})
+	Apply(ctx, db, a, rs)
+	assert.Equal(suite.T(), 1, a.Successes)
+
+	// the second roll is a chance die, which loses everything on a 1
+	assert.Equal(suite.T(), 0, Dice(a, 0))
+	rs = suite.rolled(a, 0, []int64{1})
+	Apply(ctx, db, a, rs)
+	assert.Equal(suite.T(), 0, a.Successes)
+	assert.Equal(suite.T(), domains.ExtendedActive, a.Status)
+}
+
+func (suite *ExtendedSuite) TestExceptional() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	a := genAction()
+	a.Target = 20
+	a.Skill = 3
+	rs := suite.rolled(a, 0, []int64{8, 8, 8, 8, 8, 1})
+	Apply(ctx, db, a, rs)
+	assert.True(suite.T(), a.Choosing)
+	_, err := Roller(a, 0)
+	assert.Equal(suite.T(), ErrChoosing, err)
+
+	assert.Equal(suite.T(), ErrChoice, Choose(ctx, db, a, "luck"))
+	assert.Nil(suite.T(), Choose(ctx, db, a, ChooseTime))
+	assert.Equal(suite.T(), 30*time.Minute, a.Interval)
+	assert.False(suite.T(), a.Choosing)
+	assert.Equal(suite.T(), ErrNotChoosing, Choose(ctx, db, a, ChooseTime))
+
+	rs = suite.rolled(a, 0, []int64{8, 8, 8, 8, 8})
+	Apply(ctx, db, a, rs)
+	assert.Nil(suite.T(), Choose(ctx, db, a, ChooseTarget))
+	assert.Equal(suite.T(), 17, a.Target)
+
+	// an exceptional success which completes the action makes it exceptional
+	rs = suite.rolled(a, 3, []int64{8, 8, 8, 8, 8, 8, 8})
+	Apply(ctx, db, a, rs)
+	assert.Equal(suite.T(), domains.ExtendedSucceeded, a.Status)
+	assert.True(suite.T(), a.Exceptional)
+	assert.False(suite.T(), a.Choosing)
+}
+
+func (suite *ExtendedSuite) TestChooseTarget() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	a := genAction()
+	a.Target = 7
+	a.Limit = 1
+	a.Choosing = true
+	a.Successes = 5
+	a.Rolls = 1
+	assert.Equal(suite.T(), ErrNoSkill, Choose(ctx, db, a, ChooseTarget))
+
+	// lowering the target can complete an action which has run out of rolls
+	a.Skill = 2
+	assert.Nil(suite.T(), Choose(ctx, db, a, ChooseTarget))
+	assert.Equal(suite.T(), domains.ExtendedSucceeded, a.Status)
+	assert.False(suite.T(), a.Exceptional)
+}
+
+func (suite *ExtendedSuite) TestAbandon() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockExtendedActionRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	a := genAction()
+	assert.Nil(suite.T(), Abandon(ctx, db, a))
+	assert.Equal(suite.T(), domains.ExtendedAbandoned, a.Status)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package fair makes rolls provably fair.  While a fair session runs in a channel, dice are derived
+// from a secret seed whose hash was published when the session started; once the session ends and
+// the seed is revealed, anyone can recompute the dice.  Dice from random.org are signed instead.
+package fair
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrSessionActive is thrown when a fair session is started in a channel which already has one
+var ErrSessionActive = errors.New("a fair session is already running in this channel")
+
+// ErrNoSession is thrown when a fair session is ended in a channel which has none
+var ErrNoSession = errors.New("no fair session is running in this channel")
+
+// seedSize is the number of random bytes in a seed
+const seedSize = 32
+
+// Start starts a fair session in a channel, with a new secret seed
+func Start(ctx context.Context, db domains.FairSessionRepository, guild, channel string) (*domains.FairSession, error) {
+	active, err := db.FindActive(ctx, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find fair session")
+	}
+	if active != nil {
+		return nil, ErrSessionActive
+	}
+	seed := make([]byte, seedSize)
+	_, err = rand.Read(seed)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate seed")
+	}
+	s := new(domains.FairSession)
+	s.Guild = guild
+	s.Channel = channel
+	s.Seed = hex.EncodeToString(seed)
+	s.Hash = Hash(s.Seed)
+	err = db.Store(ctx, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not start fair session")
+	}
+	return s, nil
+}
+
+// End ends the fair session in a channel, which reveals its seed
+func End(ctx context.Context, db domains.FairSessionRepository, channel string) (*domains.FairSession, error) {
+	s, err := db.FindActive(ctx, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find fair session")
+	}
+	if s == nil {
+		return nil, ErrNoSession
+	}
+	now := time.Now()
+	s.EndedAt = &now
+	err = db.Store(ctx, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not end fair session")
+	}
+	return s, nil
+}
+
+// Hash hashes a seed, so that it can be published without revealing the seed
+func Hash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
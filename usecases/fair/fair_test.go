@@ -0,0 +1,274 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package fair
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FairSuite struct {
+	suite.Suite
+}
+
+func TestFair(t *testing.T) {
+	suite.Run(t, new(FairSuite))
+}
+
+type fakeVerifier bool
+
+func (f fakeVerifier) VerifyDraw(ctx context.Context, d *domains.RollDraw) (bool, error) {
+	return bool(f), nil
+}
+
+func genSession(ended bool) *domains.FairSession {
+	id := snowflake.ID(7)
+	s := &domains.FairSession{ID: &id, Channel: "c1", Seed: "00ff"}
+	s.Hash = Hash(s.Seed)
+	if ended {
+		now := time.Now()
+		s.EndedAt = &now
+	}
+	return s
+}
+
+// genRoll rolls dice for a session the way a roll system would, with a reroll
+func genRoll(s *domains.FairSession) *domains.Roll {
+	id := snowflake.ID(42)
+	rec := NewSessionRecorder(s, id.String())
+	dice, _ := rec.Rand(3, 1, 10)
+	reroll, _ := rec.Rand(1, 1, 10)
+	return &domains.Roll{ID: &id, Channel: "c1", Dice: append(dice, reroll...), Proof: rec.Proof()}
+}
+
+func (suite *FairSuite) TestStart() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockFairSessionRepository(ctrl)
+	db.EXPECT().FindActive(ctx, "c1").Return(nil, nil)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	s, err := Start(ctx, db, "1", "c1")
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), s.Seed, 2*seedSize)
+	assert.Equal(suite.T(), Hash(s.Seed), s.Hash)
+
+	db.EXPECT().FindActive(ctx, "c1").Return(s, nil)
+	_, err = Start(ctx, db, "1", "c1")
+	assert.Equal(suite.T(), ErrSessionActive, err)
+}
+
+func (suite *FairSuite) TestEnd() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockFairSessionRepository(ctrl)
+	s := genSession(false)
+	db.EXPECT().FindActive(ctx, "c1").Return(s, nil)
+	db.EXPECT().Store(ctx, s).Return(nil)
+	ended, err := End(ctx, db, "c1")
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), ended.EndedAt)
+
+	db.EXPECT().FindActive(ctx, "c2").Return(nil, nil)
+	_, err = End(ctx, db, "c2")
+	assert.Equal(suite.T(), ErrNoSession, err)
+}
+
+func (suite *FairSuite) TestStream() {
+	a, _ := NewStream("seed", "1").Rand(20, 1, 6)
+	b, _ := NewStream("seed", "1").Rand(20, 1, 6)
+	c, _ := NewStream("seed", "2").Rand(20, 1, 6)
+	assert.Equal(suite.T(), a, b)
+	assert.NotEqual(suite.T(), a, c)
+
+	dice, err := NewStream("seed", "1").Rand(1000, 5, 7)
+	assert.Nil(suite.T(), err)
+	seen := make(map[int64]bool)
+	for _, die := range dice {
+		assert.True(suite.T(), die >= 5 && die <= 7)
+		seen[die] = true
+	}
+	assert.Len(suite.T(), seen, 3)
+
+	_, err = NewStream("seed", "1").Rand(1, 6, 1)
+	assert.Equal(suite.T(), ErrInvalidRange, err)
+}
+
+func (suite *FairSuite) TestRecorder() {
+	s := genSession(false)
+	r := genRoll(s)
+	assert.Equal(suite.T(), domains.ProofCommitReveal, r.Proof.Method)
+	assert.Equal(suite.T(), "7", r.Proof.Session)
+	assert.Equal(suite.T(), "42", r.Proof.Nonce)
+	assert.Len(suite.T(), r.Proof.Draws, 2)
+
+	unsigned := NewRecorder(func(times int, min, max int64) (*domains.RollDraw, error) {
+		return &domains.RollDraw{Times: times, Min: min, Max: max, Dice: []int64{1}}, nil
+	})
+	unsigned.Rand(1, 1, 6)
+	assert.Nil(suite.T(), unsigned.Proof())
+
+	signed := NewRecorder(func(times int, min, max int64) (*domains.RollDraw, error) {
+		return &domains.RollDraw{Times: times, Min: min, Max: max, Dice: []int64{1}, Signature: "sig"}, nil
+	})
+	signed.Rand(1, 1, 6)
+	assert.Equal(suite.T(), domains.ProofRandomOrg, signed.Proof().Method)
+}
+
+func (suite *FairSuite) TestDrawnFrom() {
+	sources := []string{domains.RandomOrg, domains.RandomCrypto, domains.RandomOrg}
+	rec := NewRecorder(func(times int, min, max int64) (*domains.RollDraw, error) {
+		d := &domains.RollDraw{Times: times, Min: min, Max: max, Dice: []int64{1}, Source: sources[0]}
+		sources = sources[1:]
+		return d, nil
+	})
+	assert.Empty(suite.T(), rec.DrawnFrom())
+	rec.Rand(1, 1, 6)
+	rec.Rand(1, 1, 6)
+	rec.Rand(1, 1, 6)
+	assert.Equal(suite.T(), "random.org, crypto", rec.DrawnFrom())
+
+	rec = NewSessionRecorder(genSession(false), "42")
+	rec.Rand(2, 1, 10)
+	assert.Equal(suite.T(), domains.RandomFairSession, rec.DrawnFrom())
+}
+
+func (suite *FairSuite) TestVerifySession() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockFairSessionRepository(ctrl)
+	running, ended := genSession(false), genSession(true)
+	r := genRoll(ended)
+
+	db.EXPECT().FindByID(ctx, "7").Return(running, nil)
+	v, err := Verify(ctx, r, db, nil, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Pending, v.Status)
+	assert.Empty(suite.T(), v.Seed)
+
+	db.EXPECT().FindByID(ctx, "7").Return(ended, nil).Times(3)
+	v, err = Verify(ctx, r, db, nil, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Verified, v.Status)
+	assert.Equal(suite.T(), ended.Seed, v.Seed)
+
+	// a nonce from another roll does not verify
+	other := *r
+	other.Proof = &domains.RollProof{Method: r.Proof.Method, Session: "7", Nonce: "43", Draws: r.Proof.Draws}
+	v, _ = Verify(ctx, &other, db, nil, nil)
+	assert.Equal(suite.T(), Failed, v.Status)
+
+	// nor does a draw which was changed
+	r.Proof.Draws[0].Dice[0] = r.Proof.Draws[0].Dice[0]%10 + 1
+	r.Dice[0] = r.Proof.Draws[0].Dice[0]
+	v, _ = Verify(ctx, r, db, nil, nil)
+	assert.Equal(suite.T(), Failed, v.Status)
+
+	// nor dice which were not drawn
+	r.Dice[0] = 11
+	v, _ = Verify(ctx, r, db, nil, nil)
+	assert.Equal(suite.T(), Failed, v.Status)
+}
+
+func (suite *FairSuite) TestVerifyRandomOrg() {
+	ctx := context.Background()
+	id := snowflake.ID(42)
+	random, _ := json.Marshal(&signedRandom{N: 2, Min: 1, Max: 10, Data: []int64{3, 9}})
+	r := &domains.Roll{ID: &id, Dice: []int64{9, 3}, Proof: &domains.RollProof{
+		Method: domains.ProofRandomOrg,
+		Draws:  []*domains.RollDraw{{Times: 2, Min: 1, Max: 10, Dice: []int64{3, 9}, Random: random, Signature: "sig"}},
+	}}
+	v, err := Verify(ctx, r, nil, nil, fakeVerifier(true))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Verified, v.Status)
+	v, _ = Verify(ctx, r, nil, nil, fakeVerifier(false))
+	assert.Equal(suite.T(), Failed, v.Status)
+
+	r.Proof.Draws[0].Dice = []int64{3, 10}
+	r.Dice = []int64{3, 10}
+	v, _ = Verify(ctx, r, nil, nil, fakeVerifier(true))
+	assert.Equal(suite.T(), Failed, v.Status)
+}
+
+func (suite *FairSuite) TestVerifyRandomOrgBatch() {
+	ctx := context.Background()
+	id := snowflake.ID(42)
+	random, _ := json.Marshal(&signedRandom{N: 5, Min: 1, Max: 10, Data: []int64{3, 9, 4, 7, 1}})
+	draw := &domains.RollDraw{Times: 2, Min: 1, Max: 10, Dice: []int64{4, 7}, Random: random, Signature: "sig", Offset: 2}
+	r := &domains.Roll{ID: &id, Dice: []int64{4, 7}, Proof: &domains.RollProof{
+		Method: domains.ProofRandomOrg,
+		Draws:  []*domains.RollDraw{draw},
+	}}
+	v, err := Verify(ctx, r, nil, nil, fakeVerifier(true))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Verified, v.Status)
+
+	// the dice must be at the offset they claim
+	draw.Offset = 1
+	v, _ = Verify(ctx, r, nil, nil, fakeVerifier(true))
+	assert.Equal(suite.T(), Failed, v.Status)
+
+	// and within the batch
+	draw.Offset = 4
+	v, _ = Verify(ctx, r, nil, nil, fakeVerifier(true))
+	assert.Equal(suite.T(), Failed, v.Status)
+}
+
+func (suite *FairSuite) TestVerifyKeptBatch() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	batches := domains.NewMockSignedBatchRepository(ctrl)
+	id := snowflake.ID(42)
+	random, _ := json.Marshal(&signedRandom{N: 5, Min: 1, Max: 10, Data: []int64{3, 9, 4, 7, 1}})
+	batch := &domains.SignedBatch{ID: "b", Random: random, Signature: "sig"}
+	r := &domains.Roll{ID: &id, Dice: []int64{4, 7}, Proof: &domains.RollProof{
+		Method: domains.ProofRandomOrg,
+		Draws:  []*domains.RollDraw{{Times: 2, Min: 1, Max: 10, Dice: []int64{4, 7}, Batch: "b", Offset: 2}},
+	}}
+
+	// the batch is withheld until all of its dice are drawn
+	batches.EXPECT().FindByID(ctx, "b").Return(batch, nil)
+	v, err := Verify(ctx, r, nil, batches, fakeVerifier(true))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Pending, v.Status)
+
+	published := *batch
+	published.Published = true
+	batches.EXPECT().FindByID(ctx, "b").Return(&published, nil)
+	v, err = Verify(ctx, r, nil, batches, fakeVerifier(true))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Verified, v.Status)
+	assert.Empty(suite.T(), r.Proof.Draws[0].Random)
+
+	batches.EXPECT().FindByID(ctx, "b").Return(nil, nil)
+	v, _ = Verify(ctx, r, nil, batches, fakeVerifier(true))
+	assert.Equal(suite.T(), Failed, v.Status)
+}
+
+func (suite *FairSuite) TestVerifyUnverifiable() {
+	id := snowflake.ID(42)
+	v, err := Verify(context.Background(), &domains.Roll{ID: &id}, nil, nil, nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Unverifiable, v.Status)
+}
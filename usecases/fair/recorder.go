@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package fair
+
+import (
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+)
+
+// A Draw draws dice between min and max, along with any proof its source offers
+type Draw func(times int, min, max int64) (*domains.RollDraw, error)
+
+// A Recorder draws the dice for a roll and records each draw, so that the roll can be verified.
+type Recorder struct {
+	draw  Draw
+	proof *domains.RollProof
+}
+
+// NewRecorder records the dice drawn from a random source
+func NewRecorder(draw Draw) *Recorder {
+	rec := new(Recorder)
+	rec.draw = draw
+	rec.proof = new(domains.RollProof)
+	return rec
+}
+
+// NewSessionRecorder records the dice derived from the seed of a fair session
+func NewSessionRecorder(s *domains.FairSession, nonce string) *Recorder {
+	stream := NewStream(s.Seed, nonce)
+	rec := NewRecorder(func(times int, min, max int64) (*domains.RollDraw, error) {
+		dice, err := stream.Rand(times, min, max)
+		if err != nil {
+			return nil, err
+		}
+		return &domains.RollDraw{Times: times, Min: min, Max: max, Dice: dice, Source: domains.RandomFairSession}, nil
+	})
+	rec.proof.Method = domains.ProofCommitReveal
+	rec.proof.Session = s.ID.String()
+	rec.proof.Nonce = nonce
+	return rec
+}
+
+// Rand draws dice between min and max, and records the draw
+func (rec *Recorder) Rand(times int, min, max int64) ([]int64, error) {
+	d, err := rec.draw(times, min, max)
+	if err != nil {
+		return nil, err
+	}
+	rec.proof.Draws = append(rec.proof.Draws, d)
+	return d.Dice, nil
+}
+
+// Proof describes how the recorded dice were drawn.  Dice from a source which offers no proof
+// have none.
+func (rec *Recorder) Proof() *domains.RollProof {
+	if rec.proof.Method != "" {
+		return rec.proof
+	}
+	if len(rec.proof.Draws) == 0 {
+		return nil
+	}
+	for _, d := range rec.proof.Draws {
+		if d.Batch == "" && d.Signature == "" {
+			return nil
+		}
+	}
+	rec.proof.Method = domains.ProofRandomOrg
+	return rec.proof
+}
+
+// DrawnFrom lists the sources the recorded dice were drawn from, in the order they were first used
+func (rec *Recorder) DrawnFrom() string {
+	sources := make([]string, 0, 1)
+	seen := make(map[string]bool)
+	for _, d := range rec.proof.Draws {
+		if d.Source == "" || seen[d.Source] {
+			continue
+		}
+		seen[d.Source] = true
+		sources = append(sources, d.Source)
+	}
+	return strings.Join(sources, ", ")
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package fair
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/kkragenbrink/slate/usecases/roll"
+)
+
+// ErrInvalidRange is thrown when dice are drawn with a maximum below their minimum
+var ErrInvalidRange = roll.ErrInvalidRange
+
+// A Stream derives dice from a seed and a nonce.  Each block of the stream is the HMAC-SHA256 of
+// the nonce and the block's index, keyed with the seed, so the same seed and nonce always give the
+// same dice.
+type Stream struct {
+	seed    []byte
+	nonce   string
+	counter uint64
+	buf     []byte
+}
+
+// NewStream creates a new stream of dice for a seed and nonce
+func NewStream(seed, nonce string) *Stream {
+	s := new(Stream)
+	s.seed = []byte(seed)
+	s.nonce = nonce
+	return s
+}
+
+// Rand draws dice between min and max from the stream.  Values which would bias the dice towards
+// the low end of the range are rejected and drawn again.
+func (s *Stream) Rand(times int, min, max int64) ([]int64, error) {
+	return roll.Uniform(times, min, max, s.next)
+}
+
+// next takes the next 64 bits from the stream
+func (s *Stream) next() (uint64, error) {
+	if len(s.buf) < 8 {
+		mac := hmac.New(sha256.New, s.seed)
+		fmt.Fprintf(mac, "%s:%d", s.nonce, s.counter)
+		s.counter++
+		s.buf = append(s.buf, mac.Sum(nil)...)
+	}
+	v := binary.BigEndian.Uint64(s.buf[:8])
+	s.buf = s.buf[8:]
+	return v, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package fair
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// The results of verifying a roll
+const (
+	Verified     = "verified"
+	Failed       = "failed"
+	Pending      = "pending" // the seed of the fair session, or the batch random.org signed, is not yet revealed
+	Unverifiable = "unverifiable"
+)
+
+// A Verification is the result of checking a roll against its proof
+type Verification struct {
+	Roll   string `json:"roll"`
+	Method string `json:"method,omitempty"`
+	Status string `json:"status"`
+	Hash   string `json:"hash,omitempty"`
+	Seed   string `json:"seed,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// A SignatureVerifier checks with random.org that it signed a draw
+type SignatureVerifier interface {
+	VerifyDraw(ctx context.Context, d *domains.RollDraw) (bool, error)
+}
+
+// signedRandom is the part of a random.org random object which describes the dice it signed
+type signedRandom struct {
+	N    int     `json:"n"`
+	Min  int64   `json:"min"`
+	Max  int64   `json:"max"`
+	Data []int64 `json:"data"`
+}
+
+// Verify checks a roll against its proof.  Dice derived from a fair session are recomputed from
+// the session's seed, once it has been revealed; dice from random.org have their signatures checked,
+// once the batches they were drawn from have been published.
+func Verify(ctx context.Context, r *domains.Roll, sessions domains.FairSessionRepository, batches domains.SignedBatchRepository, signatures SignatureVerifier) (*Verification, error) {
+	v := &Verification{Roll: r.ID.String()}
+	if r.Proof == nil {
+		v.Status = Unverifiable
+		v.Reason = "the dice were drawn from a source which offers no proof"
+		return v, nil
+	}
+	v.Method = r.Proof.Method
+	if !sameDice(r.Proof.Draws, r.Dice) {
+		return v.fail("the dice of the roll are not the dice which were drawn"), nil
+	}
+	switch r.Proof.Method {
+	case domains.ProofCommitReveal:
+		return verifySession(ctx, v, r, sessions)
+	case domains.ProofRandomOrg:
+		return verifySignatures(ctx, v, r, batches, signatures)
+	}
+	v.Status = Unverifiable
+	v.Reason = fmt.Sprintf("%s proofs cannot be verified", r.Proof.Method)
+	return v, nil
+}
+
+// verifySession recomputes the dice of a roll from the seed of its fair session
+func verifySession(ctx context.Context, v *Verification, r *domains.Roll, sessions domains.FairSessionRepository) (*Verification, error) {
+	s, err := sessions.FindByID(ctx, r.Proof.Session)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find fair session")
+	}
+	v.Hash = s.Hash
+	if s.EndedAt == nil {
+		v.Status = Pending
+		v.Reason = "the seed will be revealed when the fair session ends"
+		return v, nil
+	}
+	v.Seed = s.Seed
+	switch {
+	case Hash(s.Seed) != s.Hash:
+		return v.fail("the seed does not match the hash published when the session started"), nil
+	case s.Channel != r.Channel:
+		return v.fail("the roll was not made in the fair session's channel"), nil
+	case r.Proof.Nonce != r.ID.String():
+		return v.fail("the dice were not derived for this roll"), nil
+	}
+	stream := NewStream(s.Seed, r.Proof.Nonce)
+	for i, d := range r.Proof.Draws {
+		dice, err := stream.Rand(d.Times, d.Min, d.Max)
+		if err != nil || !equal(dice, d.Dice) {
+			return v.fail(fmt.Sprintf("draw %d does not match the seed", i+1)), nil
+		}
+	}
+	v.Status = Verified
+	return v, nil
+}
+
+// verifySignatures checks that random.org signed each draw of a roll
+func verifySignatures(ctx context.Context, v *Verification, r *domains.Roll, batches domains.SignedBatchRepository, signatures SignatureVerifier) (*Verification, error) {
+	for i, d := range r.Proof.Draws {
+		if d.Batch != "" {
+			b, err := batches.FindByID(ctx, d.Batch)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not find signed batch")
+			}
+			if b == nil {
+				return v.fail(fmt.Sprintf("the batch draw %d was taken from was never signed", i+1)), nil
+			}
+			if !b.Published {
+				v.Status = Pending
+				v.Reason = "the batch random.org signed the dice in is published once all of its dice are drawn"
+				return v, nil
+			}
+			signedDraw := *d
+			signedDraw.Random = b.Random
+			signedDraw.Signature = b.Signature
+			d = &signedDraw
+		}
+		var random signedRandom
+		err := json.Unmarshal(d.Random, &random)
+		if err != nil || !signed(&random, d) {
+			return v.fail(fmt.Sprintf("draw %d does not match what random.org signed", i+1)), nil
+		}
+		ok, err := signatures.VerifyDraw(ctx, d)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not verify signature")
+		}
+		if !ok {
+			return v.fail(fmt.Sprintf("random.org did not sign draw %d", i+1)), nil
+		}
+	}
+	v.Status = Verified
+	return v, nil
+}
+
+// signed determines whether the dice of a draw are the integers random.org signed at its offset.
+// random.org signs integers in batches, and a batch may be shared by several draws.
+func signed(random *signedRandom, d *domains.RollDraw) bool {
+	if random.Min != d.Min || random.Max != d.Max || random.N != len(random.Data) {
+		return false
+	}
+	if d.Offset < 0 || d.Times < 0 || d.Offset+d.Times > len(random.Data) {
+		return false
+	}
+	return equal(random.Data[d.Offset:d.Offset+d.Times], d.Dice)
+}
+
+func (v *Verification) fail(reason string) *Verification {
+	v.Status = Failed
+	v.Reason = reason
+	return v
+}
+
+// sameDice determines whether the dice of a roll are the dice which were drawn for it, in any order
+func sameDice(draws []*domains.RollDraw, dice []int64) bool {
+	drawn := make([]int64, 0, len(dice))
+	for _, d := range draws {
+		drawn = append(drawn, d.Dice...)
+	}
+	rolled := append([]int64(nil), dice...)
+	sort.Slice(drawn, func(i, j int) bool { return drawn[i] < drawn[j] })
+	sort.Slice(rolled, func(i, j int) bool { return rolled[i] < rolled[j] })
+	return equal(drawn, rolled)
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package initiative keeps the turn order of combats.  Each character rolls initiative as they join,
+// and the order is kept from the highest score to the lowest, with ties going to the higher
+// modifier.  A character may delay their turn and act later in the round, which keeps their new
+// place in the order for the rounds after.
+package initiative
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrNoInitiative is thrown when a channel without a combat is asked for its initiative
+var ErrNoInitiative = errors.New("no combat is running in this channel; join the initiative to start one")
+
+// ErrNoEntries is thrown when turns are taken before anyone has joined the initiative
+var ErrNoEntries = errors.New("no one has joined the initiative")
+
+// ErrDuplicateEntry is thrown when a name is added to the initiative twice
+var ErrDuplicateEntry = errors.New("that name is already in the initiative")
+
+// ErrEntryNotFound is thrown when a name which is not in the initiative is delayed, acts or is removed
+var ErrEntryNotFound = errors.New("that name is not in the initiative")
+
+// ErrNotStarted is thrown when the current turn is delayed before the first turn is taken
+var ErrNotStarted = errors.New("the combat has not started; take the first turn with next")
+
+// ErrNotDelayed is thrown when a character who has not delayed their turn tries to act
+var ErrNotDelayed = errors.New("only a character who delayed their turn can act out of order")
+
+// Find finds the initiative of the combat running in a channel
+func Find(ctx context.Context, db domains.InitiativeRepository, channel string) (*domains.Initiative, error) {
+	i, err := db.FindActive(ctx, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find initiative")
+	}
+	if i == nil {
+		return nil, ErrNoInitiative
+	}
+	return i, nil
+}
+
+// Roll rolls initiative: a d10 in Chronicles of Darkness or a d20 otherwise, plus the modifier
+func Roll(system string, modifier int, dice func(times int, min, max int64) ([]int64, error)) (int, error) {
+	sides := int64(20)
+	if system == "cofd" {
+		sides = 10
+	}
+	rolled, err := dice(1, 1, sides)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not roll initiative")
+	}
+	return int(rolled[0]) + modifier, nil
+}
+
+// Join adds an entry to the initiative of a channel, starting a combat there if none is running
+func Join(ctx context.Context, db domains.InitiativeRepository, guild, channel, system string, e *domains.InitiativeEntry) (*domains.Initiative, error) {
+	i, err := db.FindActive(ctx, channel)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find initiative")
+	}
+	if i == nil {
+		i = new(domains.Initiative)
+		i.Guild = guild
+		i.Channel = channel
+		i.System = system
+		i.Entries = make([]*domains.InitiativeEntry, 0)
+	}
+	if _, err := find(i, e.Name); err == nil {
+		return nil, ErrDuplicateEntry
+	}
+	insert(i, e)
+	return i, store(ctx, db, i)
+}
+
+// Current is the entry whose turn it is, if the combat has started
+func Current(i *domains.Initiative) *domains.InitiativeEntry {
+	if i.Round == 0 || i.Turn >= len(i.Entries) {
+		return nil
+	}
+	return i.Entries[i.Turn]
+}
+
+// Entry finds the named entry, ignoring case, or the current entry if none is named
+func Entry(i *domains.Initiative, name string) (*domains.InitiativeEntry, error) {
+	if name == "" {
+		return Current(i), nil
+	}
+	n, err := find(i, name)
+	if err != nil {
+		return nil, err
+	}
+	return i.Entries[n], nil
+}
+
+// Next ends the current turn and starts the next one, skipping those who are delaying.  The first
+// turn of the combat is started if it has not been.
+func Next(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative) (*domains.InitiativeEntry, error) {
+	if len(i.Entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	advance(i)
+	return Current(i), store(ctx, db, i)
+}
+
+// Delay delays the turn of the named entry, or the current turn if none is named
+func Delay(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative, name string) (*domains.InitiativeEntry, error) {
+	if i.Round == 0 {
+		return nil, ErrNotStarted
+	}
+	n := i.Turn
+	if name != "" {
+		var err error
+		n, err = find(i, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	e := i.Entries[n]
+	e.Delayed = true
+	if n == i.Turn {
+		advance(i)
+	}
+	return e, store(ctx, db, i)
+}
+
+// Act takes the delayed turn of the named entry now, once the current turn ends.  The entry keeps
+// its new place in the order for the rounds after.
+func Act(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative, name string) (*domains.InitiativeEntry, error) {
+	n, err := find(i, name)
+	if err != nil {
+		return nil, err
+	}
+	e := i.Entries[n]
+	if !e.Delayed {
+		return nil, ErrNotDelayed
+	}
+	remove(i, n)
+	e.Delayed = false
+	e.Score = i.Entries[i.Turn].Score
+	i.Turn++
+	i.Entries = append(i.Entries[:i.Turn], append([]*domains.InitiativeEntry{e}, i.Entries[i.Turn:]...)...)
+	return e, store(ctx, db, i)
+}
+
+// Remove takes the named entry out of the initiative.  If it was their turn, the next turn starts.
+func Remove(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative, name string) (*domains.InitiativeEntry, error) {
+	n, err := find(i, name)
+	if err != nil {
+		return nil, err
+	}
+	e := i.Entries[n]
+	current := i.Round > 0 && n == i.Turn
+	remove(i, n)
+	if current {
+		i.Turn--
+		advance(i)
+	}
+	return e, store(ctx, db, i)
+}
+
+// Pin records the message which shows the initiative, so that it can be updated in place
+func Pin(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative, message string) error {
+	i.Message = message
+	return store(ctx, db, i)
+}
+
+// End ends the combat in a channel
+func End(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative) error {
+	now := time.Now()
+	i.EndedAt = &now
+	return store(ctx, db, i)
+}
+
+// insert adds an entry to its place in the order, after any with a higher score or the same score
+// and a higher modifier
+func insert(i *domains.Initiative, e *domains.InitiativeEntry) {
+	n := 0
+	for n < len(i.Entries) {
+		x := i.Entries[n]
+		if e.Score > x.Score || (e.Score == x.Score && e.Modifier > x.Modifier) {
+			break
+		}
+		n++
+	}
+	i.Entries = append(i.Entries[:n], append([]*domains.InitiativeEntry{e}, i.Entries[n:]...)...)
+	if i.Round > 0 && n <= i.Turn {
+		i.Turn++
+	}
+}
+
+// remove takes an entry out of the order, keeping the turn with the same entry where it can
+func remove(i *domains.Initiative, n int) {
+	i.Entries = append(i.Entries[:n], i.Entries[n+1:]...)
+	if n < i.Turn {
+		i.Turn--
+	}
+}
+
+// advance moves the turn to the next entry who is not delaying.  Delayed turns which are not taken
+// by the end of the round are lost.
+func advance(i *domains.Initiative) {
+	if len(i.Entries) == 0 {
+		i.Turn = 0
+		return
+	}
+	if i.Round == 0 {
+		i.Round = 1
+		i.Turn = -1
+	}
+	for {
+		i.Turn++
+		if i.Turn >= len(i.Entries) {
+			i.Round++
+			i.Turn = 0
+			for _, e := range i.Entries {
+				e.Delayed = false
+			}
+		}
+		if !i.Entries[i.Turn].Delayed {
+			return
+		}
+	}
+}
+
+// find finds the index of an entry by name, ignoring case
+func find(i *domains.Initiative, name string) (int, error) {
+	for n, e := range i.Entries {
+		if strings.EqualFold(e.Name, name) {
+			return n, nil
+		}
+	}
+	return 0, ErrEntryNotFound
+}
+
+func store(ctx context.Context, db domains.InitiativeRepository, i *domains.Initiative) error {
+	err := db.Store(ctx, i)
+	if err != nil {
+		return errors.Wrap(err, "could not store initiative")
+	}
+	return nil
+}
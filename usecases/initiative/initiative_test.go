@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package initiative
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type InitiativeSuite struct {
+	suite.Suite
+}
+
+func TestInitiative(t *testing.T) {
+	suite.Run(t, new(InitiativeSuite))
+}
+
+func genInitiative(round, turn int, names ...string) *domains.Initiative {
+	i := &domains.Initiative{Channel: "100", System: "cofd", Round: round, Turn: turn}
+	for n, name := range names {
+		i.Entries = append(i.Entries, &domains.InitiativeEntry{Name: name, Score: 20 - n})
+	}
+	return i
+}
+
+func order(i *domains.Initiative) []string {
+	names := make([]string, 0, len(i.Entries))
+	for _, e := range i.Entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func (suite *InitiativeSuite) TestRoll() {
+	var sides int64
+	dice := func(times int, min, max int64) ([]int64, error) {
+		sides = max
+		return []int64{7}, nil
+	}
+	score, err := Roll("cofd", 5, dice)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 12, score)
+	assert.Equal(suite.T(), int64(10), sides)
+	_, err = Roll("d20", 2, dice)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(20), sides)
+}
+
+func (suite *InitiativeSuite) TestJoin() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	db.EXPECT().FindActive(ctx, "100").Return(nil, nil)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	i, err := Join(ctx, db, "1", "100", "cofd", &domains.InitiativeEntry{Name: "Ada", Score: 9, Modifier: 4})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "cofd", i.System)
+	assert.Equal(suite.T(), []string{"Ada"}, order(i))
+
+	db.EXPECT().FindActive(ctx, "100").Return(i, nil).Times(3)
+	db.EXPECT().Store(ctx, i).Return(nil).Times(2)
+	_, err = Join(ctx, db, "1", "100", "cofd", &domains.InitiativeEntry{Name: "Bea", Score: 9, Modifier: 5})
+	assert.Nil(suite.T(), err)
+	_, err = Join(ctx, db, "1", "100", "cofd", &domains.InitiativeEntry{Name: "Cat", Score: 12})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"Cat", "Bea", "Ada"}, order(i))
+	_, err = Join(ctx, db, "1", "100", "cofd", &domains.InitiativeEntry{Name: "ada"})
+	assert.Equal(suite.T(), ErrDuplicateEntry, err)
+}
+
+func (suite *InitiativeSuite) TestJoinDuringCombat() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	i := genInitiative(1, 1, "Ada", "Bea")
+	db.EXPECT().FindActive(ctx, "100").Return(i, nil)
+	db.EXPECT().Store(ctx, i).Return(nil)
+	_, err := Join(ctx, db, "1", "100", "cofd", &domains.InitiativeEntry{Name: "Cat", Score: 30})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bea", Current(i).Name)
+}
+
+func (suite *InitiativeSuite) TestEntry() {
+	i := genInitiative(0, 0, "Ada", "Bea")
+	e, err := Entry(i, "")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), e)
+	i = genInitiative(1, 1, "Ada", "Bea")
+	e, err = Entry(i, "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bea", e.Name)
+	e, err = Entry(i, "ada")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", e.Name)
+	_, err = Entry(i, "Cat")
+	assert.Equal(suite.T(), ErrEntryNotFound, err)
+}
+
+func (suite *InitiativeSuite) TestNext() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	i := genInitiative(0, 0, "Ada", "Bea", "Cat")
+	assert.Nil(suite.T(), Current(i))
+	e, err := Next(ctx, db, i)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", e.Name)
+	assert.Equal(suite.T(), 1, i.Round)
+	i.Entries[1].Delayed = true
+	e, _ = Next(ctx, db, i)
+	assert.Equal(suite.T(), "Cat", e.Name)
+	e, _ = Next(ctx, db, i)
+	assert.Equal(suite.T(), "Ada", e.Name)
+	assert.Equal(suite.T(), 2, i.Round)
+	assert.False(suite.T(), i.Entries[1].Delayed)
+
+	_, err = Next(ctx, db, genInitiative(0, 0))
+	assert.Equal(suite.T(), ErrNoEntries, err)
+}
+
+func (suite *InitiativeSuite) TestDelayAndAct() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	_, err := Delay(ctx, db, genInitiative(0, 0, "Ada"), "")
+	assert.Equal(suite.T(), ErrNotStarted, err)
+
+	i := genInitiative(1, 0, "Ada", "Bea", "Cat")
+	e, err := Delay(ctx, db, i, "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", e.Name)
+	assert.Equal(suite.T(), "Bea", Current(i).Name)
+	_, err = Act(ctx, db, i, "Cat")
+	assert.Equal(suite.T(), ErrNotDelayed, err)
+	e, err = Act(ctx, db, i, "ada")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", Current(i).Name)
+	assert.Equal(suite.T(), []string{"Bea", "Ada", "Cat"}, order(i))
+	assert.Equal(suite.T(), 19, e.Score)
+	_, err = Act(ctx, db, i, "Dan")
+	assert.Equal(suite.T(), ErrEntryNotFound, err)
+}
+
+func (suite *InitiativeSuite) TestRemove() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).AnyTimes()
+	i := genInitiative(1, 1, "Ada", "Bea", "Cat")
+	_, err := Remove(ctx, db, i, "Ada")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bea", Current(i).Name)
+	_, err = Remove(ctx, db, i, "Cat")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bea", Current(i).Name)
+	i = genInitiative(1, 2, "Ada", "Bea", "Cat")
+	_, err = Remove(ctx, db, i, "Cat")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", Current(i).Name)
+	assert.Equal(suite.T(), 2, i.Round)
+}
+
+func (suite *InitiativeSuite) TestPin() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	i := genInitiative(0, 0, "Ada")
+	db.EXPECT().Store(ctx, i).Return(nil)
+	assert.Nil(suite.T(), Pin(ctx, db, i, "m1"))
+	assert.Equal(suite.T(), "m1", i.Message)
+}
+
+func (suite *InitiativeSuite) TestEnd() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockInitiativeRepository(ctrl)
+	i := genInitiative(1, 0, "Ada")
+	db.EXPECT().Store(ctx, i).Return(nil)
+	assert.Nil(suite.T(), End(ctx, db, i))
+	assert.NotNil(suite.T(), i.EndedAt)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package macro keeps the rolls players save to make again by name.  A macro may be saved for all
+// of a player's characters or for one, whose macro is found first.  Macros may have parameters,
+// written {mod} or with a default as {mod=0}, which are given when the macro is rolled.
+package macro
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrMacroName is thrown when a macro is saved without a name, or with more than one word
+var ErrMacroName = errors.New("a macro needs a name of one word, such as attack")
+
+// ErrMacroRoll is thrown when a macro is saved without a roll
+var ErrMacroRoll = errors.New("a macro needs a roll to save, such as \"-system=cofd strength+brawl\"")
+
+// ErrMacroNotFound is thrown when a macro is not found by name
+var ErrMacroNotFound = errors.New("you have no macro by that name")
+
+// ErrMissingParameter is thrown when a macro is rolled without a parameter which has no default
+var ErrMissingParameter = errors.New("the macro needs a value for a parameter")
+
+// parameterPattern matches the parameters of a macro, such as {mod} or {mod=0}
+var parameterPattern = regexp.MustCompile(`\{(\w+)(?:=([^}]*))?\}`)
+
+// Save saves a macro for a player, for one character if char is not nil, replacing any macro of
+// the same name
+func Save(ctx context.Context, db domains.MacroRepository, player string, char *snowflake.ID, name, roll string) (*domains.Macro, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(strings.Fields(name)) > 1 {
+		return nil, ErrMacroName
+	}
+	roll = strings.TrimSpace(roll)
+	if roll == "" {
+		return nil, ErrMacroRoll
+	}
+	macros, err := db.FindByPlayer(ctx, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find macros")
+	}
+	m := find(macros, char, name)
+	if m == nil {
+		m = new(domains.Macro)
+		m.Player = player
+		m.Character = char
+	}
+	m.Name = name
+	m.Roll = roll
+	err = db.Store(ctx, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not save macro")
+	}
+	return m, nil
+}
+
+// List finds the macros a player may roll: those for every character, and those for the given
+// character
+func List(ctx context.Context, db domains.MacroRepository, player string, char *snowflake.ID) ([]*domains.Macro, error) {
+	macros, err := db.FindByPlayer(ctx, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find macros")
+	}
+	usable := make([]*domains.Macro, 0, len(macros))
+	for _, m := range macros {
+		if m.Character == nil || (char != nil && *m.Character == *char) {
+			usable = append(usable, m)
+		}
+	}
+	return usable, nil
+}
+
+// Find finds a macro by name, preferring the character's own macro to one for every character
+func Find(ctx context.Context, db domains.MacroRepository, player string, char *snowflake.ID, name string) (*domains.Macro, error) {
+	macros, err := db.FindByPlayer(ctx, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find macros")
+	}
+	if char != nil {
+		if m := find(macros, char, name); m != nil {
+			return m, nil
+		}
+	}
+	if m := find(macros, nil, name); m != nil {
+		return m, nil
+	}
+	return nil, ErrMacroNotFound
+}
+
+// Delete deletes a macro of a player, for one character if char is not nil
+func Delete(ctx context.Context, db domains.MacroRepository, player string, char *snowflake.ID, name string) (*domains.Macro, error) {
+	macros, err := db.FindByPlayer(ctx, player)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find macros")
+	}
+	m := find(macros, char, strings.TrimSpace(name))
+	if m == nil {
+		return nil, ErrMacroNotFound
+	}
+	err = db.Delete(ctx, m.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not delete macro")
+	}
+	return m, nil
+}
+
+// Parameters lists the names of the parameters of a macro, in the order they first appear
+func Parameters(m *domains.Macro) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range parameterPattern.FindAllStringSubmatch(m.Roll, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Expand fills in the parameters of a macro and splits it into the fields of a roll.  Arguments
+// written name=value set the named parameter, and the others set the parameters without a default
+// in order; any left over are added to the end of the roll, as with -secret.
+func Expand(m *domains.Macro, args []string) ([]string, error) {
+	params := Parameters(m)
+	required := make([]string, 0)
+	for _, match := range parameterPattern.FindAllStringSubmatch(m.Roll, -1) {
+		if !strings.Contains(match[0], "=") && !util.ContainsString(required, match[1]) {
+			required = append(required, match[1])
+		}
+	}
+	values := make(map[string]string)
+	positional := make([]string, 0)
+	for _, arg := range args {
+		pieces := strings.SplitN(arg, "=", 2)
+		if len(pieces) == 2 && util.ContainsString(params, pieces[0]) {
+			values[pieces[0]] = pieces[1]
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	extra := make([]string, 0)
+	for _, arg := range positional {
+		for len(required) > 0 {
+			if _, ok := values[required[0]]; !ok {
+				break
+			}
+			required = required[1:]
+		}
+		if len(required) == 0 {
+			extra = append(extra, arg)
+			continue
+		}
+		values[required[0]] = arg
+	}
+	var missing string
+	roll := parameterPattern.ReplaceAllStringFunc(m.Roll, func(param string) string {
+		match := parameterPattern.FindStringSubmatch(param)
+		if value, ok := values[match[1]]; ok {
+			return value
+		}
+		if strings.Contains(param, "=") {
+			return match[2]
+		}
+		if missing == "" {
+			missing = match[1]
+		}
+		return param
+	})
+	if missing != "" {
+		return nil, errors.Wrapf(ErrMissingParameter, "{%s}", missing)
+	}
+	return append(strings.Fields(roll), extra...), nil
+}
+
+// find finds a macro among others by name, for a character or, if char is nil, for every character
+func find(macros []*domains.Macro, char *snowflake.ID, name string) *domains.Macro {
+	for _, m := range macros {
+		if !strings.EqualFold(m.Name, name) {
+			continue
+		}
+		if (char == nil && m.Character == nil) || (char != nil && m.Character != nil && *char == *m.Character) {
+			return m
+		}
+	}
+	return nil
+}
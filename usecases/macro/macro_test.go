@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package macro
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/roll"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MacroSuite struct {
+	suite.Suite
+}
+
+func TestMacro(t *testing.T) {
+	suite.Run(t, new(MacroSuite))
+}
+
+func id(n int64) *snowflake.ID {
+	sid := snowflake.ID(n)
+	return &sid
+}
+
+func genMacros() []*domains.Macro {
+	return []*domains.Macro{
+		{ID: id(1), Player: "9", Name: "attack", Roll: "-system=cofd strength+brawl"},
+		{ID: id(2), Player: "9", Character: id(50), Name: "Attack", Roll: "-system=cofd strength+weaponry+{mod=0}"},
+		{ID: id(3), Player: "9", Character: id(60), Name: "dodge", Roll: "-system=cofd dexterity+athletics"},
+	}
+}
+
+func (suite *MacroSuite) TestSave() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMacroRepository(ctrl)
+	db.EXPECT().FindByPlayer(ctx, "9").Return(genMacros(), nil).Times(2)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).Times(2)
+	m, err := Save(ctx, db, "9", id(50), "attack", " -system=cofd 5 ")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), *id(2), *m.ID)
+	assert.Equal(suite.T(), "-system=cofd 5", m.Roll)
+	m, err = Save(ctx, db, "9", id(60), "attack", "1d20+5")
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), m.ID)
+	assert.Equal(suite.T(), *id(60), *m.Character)
+	_, err = Save(ctx, db, "9", nil, "big attack", "1d20")
+	assert.Equal(suite.T(), ErrMacroName, err)
+	_, err = Save(ctx, db, "9", nil, "attack", " ")
+	assert.Equal(suite.T(), ErrMacroRoll, err)
+}
+
+func (suite *MacroSuite) TestFind() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMacroRepository(ctrl)
+	db.EXPECT().FindByPlayer(ctx, "9").Return(genMacros(), nil).AnyTimes()
+	m, err := Find(ctx, db, "9", id(50), "ATTACK")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), *id(2), *m.ID)
+	m, err = Find(ctx, db, "9", id(60), "attack")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), *id(1), *m.ID)
+	_, err = Find(ctx, db, "9", nil, "dodge")
+	assert.Equal(suite.T(), ErrMacroNotFound, err)
+	macros, err := List(ctx, db, "9", id(60))
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), macros, 2)
+}
+
+func (suite *MacroSuite) TestDelete() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMacroRepository(ctrl)
+	db.EXPECT().FindByPlayer(ctx, "9").Return(genMacros(), nil).Times(2)
+	db.EXPECT().Delete(ctx, "3").Return(nil)
+	m, err := Delete(ctx, db, "9", id(60), "dodge")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "dodge", m.Name)
+	_, err = Delete(ctx, db, "9", nil, "dodge")
+	assert.Equal(suite.T(), ErrMacroNotFound, err)
+}
+
+func (suite *MacroSuite) TestParameters() {
+	m := &domains.Macro{Roll: "-again={again=10} strength+{skill}+{mod=0}-{skill}"}
+	assert.Equal(suite.T(), []string{"again", "skill", "mod"}, Parameters(m))
+}
+
+func (suite *MacroSuite) TestExpand() {
+	m := &domains.Macro{Roll: "-system=cofd -again={again=10} strength+weaponry+{mod}"}
+	fields, err := Expand(m, []string{"2"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"-system=cofd", "-again=10", "strength+weaponry+2"}, fields)
+	fields, err = Expand(m, []string{"again=8", "-1", "-rote"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"-system=cofd", "-again=8", "strength+weaponry+-1", "-rote"}, fields)
+	fields, err = Expand(m, []string{"mod=2", "-secret"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"-system=cofd", "-again=10", "strength+weaponry+2", "-secret"}, fields)
+	_, err = Expand(m, nil)
+	assert.Equal(suite.T(), ErrMissingParameter, errors.Cause(err))
+}
+
+// TestReadmeMacro saves and rolls the macro the README offers, the way the bot does
+func (suite *MacroSuite) TestReadmeMacro() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMacroRepository(ctrl)
+	var saved *domains.Macro
+	db.EXPECT().FindByPlayer(ctx, "9").Return(nil, nil)
+	db.EXPECT().Store(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, m *domains.Macro) error {
+		saved = m
+		return nil
+	})
+	_, err := Save(ctx, db, "9", nil, "attack", "-system=cofd strength+weaponry+{mod=0}")
+	assert.Nil(suite.T(), err)
+
+	db.EXPECT().FindByPlayer(ctx, "9").Return([]*domains.Macro{saved}, nil)
+	m, err := Find(ctx, db, "9", nil, "attack")
+	assert.Nil(suite.T(), err)
+	fields, err := Expand(m, []string{"mod=-1"})
+	assert.Nil(suite.T(), err)
+
+	rs, err := roll.NewRoller("cofd", nil)
+	assert.Nil(suite.T(), err)
+	fs := flag.NewFlagSet("roll", flag.ContinueOnError)
+	rs.Flags(fs)
+	assert.Nil(suite.T(), fs.Parse(fields))
+	pool := strings.Join(fs.Args(), "+")
+	assert.True(suite.T(), sheet.NamesTraits(pool))
+	sh := sheet.NewCofD2e()
+	sh.Strength = 3
+	sh.Weaponry.Dots = 2
+	dice, _, err := sheet.SheetPool(sh, pool)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, dice)
+
+	rs.SetRand(func(times int, min, max int64) ([]int64, error) {
+		dice := make([]int64, times)
+		for i := range dice {
+			dice[i] = 5
+		}
+		return dice, nil
+	})
+	assert.Nil(suite.T(), rs.Roll(ctx, []string{"4"}))
+	assert.Len(suite.T(), rs.Rolled(), 4)
+}
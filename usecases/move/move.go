@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package move keeps the Powered by the Apocalypse moves a campaign's storytellers define, and the
+// forward and ongoing modifiers each character carries into their rolls.  Forward is spent on the
+// character's next roll; ongoing lasts until it is cleared.
+package move
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrMoveName is thrown when a move is defined without a name
+var ErrMoveName = errors.New("a move needs a name")
+
+// ErrMoveNotFound is thrown when a move is not found by name
+var ErrMoveNotFound = errors.New("move not found")
+
+// Define adds a move to a campaign, or replaces the text of the campaign's move by the same name.
+// Whether an existing move was replaced is returned.
+func Define(ctx context.Context, db domains.MoveRepository, c *domains.Campaign, m *domains.Move) (bool, error) {
+	m.Name = strings.TrimSpace(m.Name)
+	if m.Name == "" {
+		return false, ErrMoveName
+	}
+	moves, err := List(ctx, db, c)
+	if err != nil {
+		return false, err
+	}
+	existing := findMove(moves, m.Name)
+	if existing != nil {
+		m.ID = existing.ID
+	}
+	m.Campaign = c.ID
+	err = db.Store(ctx, m)
+	if err != nil {
+		return false, errors.Wrap(err, "could not define move")
+	}
+	return existing != nil, nil
+}
+
+// List finds the moves of a campaign
+func List(ctx context.Context, db domains.MoveRepository, c *domains.Campaign) ([]*domains.Move, error) {
+	moves, err := db.FindByCampaign(ctx, c.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find moves")
+	}
+	return moves, nil
+}
+
+// Find finds a move of a campaign by name
+func Find(ctx context.Context, db domains.MoveRepository, c *domains.Campaign, name string) (*domains.Move, error) {
+	moves, err := List(ctx, db, c)
+	if err != nil {
+		return nil, err
+	}
+	m := findMove(moves, name)
+	if m == nil {
+		return nil, ErrMoveNotFound
+	}
+	return m, nil
+}
+
+// Remove removes a move from a campaign
+func Remove(ctx context.Context, db domains.MoveRepository, c *domains.Campaign, name string) (*domains.Move, error) {
+	m, err := Find(ctx, db, c, name)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Delete(ctx, m.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not remove move")
+	}
+	return m, nil
+}
+
+// findMove finds a move by name, ignoring case
+func findMove(moves []*domains.Move, name string) *domains.Move {
+	name = strings.TrimSpace(name)
+	for _, m := range moves {
+		if strings.EqualFold(m.Name, name) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Modifiers finds the forward and ongoing modifiers of a character
+func Modifiers(ctx context.Context, db domains.RollModifiersRepository, char *domains.Character) (*domains.RollModifiers, error) {
+	m, err := db.FindByCharacter(ctx, char.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find roll modifiers")
+	}
+	return m, nil
+}
+
+// Adjust adds to the forward and ongoing modifiers of a character, which may be negative
+func Adjust(ctx context.Context, db domains.RollModifiersRepository, char *domains.Character, forward, ongoing int) (*domains.RollModifiers, error) {
+	m, err := Modifiers(ctx, db, char)
+	if err != nil {
+		return nil, err
+	}
+	m.Forward += forward
+	m.Ongoing += ongoing
+	err = db.Store(ctx, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not store roll modifiers")
+	}
+	return m, nil
+}
+
+// Clear removes the forward and ongoing modifiers of a character
+func Clear(ctx context.Context, db domains.RollModifiersRepository, char *domains.Character) error {
+	err := db.Store(ctx, &domains.RollModifiers{Character: char.ID})
+	if err != nil {
+		return errors.Wrap(err, "could not clear roll modifiers")
+	}
+	return nil
+}
+
+// SpendForward uses up a character's forward once it has been added to a roll.  Ongoing is kept.
+func SpendForward(ctx context.Context, db domains.RollModifiersRepository, m *domains.RollModifiers) error {
+	if m.Forward == 0 {
+		return nil
+	}
+	m.Forward = 0
+	err := db.Store(ctx, m)
+	if err != nil {
+		return errors.Wrap(err, "could not spend forward")
+	}
+	return nil
+}
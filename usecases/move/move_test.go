@@ -0,0 +1,154 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package move
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MoveSuite struct {
+	suite.Suite
+}
+
+func TestMove(t *testing.T) {
+	suite.Run(t, new(MoveSuite))
+}
+
+func genCampaign() *domains.Campaign {
+	id := snowflake.ID(10)
+	return &domains.Campaign{ID: &id, Name: "Apocalypse World"}
+}
+
+func genCharacter() *domains.Character {
+	id := snowflake.ID(20)
+	return &domains.Character{ID: &id, Name: "Rolfball"}
+}
+
+func genMoves() []*domains.Move {
+	id := snowflake.ID(30)
+	return []*domains.Move{
+		{ID: &id, Name: "Go Aggro", Stat: "hard", Hit: "They have to choose.", Partial: "They can choose.", Miss: "Prepare for the worst."},
+		{Name: "Read a Sitch", Stat: "sharp"},
+	}
+}
+
+func (suite *MoveSuite) TestDefine() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	c := genCampaign()
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genMoves(), nil)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	m := &domains.Move{Name: " Seize by Force ", Stat: "hard"}
+	replaced, err := Define(ctx, db, c, m)
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), replaced)
+	assert.Equal(suite.T(), "Seize by Force", m.Name)
+	assert.Equal(suite.T(), c.ID, m.Campaign)
+	assert.Nil(suite.T(), m.ID)
+}
+
+func (suite *MoveSuite) TestDefineReplaces() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genMoves(), nil)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	m := &domains.Move{Name: "go aggro", Hit: "They back down."}
+	replaced, err := Define(ctx, db, genCampaign(), m)
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), replaced)
+	assert.Equal(suite.T(), snowflake.ID(30), *m.ID)
+}
+
+func (suite *MoveSuite) TestDefineName() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	_, err := Define(ctx, db, genCampaign(), &domains.Move{Name: " "})
+	assert.Equal(suite.T(), ErrMoveName, err)
+}
+
+func (suite *MoveSuite) TestFind() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genMoves(), nil).Times(2)
+	m, err := Find(ctx, db, genCampaign(), "read a sitch")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Read a Sitch", m.Name)
+	_, err = Find(ctx, db, genCampaign(), "Help or Interfere")
+	assert.Equal(suite.T(), ErrMoveNotFound, err)
+}
+
+func (suite *MoveSuite) TestRemove() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genMoves(), nil)
+	db.EXPECT().Delete(ctx, "30").Return(nil)
+	m, err := Remove(ctx, db, genCampaign(), "Go Aggro")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Go Aggro", m.Name)
+}
+
+func (suite *MoveSuite) TestRemoveFails() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockMoveRepository(ctrl)
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genMoves(), nil)
+	db.EXPECT().Delete(ctx, "30").Return(errors.New("oops"))
+	_, err := Remove(ctx, db, genCampaign(), "Go Aggro")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *MoveSuite) TestAdjust() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockRollModifiersRepository(ctrl)
+	char := genCharacter()
+	db.EXPECT().FindByCharacter(ctx, "20").Return(&domains.RollModifiers{Character: char.ID, Forward: 1, Ongoing: -1}, nil)
+	db.EXPECT().Store(ctx, &domains.RollModifiers{Character: char.ID, Forward: 2, Ongoing: 0}).Return(nil)
+	m, err := Adjust(ctx, db, char, 1, 1)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, m.Forward)
+	assert.Equal(suite.T(), 0, m.Ongoing)
+}
+
+func (suite *MoveSuite) TestClear() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockRollModifiersRepository(ctrl)
+	char := genCharacter()
+	db.EXPECT().Store(ctx, &domains.RollModifiers{Character: char.ID}).Return(nil)
+	assert.Nil(suite.T(), Clear(ctx, db, char))
+}
+
+func (suite *MoveSuite) TestSpendForward() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockRollModifiersRepository(ctrl)
+	char := genCharacter()
+	m := &domains.RollModifiers{Character: char.ID, Forward: 1, Ongoing: 1}
+	db.EXPECT().Store(ctx, &domains.RollModifiers{Character: char.ID, Ongoing: 1}).Return(nil)
+	assert.Nil(suite.T(), SpendForward(ctx, db, m))
+	// there is no forward left to spend, so nothing is stored
+	assert.Nil(suite.T(), SpendForward(ctx, db, m))
+}
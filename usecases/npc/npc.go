@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package npc keeps the quick stat blocks of the non-player characters a campaign's storytellers
+// run.  An npc has a few named dice pools rather than a full sheet, and is hidden from the
+// campaign's players until a storyteller reveals it.
+package npc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/usecases/sheet"
+	"github.com/pkg/errors"
+)
+
+// ErrNPCName is thrown when an npc is created without a name
+var ErrNPCName = errors.New("an npc needs a name")
+
+// ErrDuplicateNPC is thrown when an npc is created with the name of another in the campaign
+var ErrDuplicateNPC = errors.New("this campaign already has an npc by that name")
+
+// ErrNPCNotFound is thrown when an npc is not found by name
+var ErrNPCNotFound = errors.New("npc not found")
+
+// ErrPools is thrown when dice pools are not written as name:dice
+var ErrPools = errors.New("dice pools are written as name:dice, such as brawl:7,intimidation:5")
+
+// ErrUnknownPool is thrown when an npc is rolled with a pool it does not have
+var ErrUnknownPool = errors.New("the npc does not have that pool")
+
+// ErrCloneCount is thrown when too few or too many copies of an npc are made
+var ErrCloneCount = errors.Errorf("an npc can be cloned between 1 and %d times", MaxClones)
+
+// MaxClones is the most copies of an npc which can be made at once
+const MaxClones = 20
+
+// ParsePools reads a list of dice pools such as brawl:7,intimidation:5
+func ParsePools(value string) (map[string]int, error) {
+	pools := make(map[string]int)
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		pieces := strings.SplitN(part, ":", 2)
+		name := strings.ToLower(strings.TrimSpace(pieces[0]))
+		if len(pieces) != 2 || name == "" {
+			return nil, ErrPools
+		}
+		dice, err := strconv.Atoi(strings.TrimSpace(pieces[1]))
+		if err != nil {
+			return nil, ErrPools
+		}
+		pools[name] = dice
+	}
+	return pools, nil
+}
+
+// Create adds an npc to a campaign
+func Create(ctx context.Context, db domains.NPCRepository, c *domains.Campaign, n *domains.NPC) error {
+	n.Name = strings.TrimSpace(n.Name)
+	if n.Name == "" {
+		return ErrNPCName
+	}
+	npcs, err := List(ctx, db, c, true)
+	if err != nil {
+		return err
+	}
+	if findNPC(npcs, n.Name) != nil {
+		return ErrDuplicateNPC
+	}
+	if n.Pools == nil {
+		n.Pools = make(map[string]int)
+	}
+	n.Campaign = c.ID
+	err = db.Store(ctx, n)
+	if err != nil {
+		return errors.Wrap(err, "could not create npc")
+	}
+	return nil
+}
+
+// List finds the npcs of a campaign.  Hidden npcs are only listed when hidden is true.
+func List(ctx context.Context, db domains.NPCRepository, c *domains.Campaign, hidden bool) ([]*domains.NPC, error) {
+	npcs, err := db.FindByCampaign(ctx, c.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find npcs")
+	}
+	if hidden {
+		return npcs, nil
+	}
+	visible := make([]*domains.NPC, 0, len(npcs))
+	for _, n := range npcs {
+		if !n.Hidden {
+			visible = append(visible, n)
+		}
+	}
+	return visible, nil
+}
+
+// Find finds an npc of a campaign by name.  Hidden npcs are only found when hidden is true.
+func Find(ctx context.Context, db domains.NPCRepository, c *domains.Campaign, name string, hidden bool) (*domains.NPC, error) {
+	npcs, err := List(ctx, db, c, hidden)
+	if err != nil {
+		return nil, err
+	}
+	n := findNPC(npcs, name)
+	if n == nil {
+		return nil, ErrNPCNotFound
+	}
+	return n, nil
+}
+
+// Clone makes numbered copies of an npc, such as Thug 1 to Thug 5.  The numbers continue from
+// any copies the campaign already has.
+func Clone(ctx context.Context, db domains.NPCRepository, c *domains.Campaign, name string, count int) ([]*domains.NPC, error) {
+	if count < 1 || count > MaxClones {
+		return nil, ErrCloneCount
+	}
+	npcs, err := List(ctx, db, c, true)
+	if err != nil {
+		return nil, err
+	}
+	original := findNPC(npcs, name)
+	if original == nil {
+		return nil, ErrNPCNotFound
+	}
+	last := 0
+	prefix := strings.ToLower(original.Name) + " "
+	for _, n := range npcs {
+		if strings.HasPrefix(strings.ToLower(n.Name), prefix) {
+			number, err := strconv.Atoi(n.Name[len(prefix):])
+			if err == nil && number > last {
+				last = number
+			}
+		}
+	}
+	clones := make([]*domains.NPC, 0, count)
+	for i := 1; i <= count; i++ {
+		clone := *original
+		clone.ID = nil
+		clone.Name = fmt.Sprintf("%s %d", original.Name, last+i)
+		clone.Pools = make(map[string]int, len(original.Pools))
+		for pool, dice := range original.Pools {
+			clone.Pools[pool] = dice
+		}
+		err = db.Store(ctx, &clone)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not clone npc")
+		}
+		clones = append(clones, &clone)
+	}
+	return clones, nil
+}
+
+// SetHidden hides an npc from the campaign's players, or reveals it to them
+func SetHidden(ctx context.Context, db domains.NPCRepository, n *domains.NPC, hidden bool) error {
+	n.Hidden = hidden
+	err := db.Store(ctx, n)
+	if err != nil {
+		return errors.Wrap(err, "could not store npc")
+	}
+	return nil
+}
+
+// Remove removes an npc from a campaign
+func Remove(ctx context.Context, db domains.NPCRepository, c *domains.Campaign, name string) (*domains.NPC, error) {
+	n, err := Find(ctx, db, c, name, true)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Delete(ctx, n.ID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not remove npc")
+	}
+	return n, nil
+}
+
+// Pool adds up a dice pool such as brawl+2 from the pools of an npc.  An npc's defense may be used
+// as a pool, as when it resists.
+func Pool(n *domains.NPC, pool string) (int, error) {
+	return sheet.SumPool(pool, func(name string) (int, error) {
+		key := sheet.TraitKey(name)
+		for p, dice := range n.Pools {
+			if sheet.TraitKey(p) == key {
+				return dice, nil
+			}
+		}
+		if key == "defense" {
+			return n.Defense, nil
+		}
+		return 0, errors.Wrap(ErrUnknownPool, name)
+	})
+}
+
+// findNPC finds an npc among others by name
+func findNPC(npcs []*domains.NPC, name string) *domains.NPC {
+	name = strings.TrimSpace(name)
+	for _, n := range npcs {
+		if strings.EqualFold(n.Name, name) {
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package npc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NPCSuite struct {
+	suite.Suite
+}
+
+func TestNPC(t *testing.T) {
+	suite.Run(t, new(NPCSuite))
+}
+
+func genCampaign() *domains.Campaign {
+	id := snowflake.ID(10)
+	return &domains.Campaign{ID: &id, Name: "Chicago"}
+}
+
+func genNPCs() []*domains.NPC {
+	return []*domains.NPC{
+		{Name: "Bouncer", Pools: map[string]int{"brawl": 7, "animal ken": 2}, Health: 8, Defense: 3, Hidden: true},
+		{Name: "Thug", Pools: map[string]int{"brawl": 5}, Health: 7, Hidden: true},
+		{Name: "Thug 1", Pools: map[string]int{"brawl": 5}, Health: 7, Hidden: true},
+		{Name: "Prince", Pools: map[string]int{"persuasion": 9}},
+	}
+}
+
+func (suite *NPCSuite) TestParsePools() {
+	pools, err := ParsePools("Brawl:7, intimidation:5,")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]int{"brawl": 7, "intimidation": 5}, pools)
+	_, err = ParsePools("brawl")
+	assert.Equal(suite.T(), ErrPools, err)
+	_, err = ParsePools(":5")
+	assert.Equal(suite.T(), ErrPools, err)
+	_, err = ParsePools("brawl:lots")
+	assert.Equal(suite.T(), ErrPools, err)
+}
+
+func (suite *NPCSuite) TestCreate() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockNPCRepository(ctrl)
+	c := genCampaign()
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genNPCs(), nil).Times(3)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	n := &domains.NPC{Name: " Ghoul ", Hidden: true}
+	err := Create(ctx, db, c, n)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ghoul", n.Name)
+	assert.Equal(suite.T(), c.ID, n.Campaign)
+	assert.NotNil(suite.T(), n.Pools)
+	err = Create(ctx, db, c, &domains.NPC{Name: "bouncer"})
+	assert.Equal(suite.T(), ErrDuplicateNPC, err)
+	err = Create(ctx, db, c, &domains.NPC{Name: " "})
+	assert.Equal(suite.T(), ErrNPCName, err)
+	err = Create(ctx, db, c, &domains.NPC{Name: "prince"})
+	assert.Equal(suite.T(), ErrDuplicateNPC, err)
+}
+
+func (suite *NPCSuite) TestFind() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockNPCRepository(ctrl)
+	c := genCampaign()
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genNPCs(), nil).AnyTimes()
+	n, err := Find(ctx, db, c, "bouncer", true)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bouncer", n.Name)
+	_, err = Find(ctx, db, c, "bouncer", false)
+	assert.Equal(suite.T(), ErrNPCNotFound, err)
+	n, err = Find(ctx, db, c, "Prince", false)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Prince", n.Name)
+	npcs, err := List(ctx, db, c, false)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), npcs, 1)
+}
+
+func (suite *NPCSuite) TestClone() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockNPCRepository(ctrl)
+	c := genCampaign()
+	db.EXPECT().FindByCampaign(ctx, "10").Return(genNPCs(), nil).Times(2)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil).Times(3)
+	clones, err := Clone(ctx, db, c, "thug", 3)
+	assert.Nil(suite.T(), err)
+	names := make([]string, 0, len(clones))
+	for _, clone := range clones {
+		names = append(names, clone.Name)
+		assert.True(suite.T(), clone.Hidden)
+		assert.Equal(suite.T(), 5, clone.Pools["brawl"])
+	}
+	assert.Equal(suite.T(), []string{"Thug 2", "Thug 3", "Thug 4"}, names)
+	clones[0].Pools["brawl"] = 1
+	assert.Equal(suite.T(), 5, clones[1].Pools["brawl"])
+	_, err = Clone(ctx, db, c, "thug", 0)
+	assert.Equal(suite.T(), ErrCloneCount, err)
+	_, err = Clone(ctx, db, c, "ogre", 2)
+	assert.Equal(suite.T(), ErrNPCNotFound, err)
+}
+
+func (suite *NPCSuite) TestRemove() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockNPCRepository(ctrl)
+	c := genCampaign()
+	npcs := genNPCs()
+	id := snowflake.ID(20)
+	npcs[0].ID = &id
+	db.EXPECT().FindByCampaign(ctx, "10").Return(npcs, nil)
+	db.EXPECT().Delete(ctx, "20").Return(nil)
+	n, err := Remove(ctx, db, c, "Bouncer")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Bouncer", n.Name)
+}
+
+func (suite *NPCSuite) TestSetHidden() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockNPCRepository(ctrl)
+	n := genNPCs()[0]
+	db.EXPECT().Store(ctx, n).Return(nil)
+	err := SetHidden(ctx, db, n, false)
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), n.Hidden)
+}
+
+func (suite *NPCSuite) TestPool() {
+	n := genNPCs()[0]
+	dice, err := Pool(n, "Brawl+2")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 9, dice)
+	dice, err = Pool(n, "animal_ken+defense-1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, dice)
+	_, err = Pool(n, "firearms")
+	assert.Equal(suite.T(), ErrUnknownPool, errors.Cause(err))
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package permission
+
+import (
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrForbidden is thrown when a user tries something their role does not permit
+var ErrForbidden = errors.New("you do not have permission to do that")
+
+// An Action is something a user may or may not be permitted to do
+type Action string
+
+// The actions which are checked against a user's role
+const (
+	ViewSheet       Action = "view-sheet"
+	EditSheet       Action = "edit-sheet"
+	ViewGuildSheets Action = "view-guild-sheets"
+	ViewHiddenRolls Action = "view-hidden-rolls"
+	ManageCampaign  Action = "manage-campaign"
+	ManageDice      Action = "manage-dice"
+	Configure       Action = "configure"
+)
+
+// A Member describes a user's standing in a guild, as reported by discord
+type Member struct {
+	ID    string
+	Admin bool     // whether the user owns or administers the guild
+	Roles []string // the discord roles the user has in the guild
+}
+
+// RoleOf determines the role of a guild member.  Guild admins are owners, and members with one of
+// the guild's storyteller roles are storytellers.  A nil member is not in the guild at all.
+func RoleOf(member *Member, s *domains.GuildSettings) domains.Role {
+	switch {
+	case member == nil:
+		return domains.RoleNone
+	case member.Admin:
+		return domains.RoleOwner
+	}
+	for _, role := range member.Roles {
+		for _, storyteller := range s.Storytellers {
+			if role == storyteller {
+				return domains.RoleStoryteller
+			}
+		}
+	}
+	return domains.RolePlayer
+}
+
+// InCampaign raises a user's role to storyteller for the characters of a campaign they run
+func InCampaign(role domains.Role, user string, c *domains.Campaign) domains.Role {
+	if c == nil || role >= domains.RoleStoryteller {
+		return role
+	}
+	for _, storyteller := range c.Storytellers {
+		if storyteller == user {
+			return domains.RoleStoryteller
+		}
+	}
+	return role
+}
+
+// Can determines whether a user with a role in a guild may perform an action.  For actions on a
+// character, the role must be the user's role in the character's guild.
+func Can(role domains.Role, user string, action Action, char *domains.Character) bool {
+	owns := char != nil && char.Player == user
+	switch action {
+	case ViewSheet:
+		return owns || role >= domains.RolePlayer
+	case EditSheet:
+		return owns || role >= domains.RoleStoryteller
+	case ViewGuildSheets, ViewHiddenRolls, ManageCampaign, ManageDice:
+		return role >= domains.RoleStoryteller
+	case Configure:
+		return role >= domains.RoleOwner
+	}
+	return false
+}
+
+// CanReveal determines whether a user may reveal a hidden roll.  Storytellers may reveal any roll,
+// and players may reveal their own secret rolls, but not their gm rolls.
+func CanReveal(role domains.Role, user string, r *domains.Roll) bool {
+	if r.Visibility == domains.RollSecret && r.Player == user {
+		return true
+	}
+	return Can(role, user, ViewHiddenRolls, nil)
+}
+
+// Check returns ErrForbidden unless the user may perform the action
+func Check(role domains.Role, user string, action Action, char *domains.Character) error {
+	if !Can(role, user, action, char) {
+		return ErrForbidden
+	}
+	return nil
+}
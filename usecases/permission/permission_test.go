@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package permission
+
+import (
+	"testing"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionSuite struct {
+	suite.Suite
+}
+
+func TestPermission(t *testing.T) {
+	suite.Run(t, new(PermissionSuite))
+}
+
+func (suite *PermissionSuite) TestRoleOf() {
+	s := &domains.GuildSettings{Storytellers: []string{"st"}}
+	assert.Equal(suite.T(), domains.RoleNone, RoleOf(nil, s))
+	assert.Equal(suite.T(), domains.RoleOwner, RoleOf(&Member{ID: "1", Admin: true}, s))
+	assert.Equal(suite.T(), domains.RoleStoryteller, RoleOf(&Member{ID: "1", Roles: []string{"x", "st"}}, s))
+	assert.Equal(suite.T(), domains.RolePlayer, RoleOf(&Member{ID: "1", Roles: []string{"x"}}, s))
+}
+
+func (suite *PermissionSuite) TestInCampaign() {
+	c := &domains.Campaign{Storytellers: []string{"1"}}
+	assert.Equal(suite.T(), domains.RoleStoryteller, InCampaign(domains.RolePlayer, "1", c))
+	assert.Equal(suite.T(), domains.RolePlayer, InCampaign(domains.RolePlayer, "2", c))
+	assert.Equal(suite.T(), domains.RoleOwner, InCampaign(domains.RoleOwner, "1", c))
+	assert.Equal(suite.T(), domains.RolePlayer, InCampaign(domains.RolePlayer, "1", nil))
+}
+
+func (suite *PermissionSuite) TestCan() {
+	char := &domains.Character{Player: "1", Guild: "9"}
+	assert.True(suite.T(), Can(domains.RoleNone, "1", ViewSheet, char))
+	assert.True(suite.T(), Can(domains.RoleNone, "1", EditSheet, char))
+	assert.False(suite.T(), Can(domains.RoleNone, "2", ViewSheet, char))
+	assert.True(suite.T(), Can(domains.RolePlayer, "2", ViewSheet, char))
+	assert.False(suite.T(), Can(domains.RolePlayer, "2", EditSheet, char))
+	assert.True(suite.T(), Can(domains.RoleStoryteller, "2", EditSheet, char))
+	assert.True(suite.T(), Can(domains.RoleStoryteller, "2", ViewHiddenRolls, nil))
+	assert.False(suite.T(), Can(domains.RolePlayer, "2", ManageCampaign, nil))
+	assert.True(suite.T(), Can(domains.RoleStoryteller, "2", ManageCampaign, nil))
+	assert.False(suite.T(), Can(domains.RolePlayer, "2", ManageDice, nil))
+	assert.True(suite.T(), Can(domains.RoleStoryteller, "2", ManageDice, nil))
+	assert.False(suite.T(), Can(domains.RoleStoryteller, "2", Configure, nil))
+	assert.True(suite.T(), Can(domains.RoleOwner, "2", Configure, nil))
+	assert.Equal(suite.T(), ErrForbidden, Check(domains.RolePlayer, "2", Configure, nil))
+}
+
+func (suite *PermissionSuite) TestCanReveal() {
+	secret := &domains.Roll{Player: "1", Visibility: domains.RollSecret}
+	gm := &domains.Roll{Player: "1", Visibility: domains.RollGM}
+	assert.True(suite.T(), CanReveal(domains.RolePlayer, "1", secret))
+	assert.False(suite.T(), CanReveal(domains.RolePlayer, "2", secret))
+	assert.False(suite.T(), CanReveal(domains.RolePlayer, "1", gm))
+	assert.True(suite.T(), CanReveal(domains.RoleStoryteller, "2", gm))
+}
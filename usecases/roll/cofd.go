@@ -31,6 +31,9 @@ import (
 	"github.com/kkragenbrink/slate/util"
 )
 
+// WillpowerDice are the dice added to a roll by spending Willpower
+const WillpowerDice = 3
+
 // The CofDRollSystem is the d10 system used in Chronicles of Darkness
 type CofDRollSystem struct {
 	rand        roller
@@ -39,6 +42,7 @@ type CofDRollSystem struct {
 	Exceptional int   `json:"exceptional"`
 	Rote        bool  `json:"rote"`
 	Weakness    bool  `json:"weakness"`
+	Willpower   bool  `json:"willpower"`
 	Dice        int   `json:"dice"`
 	Results     struct {
 		Successes int     `json:"Successes"`
@@ -54,11 +58,37 @@ func (rs *CofDRollSystem) Flags(fs *flag.FlagSet) {
 	fs.IntVar(&rs.Exceptional, "exceptional", 5, "The number of Successes needed for Exceptional success.")
 	fs.BoolVar(&rs.Rote, "rote", false, "Whether the role is a Rote action.")
 	fs.BoolVar(&rs.Weakness, "weakness", false, "Whether the rs is made with Weakness.")
+	fs.BoolVar(&rs.Willpower, "wp", false, "Whether Willpower is spent for three more dice.")
 
 	var system string
 	fs.StringVar(&system, "system", "cofd", "-- ignored --")
 }
 
+// Outcome describes the overall result of the roll
+func (rs *CofDRollSystem) Outcome() Outcome {
+	switch {
+	case rs.Dice == 0 && len(rs.Results.Rolls) > 0 && rs.Results.Rolls[0] == 1:
+		return OutcomeDramaticFailure
+	case rs.Results.Successes == 0:
+		return OutcomeFailure
+	case rs.Results.Successes >= rs.Exceptional:
+		return OutcomeExceptional
+	}
+	return OutcomeSuccess
+}
+
+// Rolled lists every die rolled, followed by every die rerolled
+func (rs *CofDRollSystem) Rolled() []int64 {
+	rolled := make([]int64, 0, len(rs.Results.Rolls)+len(rs.Results.Rerolls))
+	rolled = append(rolled, rs.Results.Rolls...)
+	return append(rolled, rs.Results.Rerolls...)
+}
+
+// Total is the number of successes rolled
+func (rs *CofDRollSystem) Total() int64 {
+	return int64(rs.Results.Successes)
+}
+
 // SetRand assigns a random number generator to the system
 func (rs *CofDRollSystem) SetRand(rand roller) {
 	rs.rand = rand
@@ -77,6 +107,10 @@ func (rs *CofDRollSystem) Roll(ctx context.Context, tokens []string) error {
 		}
 	}
 
+	if rs.Willpower {
+		rs.Dice += WillpowerDice
+	}
+
 	if rs.Again == 0 {
 		rs.Again = 10
 	}
@@ -172,6 +206,9 @@ func (rs *CofDRollSystem) ToString() string {
 	if rs.Weakness {
 		flags = append(flags, "Weakness")
 	}
+	if rs.Willpower {
+		flags = append(flags, "Willpower")
+	}
 	if len(flags) > 0 {
 		buff.WriteString(fmt.Sprintf(" (with %s)", strings.Join(flags, ", ")))
 	}
@@ -201,6 +238,21 @@ func (rs *CofDRollSystem) ToString() string {
 }
 
 func (rs *CofDRollSystem) parseArgs(args []string) (int, error) {
+	return parsePool(args)
+}
+
+// parsePool sums a dice pool such as "3+2-1", which may be split across several args.  A pool is
+// never less than no dice.
+func parsePool(args []string) (int, error) {
+	sum, err := parseSum(args)
+	if err != nil {
+		return 0, err
+	}
+	return util.Max(sum, 0), nil
+}
+
+// parseSum sums numbers such as "3+2-1", which may be split across several args
+func parseSum(args []string) (int, error) {
 	tokens := []int{0}
 
 	// rejoin all the args so that we can split properly
@@ -252,5 +304,116 @@ func (rs *CofDRollSystem) parseArgs(args []string) (int, error) {
 		results += num
 	}
 
-	return util.Max(results, 0), nil
+	return results, nil
+}
+
+// oddsEpsilon is the smallest chance kept while working out odds; anything less could never be seen
+const oddsEpsilon = 1e-15
+
+// exactOdds works out the chance of each number of successes.  Rolls which reroll failures other
+// than with rote are simulated instead.
+func (rs *CofDRollSystem) exactOdds(args []string) (*Odds, error) {
+	dice, err := rs.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if rs.Willpower {
+		dice += WillpowerDice
+	}
+	if dice > MaxOddsDice {
+		return nil, ErrOddsDice
+	}
+	again := rs.Again
+	if again == 0 {
+		again = 10
+	}
+	if again < 2 {
+		return nil, ErrOddsAgain
+	}
+	if again < 8 {
+		return nil, nil
+	}
+	exceptional := rs.Exceptional
+	if exceptional == 0 {
+		exceptional = 5
+	}
+
+	var dramatic float64
+	successes := map[int64]float64{0: 1}
+	if dice == 0 {
+		// a chance die only succeeds on a 10, and fails dramatically on a 1
+		successes = map[int64]float64{0: 0.9, 1: 0.1}
+		dramatic = 0.1
+	}
+	die := cofdDie(again, rs.Rote, rs.Weakness)
+	for i := 0; i < dice; i++ {
+		successes = convolve(successes, die)
+		for value, chance := range successes {
+			if chance < oddsEpsilon {
+				delete(successes, value)
+			}
+		}
+	}
+	// weakness cannot take a roll below no successes
+	for value, chance := range successes {
+		if value < 0 {
+			successes[0] += chance
+			delete(successes, value)
+		}
+	}
+
+	odds := newOdds(successes)
+	odds.Outcomes = true
+	odds.Success = odds.atLeast(1)
+	odds.Exceptional = odds.atLeast(int64(exceptional))
+	odds.DramaticFailure = dramatic
+	return odds, nil
+}
+
+// cofdDie is the chance of each number of successes from one die of a pool, including its rerolls
+func cofdDie(again int64, rote, weakness bool) map[int64]float64 {
+	rerolled := cofdReroll(again)
+	die := make(map[int64]float64)
+	for v := int64(1); v <= 10; v++ {
+		switch {
+		case rote && v < 8:
+			for k, chance := range rerolled {
+				die[k] += chance / 10
+			}
+		case v >= again:
+			for k, chance := range rerolled {
+				die[k+1] += chance / 10
+			}
+		case v >= 8:
+			die[1] += 0.1
+		case weakness && v == 1:
+			die[-1] += 0.1
+		default:
+			die[0] += 0.1
+		}
+	}
+	return die
+}
+
+// cofdReroll is the chance of each number of successes from a rerolled die, which is rerolled
+// again for as long as it comes up again or more
+func cofdReroll(again int64) map[int64]float64 {
+	if again > 11 {
+		again = 11
+	}
+	// of ten faces, seven fail, again-8 succeed, and the rest succeed and reroll
+	fail, succeed, reroll := 0.7, float64(again-8)/10, float64(11-again)/10
+	chances := map[int64]float64{0: fail}
+	previous := fail
+	for k := int64(1); ; k++ {
+		chance := reroll * previous
+		if k == 1 {
+			chance += succeed
+		}
+		if chance < oddsEpsilon {
+			return chances
+		}
+		chances[k] = chance
+		previous = chance
+	}
 }
@@ -58,6 +58,7 @@ func (suite *CofDTestSuite) TestRollAgain() {
 	assert.Equal(suite.T(), exs, o.Results.Successes)
 	assert.Equal(suite.T(), exr, o.Results.Rolls)
 	assert.Equal(suite.T(), exrr, o.Results.Rerolls)
+	assert.Equal(suite.T(), []int64{6, 6, 6, 6, 10, 9}, o.Rolled())
 }
 
 func (suite *CofDTestSuite) TestRollRote() {
@@ -84,6 +85,17 @@ func (suite *CofDTestSuite) TestRollWeak() {
 	assert.Equal(suite.T(), exrr, o.Results.Rerolls)
 }
 
+func (suite *CofDTestSuite) TestRollWillpower() {
+	exr := []int64{9, 2, 3, 4, 5, 6, 8, 1}
+	o := genMockCofDRollSystem(cofdMockRoller(exr, nil), 10, 5, false, false)
+	o.Willpower = true
+	err := o.Roll(context.Background(), []string{"5"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 8, o.Dice)
+	assert.Equal(suite.T(), 2, o.Results.Successes)
+	assert.Contains(suite.T(), o.ToString(), "Willpower")
+}
+
 func (suite *CofDTestSuite) TestRollChance() {
 	exs := 1
 	exr := []int64{10}
@@ -97,6 +109,21 @@ func (suite *CofDTestSuite) TestRollChance() {
 	assert.Equal(suite.T(), exrr, o.Results.Rerolls)
 }
 
+func (suite *CofDTestSuite) TestOutcome() {
+	o := genMockCofDRollSystem(cofdMockRoller([]int64{}, []int64{}), 10, 5, false, false)
+	o.Dice = 3
+	o.Results.Rolls = []int64{1, 2, 3}
+	assert.Equal(suite.T(), OutcomeFailure, o.Outcome())
+	o.Results.Successes = 2
+	assert.Equal(suite.T(), OutcomeSuccess, o.Outcome())
+	o.Results.Successes = 5
+	assert.Equal(suite.T(), OutcomeExceptional, o.Outcome())
+	o.Dice = 0
+	o.Results.Successes = 0
+	o.Results.Rolls = []int64{1}
+	assert.Equal(suite.T(), OutcomeDramaticFailure, o.Outcome())
+}
+
 func (suite *CofDTestSuite) TestParseArgs() {
 	str := []string{"1+1", "-1", "3-3"}
 	exp := 1
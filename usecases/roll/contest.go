@@ -0,0 +1,43 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+// A Contest is the result of a contested action, in which each side rolls and the side with the
+// most successes wins
+type Contest struct {
+	Winner int   // the index of the winning side, or -1 for a tie
+	Margin int64 // how many more successes the winner rolled
+}
+
+// Compare compares the completed rolls of the two sides of a contested action
+func Compare(a, b System) *Contest {
+	contest := new(Contest)
+	switch {
+	case a.Total() > b.Total():
+		contest.Margin = a.Total() - b.Total()
+	case b.Total() > a.Total():
+		contest.Winner = 1
+		contest.Margin = b.Total() - a.Total()
+	default:
+		contest.Winner = -1
+	}
+	return contest
+}
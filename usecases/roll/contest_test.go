@@ -0,0 +1,48 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContestSuite struct {
+	suite.Suite
+}
+
+func TestContest(t *testing.T) {
+	suite.Run(t, new(ContestSuite))
+}
+
+func contestSide(successes int) *CofDRollSystem {
+	rs := new(CofDRollSystem)
+	rs.Results.Successes = successes
+	return rs
+}
+
+func (suite *ContestSuite) TestCompare() {
+	assert.Equal(suite.T(), &Contest{Winner: 0, Margin: 2}, Compare(contestSide(3), contestSide(1)))
+	assert.Equal(suite.T(), &Contest{Winner: 1, Margin: 1}, Compare(contestSide(0), contestSide(1)))
+	assert.Equal(suite.T(), &Contest{Winner: -1}, Compare(contestSide(2), contestSide(2)))
+}
@@ -37,6 +37,12 @@ const (
 	d20Regexp = "([0-9]+)d([0-9]+)(?:(kh|kl)([0-9]+))?"
 )
 
+// ErrD20Keep is thrown when more dice are kept than are rolled
+var ErrD20Keep = errors.New("you cannot keep more dice than you roll")
+
+// ErrD20Minus is thrown when a minus sign has nothing after it to subtract
+var ErrD20Minus = errors.New("a minus sign must be followed by a number or dice")
+
 // A D20Token represents a tokenized roll expression
 type D20Token struct {
 	Dice        int     `json:"dice"`
@@ -72,6 +78,29 @@ func (rs *D20RollSystem) Flags(fs *flag.FlagSet) {
 	fs.StringVar(&system, "system", "d20", "-- ignored --")
 }
 
+// Outcome describes the overall result of the roll; d20 totals have no success or failure
+func (rs *D20RollSystem) Outcome() Outcome {
+	return OutcomeNone
+}
+
+// Rolled lists every die rolled, including those which were not kept
+func (rs *D20RollSystem) Rolled() []int64 {
+	rolled := make([]int64, 0)
+	for _, token := range rs.Expression {
+		rolled = append(rolled, token.Rolls...)
+	}
+	return rolled
+}
+
+// Total is the sum of the expression
+func (rs *D20RollSystem) Total() int64 {
+	var total int64
+	for _, token := range rs.Expression {
+		total += token.Value
+	}
+	return total
+}
+
 // SetRand assigns a random number generator to the system
 func (rs *D20RollSystem) SetRand(rand roller) {
 	rs.rand = rand
@@ -138,10 +167,8 @@ func (rs *D20RollSystem) Roll(ctx context.Context, tokens []string) error {
 // ToString converts the Results to a string.
 func (rs *D20RollSystem) ToString() string {
 	verbose := make([]string, 0)
-	var total int64
+	total := rs.Total()
 	for _, token := range rs.Expression {
-		total += token.Value
-
 		verb := make([]string, 0)
 		for i, roll := range token.Rolls {
 			if token.KeepHighest != 0 {
@@ -214,6 +241,9 @@ func (rs *D20RollSystem) parseTokens(args []string) ([]*D20Token, error) {
 			case "kl":
 				tok.KeepLowest = keep
 			}
+			if keep > dice {
+				return nil, ErrD20Keep
+			}
 
 			tokens = append(tokens, tok)
 			continue
@@ -229,6 +259,9 @@ func (rs *D20RollSystem) parseTokens(args []string) ([]*D20Token, error) {
 
 			// this token is a -, so we need to set the previous token negative
 			if arg == "-" {
+				if len(tokens) == 0 {
+					return nil, ErrD20Minus
+				}
 				x, r := tokens[len(tokens)-1], tokens[:len(tokens)-1]
 				x.Negative = true
 				r = append(r, x)
@@ -247,3 +280,106 @@ func (rs *D20RollSystem) parseTokens(args []string) ([]*D20Token, error) {
 
 	return tokens, nil
 }
+
+// d20Enumeration bounds how many combinations of dice are enumerated to work out the odds of
+// keeping some of them
+const d20Enumeration = 1000000
+
+// d20Convolution bounds the work of summing a pool of dice to work out its odds
+const d20Convolution = 1000000000
+
+// exactOdds works out the chance of each total.  Rolls which keep some of a large pool of dice are
+// simulated instead.
+func (rs *D20RollSystem) exactOdds(args []string) (*Odds, error) {
+	tokens, err := rs.parseTokens(args)
+	if err != nil {
+		return nil, err
+	}
+	totals := map[int64]float64{0: 1}
+	for _, token := range tokens {
+		values := map[int64]float64{token.Value: 1}
+		if token.Dice != 0 && token.Sides != 0 {
+			if token.Dice > MaxOddsDice {
+				return nil, ErrOddsDice
+			}
+			values = d20Dice(token)
+			if values == nil {
+				return nil, nil
+			}
+		}
+		if token.Negative {
+			negated := make(map[int64]float64, len(values))
+			for value, chance := range values {
+				negated[-value] = chance
+			}
+			values = negated
+		}
+		totals = convolve(totals, values)
+	}
+	return newOdds(totals), nil
+}
+
+// d20Dice is the chance of each total of a token's dice, or nil when there are too many to work out
+func d20Dice(token *D20Token) map[int64]float64 {
+	dice, sides := int64(token.Dice), token.Sides
+	keep := token.KeepHighest + token.KeepLowest
+	if keep == 0 || keep >= token.Dice {
+		if work := float64(dice) * float64(sides); work*work > d20Convolution {
+			return nil
+		}
+		// sums[n] is the chance of the dice rolled so far adding up to n
+		sums := []float64{1}
+		for i := int64(0); i < dice; i++ {
+			next := make([]float64, len(sums)+int(sides))
+			for n, chance := range sums {
+				for face := int64(1); face <= sides; face++ {
+					next[n+int(face)] += chance / float64(sides)
+				}
+			}
+			sums = next
+		}
+		chances := make(map[int64]float64)
+		for n, chance := range sums {
+			if chance > 0 {
+				chances[int64(n)] = chance
+			}
+		}
+		return chances
+	}
+
+	combinations := int64(1)
+	for i := int64(0); i < dice; i++ {
+		combinations *= sides
+		if combinations > d20Enumeration {
+			return nil
+		}
+	}
+	chances := make(map[int64]float64)
+	rolls := make([]int64, dice)
+	sorted := make([]int64, dice)
+	for i := range rolls {
+		rolls[i] = 1
+	}
+	for c := int64(0); c < combinations; c++ {
+		copy(sorted, rolls)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		kept := sorted[:keep]
+		if token.KeepHighest != 0 {
+			kept = sorted[len(sorted)-keep:]
+		}
+		var total int64
+		for _, roll := range kept {
+			total += roll
+		}
+		chances[total] += 1 / float64(combinations)
+		// count up to the next combination of dice
+		for i := range rolls {
+			rolls[i]++
+			if rolls[i] <= sides {
+				break
+			}
+			rolls[i] = 1
+		}
+	}
+	return chances
+}
@@ -56,6 +56,7 @@ func (suite *D20TestSuite) TestRollKeepHighest() {
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), []int64{6, 5, 4, 3}, o.Expression[0].Rolls)
 	assert.Equal(suite.T(), int64(15), o.Expression[0].Value)
+	assert.Equal(suite.T(), []int64{6, 5, 4, 3}, o.Rolled())
 }
 
 func (suite *D20TestSuite) TestRollKeepLowest() {
@@ -67,6 +68,17 @@ func (suite *D20TestSuite) TestRollKeepLowest() {
 	assert.Equal(suite.T(), int64(12), o.Expression[0].Value)
 }
 
+func (suite *D20TestSuite) TestRollInvalid() {
+	o := genMockD20RollSystem(d20MockRoller([]int64{4, 2}))
+	assert.Equal(suite.T(), ErrD20Minus, o.Roll(context.Background(), []string{"2d6-"}))
+	o = genMockD20RollSystem(d20MockRoller(nil))
+	assert.Equal(suite.T(), ErrD20Minus, o.Roll(context.Background(), []string{"-"}))
+	o = genMockD20RollSystem(d20MockRoller([]int64{15}))
+	assert.Equal(suite.T(), ErrD20Keep, o.Roll(context.Background(), []string{"1d20kh2"}))
+	o = genMockD20RollSystem(d20MockRoller([]int64{15}))
+	assert.Equal(suite.T(), ErrD20Keep, o.Roll(context.Background(), []string{"1d20kl2"}))
+}
+
 func d20MockRoller(rolls []int64) roller {
 	return func(times int, min, max int64) ([]int64, error) {
 		return rolls, nil
@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTrials is the number of rolls simulated when the odds of a roll cannot be worked out exactly
+const DefaultTrials = 10000
+
+// MaxOddsDice is the largest pool of dice which odds are worked out or simulated for
+const MaxOddsDice = 100
+
+// ErrOddsDice is thrown when the odds of too large a pool are asked for
+var ErrOddsDice = errors.Errorf("odds can only be worked out for up to %d dice", MaxOddsDice)
+
+// ErrOddsAgain is thrown when the odds of a roll which would never stop rerolling are asked for
+var ErrOddsAgain = errors.New("odds can only be worked out for an again of 2 or more")
+
+// An OddsBucket is the chance of a roll coming to a value
+type OddsBucket struct {
+	Value  int64   `json:"value"`
+	Chance float64 `json:"chance"`
+}
+
+// Odds describe how likely each result of a roll is
+type Odds struct {
+	Exact           bool          `json:"exact"`
+	Trials          int           `json:"trials,omitempty"` // the number of rolls simulated, when the odds are not exact
+	Expected        float64       `json:"expected"`
+	Outcomes        bool          `json:"outcomes"` // whether the roll succeeds or fails, rather than coming to a total
+	Success         float64       `json:"success"`
+	Exceptional     float64       `json:"exceptional"`
+	DramaticFailure float64       `json:"dramaticFailure"`
+	Histogram       []*OddsBucket `json:"histogram"`
+}
+
+// exactOdds is implemented by systems which can work out the odds of a roll without rolling it.
+// Systems return no odds when the roll is too complicated to work out, and it is simulated instead.
+type exactOdds interface {
+	exactOdds(args []string) (*Odds, error)
+}
+
+// CalculateOdds works out the odds of a roll, exactly where the system can and otherwise by
+// simulating it the given number of times.  newSystem returns the system configured for the roll.
+func CalculateOdds(ctx context.Context, newSystem func() (System, error), args []string, trials int) (*Odds, error) {
+	rs, err := newSystem()
+	if err != nil {
+		return nil, err
+	}
+	if calc, ok := rs.(exactOdds); ok {
+		odds, err := calc.exactOdds(args)
+		if err != nil || odds != nil {
+			return odds, err
+		}
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return simulate(ctx, newSystem, args, trials, func(times int, min, max int64) ([]int64, error) {
+		return Uniform(times, min, max, func() (uint64, error) { return rng.Uint64(), nil })
+	})
+}
+
+// simulate estimates the odds of a roll by rolling it many times.  Systems which cannot work out
+// their odds exactly may not have checked the size of the pool, so no more than MaxOddsDice dice are
+// drawn at once.
+func simulate(ctx context.Context, newSystem func() (System, error), args []string, trials int, dice roller) (*Odds, error) {
+	capped := func(times int, min, max int64) ([]int64, error) {
+		if times > MaxOddsDice {
+			return nil, ErrOddsDice
+		}
+		return dice(times, min, max)
+	}
+	totals := make(map[int64]float64)
+	outcomes := make(map[Outcome]float64)
+	for i := 0; i < trials; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rs, err := newSystem()
+		if err != nil {
+			return nil, err
+		}
+		rs.SetRand(capped)
+		err = rs.Roll(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		totals[rs.Total()]++
+		outcomes[rs.Outcome()]++
+	}
+	for value := range totals {
+		totals[value] /= float64(trials)
+	}
+	odds := newOdds(totals)
+	odds.Exact = false
+	odds.Trials = trials
+	if outcomes[OutcomeNone] < float64(trials) {
+		odds.Outcomes = true
+		odds.Success = (outcomes[OutcomeSuccess] + outcomes[OutcomeExceptional]) / float64(trials)
+		odds.Exceptional = outcomes[OutcomeExceptional] / float64(trials)
+		odds.DramaticFailure = outcomes[OutcomeDramaticFailure] / float64(trials)
+	}
+	return odds, nil
+}
+
+// newOdds describes the exact chance of each value of a roll
+func newOdds(chances map[int64]float64) *Odds {
+	odds := &Odds{Exact: true, Histogram: make([]*OddsBucket, 0, len(chances))}
+	for value, chance := range chances {
+		if chance == 0 {
+			continue
+		}
+		odds.Expected += float64(value) * chance
+		odds.Histogram = append(odds.Histogram, &OddsBucket{Value: value, Chance: chance})
+	}
+	sort.Slice(odds.Histogram, func(i, j int) bool { return odds.Histogram[i].Value < odds.Histogram[j].Value })
+	return odds
+}
+
+// atLeast is the chance of a roll coming to value or more
+func (odds *Odds) atLeast(value int64) float64 {
+	var chance float64
+	for _, b := range odds.Histogram {
+		if b.Value >= value {
+			chance += b.Chance
+		}
+	}
+	return chance
+}
+
+// convolve combines the chances of two independent values into the chances of their sum
+func convolve(a, b map[int64]float64) map[int64]float64 {
+	sum := make(map[int64]float64, len(a)+len(b))
+	for x, px := range a {
+		for y, py := range b {
+			sum[x+y] += px * py
+		}
+	}
+	return sum
+}
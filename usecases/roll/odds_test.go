@@ -0,0 +1,181 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OddsSuite struct {
+	suite.Suite
+}
+
+func TestOddsSuite(t *testing.T) {
+	suite.Run(t, new(OddsSuite))
+}
+
+func cofdSystem(rs CofDRollSystem) func() (System, error) {
+	return func() (System, error) {
+		sys := rs
+		return &sys, nil
+	}
+}
+
+func d20System() (System, error) {
+	return NewRoller("d20", nil)
+}
+
+func (suite *OddsSuite) odds(newSystem func() (System, error), args ...string) *Odds {
+	odds, err := CalculateOdds(context.Background(), newSystem, args, DefaultTrials)
+	assert.Nil(suite.T(), err)
+	var total float64
+	for _, b := range odds.Histogram {
+		total += b.Chance
+	}
+	assert.InDelta(suite.T(), 1, total, 1e-9)
+	return odds
+}
+
+func chance(odds *Odds, value int64) float64 {
+	for _, b := range odds.Histogram {
+		if b.Value == value {
+			return b.Chance
+		}
+	}
+	return 0
+}
+
+func (suite *OddsSuite) TestCofD() {
+	odds := suite.odds(cofdSystem(CofDRollSystem{}), "1")
+	assert.True(suite.T(), odds.Exact)
+	assert.True(suite.T(), odds.Outcomes)
+	assert.InDelta(suite.T(), 0.7, chance(odds, 0), 1e-9)
+	assert.InDelta(suite.T(), 0.27, chance(odds, 1), 1e-9)
+	assert.InDelta(suite.T(), 0.027, chance(odds, 2), 1e-9)
+	assert.InDelta(suite.T(), 0.3, odds.Success, 1e-9)
+	assert.Zero(suite.T(), odds.DramaticFailure)
+
+	// each die of a 10-again pool averages a third of a success
+	odds = suite.odds(cofdSystem(CofDRollSystem{}), "4", "+", "2")
+	assert.InDelta(suite.T(), 2, odds.Expected, 1e-9)
+	assert.True(suite.T(), odds.Exceptional > 0 && odds.Exceptional < odds.Success)
+
+	// 8-again averages three sevenths
+	odds = suite.odds(cofdSystem(CofDRollSystem{Again: 8}), "7")
+	assert.InDelta(suite.T(), 3, odds.Expected, 1e-9)
+}
+
+func (suite *OddsSuite) TestCofDRoteWeakness() {
+	odds := suite.odds(cofdSystem(CofDRollSystem{Rote: true}), "1")
+	assert.InDelta(suite.T(), 0.51, odds.Success, 1e-9)
+
+	odds = suite.odds(cofdSystem(CofDRollSystem{Weakness: true}), "1")
+	assert.InDelta(suite.T(), 0.7, chance(odds, 0), 1e-9)
+	assert.InDelta(suite.T(), 0.3, odds.Success, 1e-9)
+
+	// a rote roll ignores weakness on the dice it rerolls
+	odds = suite.odds(cofdSystem(CofDRollSystem{Rote: true, Weakness: true}), "1")
+	assert.InDelta(suite.T(), 0.51, odds.Success, 1e-9)
+}
+
+func (suite *OddsSuite) TestCofDChance() {
+	odds := suite.odds(cofdSystem(CofDRollSystem{}), "0")
+	assert.True(suite.T(), odds.Exact)
+	assert.InDelta(suite.T(), 0.1, odds.Success, 1e-9)
+	assert.InDelta(suite.T(), 0.1, odds.DramaticFailure, 1e-9)
+	assert.Zero(suite.T(), odds.Exceptional)
+}
+
+func (suite *OddsSuite) TestCofDSimulated() {
+	odds := suite.odds(cofdSystem(CofDRollSystem{Again: 7}), "5")
+	assert.False(suite.T(), odds.Exact)
+	assert.Equal(suite.T(), DefaultTrials, odds.Trials)
+	assert.True(suite.T(), odds.Outcomes)
+}
+
+func (suite *OddsSuite) TestCofDErrors() {
+	_, err := CalculateOdds(context.Background(), cofdSystem(CofDRollSystem{Again: 1}), []string{"5"}, DefaultTrials)
+	assert.Equal(suite.T(), ErrOddsAgain, err)
+	_, err = CalculateOdds(context.Background(), cofdSystem(CofDRollSystem{}), []string{"101"}, DefaultTrials)
+	assert.Equal(suite.T(), ErrOddsDice, err)
+}
+
+func (suite *OddsSuite) TestD20() {
+	odds := suite.odds(d20System, "1d20")
+	assert.True(suite.T(), odds.Exact)
+	assert.False(suite.T(), odds.Outcomes)
+	assert.Len(suite.T(), odds.Histogram, 20)
+	assert.InDelta(suite.T(), 10.5, odds.Expected, 1e-9)
+
+	odds = suite.odds(d20System, "2d6+3")
+	assert.InDelta(suite.T(), 10, odds.Expected, 1e-9)
+	assert.InDelta(suite.T(), 6.0/36, chance(odds, 10), 1e-9)
+
+	odds = suite.odds(d20System, "10-1d4")
+	assert.InDelta(suite.T(), 7.5, odds.Expected, 1e-9)
+	assert.InDelta(suite.T(), 0.25, chance(odds, 6), 1e-9)
+}
+
+func (suite *OddsSuite) TestD20Keep() {
+	odds := suite.odds(d20System, "2d20kh1")
+	assert.True(suite.T(), odds.Exact)
+	assert.InDelta(suite.T(), 39.0/400, chance(odds, 20), 1e-9)
+	assert.InDelta(suite.T(), 13.825, odds.Expected, 1e-9)
+
+	odds = suite.odds(d20System, "2d20kl1")
+	assert.InDelta(suite.T(), 39.0/400, chance(odds, 1), 1e-9)
+
+	// too many combinations to enumerate
+	odds = suite.odds(d20System, "8d20kh3")
+	assert.False(suite.T(), odds.Exact)
+	assert.True(suite.T(), odds.Histogram[0].Value >= 3)
+	assert.True(suite.T(), odds.Histogram[len(odds.Histogram)-1].Value <= 60)
+}
+
+func (suite *OddsSuite) TestSimulate() {
+	rng := rand.New(rand.NewSource(1))
+	dice := func(times int, min, max int64) ([]int64, error) {
+		return Uniform(times, min, max, func() (uint64, error) { return rng.Uint64(), nil })
+	}
+	newSystem := cofdSystem(CofDRollSystem{})
+	exact := suite.odds(newSystem, "5")
+	simulated, err := simulate(context.Background(), newSystem, []string{"5"}, 20000, dice)
+	assert.Nil(suite.T(), err)
+	assert.InDelta(suite.T(), exact.Expected, simulated.Expected, 0.05)
+	assert.InDelta(suite.T(), exact.Success, simulated.Success, 0.02)
+	assert.InDelta(suite.T(), exact.Exceptional, simulated.Exceptional, 0.02)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = simulate(ctx, newSystem, []string{"5"}, 10, dice)
+	assert.Equal(suite.T(), context.Canceled, err)
+
+	// whatever the system, no more dice are drawn than odds are worked out for
+	_, err = simulate(context.Background(), newSystem, []string{"5000000"}, 10, dice)
+	assert.Equal(suite.T(), ErrOddsDice, err)
+	_, err = CalculateOdds(context.Background(), func() (System, error) { return new(V5RollSystem), nil }, []string{"5000000"}, 10)
+	assert.NotNil(suite.T(), err)
+}
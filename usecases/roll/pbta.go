@@ -0,0 +1,197 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// PbtADice are the dice rolled for a move, of which two are kept
+const PbtADice = 2
+
+// The tiers of a move's result
+const (
+	PbtAStrongHit = 10 // a strong hit, on 10+
+	PbtAWeakHit   = 7  // a weak hit, on 7-9; anything less is a miss
+)
+
+// The PbtARollSystem is the 2d6 move roller used in Powered by the Apocalypse games
+type PbtARollSystem struct {
+	rand      roller
+	Verbose   bool   `json:"verbose"`
+	Advantage bool   `json:"advantage"`
+	Stat      int    `json:"stat"`
+	Forward   int    `json:"forward"`
+	Ongoing   int    `json:"ongoing"`
+	Move      string `json:"move"`
+	Hit       string `json:"hit"`      // the text of the move on a strong hit
+	Partial   string `json:"partial"`  // the text of the move on a weak hit
+	Miss      string `json:"miss"`     // the text of the move on a miss
+	Modifier  int    `json:"modifier"` // the stat and the roll's tokens added together
+	Results   struct {
+		Total int64   `json:"Total"`
+		Rolls []int64 `json:"Rolls"`
+		Kept  []int64 `json:"Kept"`
+	} `json:"Results"`
+}
+
+// Flags sets up the flag rules for the system
+func (rs *PbtARollSystem) Flags(fs *flag.FlagSet) {
+	fs.BoolVar(&rs.Verbose, "verbose", false, "Whether to use a Verbose output.")
+	fs.BoolVar(&rs.Advantage, "advantage", false, "Whether to roll 3d6 and keep the highest two.")
+	fs.IntVar(&rs.Stat, "stat", 0, "The stat added to the roll, which may be negative.")
+	fs.IntVar(&rs.Forward, "forward", 0, "Forward added to this roll, besides the character's own.")
+	fs.IntVar(&rs.Ongoing, "ongoing", 0, "Ongoing added to this roll, besides the character's own.")
+	fs.StringVar(&rs.Move, "move", "", "The campaign's move being made, whose text is shown with the result.")
+
+	var system string
+	fs.StringVar(&system, "system", "pbta", "-- ignored --")
+}
+
+// Outcome describes the overall result of the roll: a strong hit is exceptional, a weak hit a
+// success and a miss a failure
+func (rs *PbtARollSystem) Outcome() Outcome {
+	switch {
+	case rs.Results.Total >= PbtAStrongHit:
+		return OutcomeExceptional
+	case rs.Results.Total >= PbtAWeakHit:
+		return OutcomeSuccess
+	}
+	return OutcomeFailure
+}
+
+// Rolled lists every die rolled, including any dropped for advantage
+func (rs *PbtARollSystem) Rolled() []int64 {
+	return rs.Results.Rolls
+}
+
+// Total is the kept dice and every modifier added together
+func (rs *PbtARollSystem) Total() int64 {
+	return rs.Results.Total
+}
+
+// SetRand assigns a random number generator to the system
+func (rs *PbtARollSystem) SetRand(rand roller) {
+	rs.rand = rand
+}
+
+// Roll runs the rollsystem for a given set of []tokens, which are added to the stat.
+// This function should only be run once per object.
+func (rs *PbtARollSystem) Roll(ctx context.Context, tokens []string) error {
+	sum, err := parseSum(tokens)
+	if err != nil {
+		return err
+	}
+	rs.Modifier = rs.Stat + sum
+
+	dice := PbtADice
+	if rs.Advantage {
+		dice++
+	}
+	rolls, err := rs.rand(dice, 1, 6)
+	if err != nil {
+		return err
+	}
+	rs.Results.Rolls = rolls
+	rs.Results.Kept = keepHighest(rolls, PbtADice)
+
+	total := int64(rs.Modifier + rs.Forward + rs.Ongoing)
+	for _, roll := range rs.Results.Kept {
+		total += roll
+	}
+	rs.Results.Total = total
+	return nil
+}
+
+// keepHighest keeps the highest of some dice, in the order they were rolled
+func keepHighest(rolls []int64, keep int) []int64 {
+	kept := append([]int64(nil), rolls...)
+	for len(kept) > keep {
+		lowest := 0
+		for i, roll := range kept {
+			if roll < kept[lowest] {
+				lowest = i
+			}
+		}
+		kept = append(kept[:lowest], kept[lowest+1:]...)
+	}
+	return kept
+}
+
+// Tier names the tier of the result and gives the move's text for it
+func (rs *PbtARollSystem) Tier() (string, string) {
+	switch rs.Outcome() {
+	case OutcomeExceptional:
+		return "Strong hit", rs.Hit
+	case OutcomeSuccess:
+		return "Weak hit", rs.Partial
+	}
+	return "Miss", rs.Miss
+}
+
+// ToString converts the Results to a string.
+func (rs *PbtARollSystem) ToString() string {
+	var buff bytes.Buffer
+	var flags []string
+
+	if rs.Move != "" {
+		buff.WriteString(fmt.Sprintf("made the %s move and ", rs.Move))
+	}
+	buff.WriteString("rolled 2d6")
+	if rs.Modifier != 0 {
+		buff.WriteString(fmt.Sprintf("%+d", rs.Modifier))
+	}
+
+	// add roll modifying flags
+	if rs.Advantage {
+		flags = append(flags, "Advantage")
+	}
+	if rs.Forward != 0 {
+		flags = append(flags, fmt.Sprintf("Forward %+d", rs.Forward))
+	}
+	if rs.Ongoing != 0 {
+		flags = append(flags, fmt.Sprintf("Ongoing %+d", rs.Ongoing))
+	}
+	if len(flags) > 0 {
+		buff.WriteString(fmt.Sprintf(" (with %s)", strings.Join(flags, ", ")))
+	}
+
+	tier, text := rs.Tier()
+	buff.WriteString(fmt.Sprintf(" for %d. %s!", rs.Results.Total, tier))
+
+	if rs.Verbose {
+		buff.WriteString(fmt.Sprintf(" Rolls: %d", rs.Results.Rolls))
+
+		if len(rs.Results.Kept) < len(rs.Results.Rolls) {
+			buff.WriteString(fmt.Sprintf(" Kept: %d", rs.Results.Kept))
+		}
+	}
+
+	if text != "" {
+		buff.WriteString("\n" + text)
+	}
+
+	return buff.String()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type PbtATestSuite struct {
+	suite.Suite
+}
+
+func TestPbtA(t *testing.T) {
+	suite.Run(t, new(PbtATestSuite))
+}
+
+func (suite *PbtATestSuite) TestRollStrongHit() {
+	o := &PbtARollSystem{rand: cofdMockRoller([]int64{5, 4}, nil), Move: "Go Aggro", Hit: "They have to choose."}
+	err := o.Roll(context.Background(), []string{"2"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(11), o.Total())
+	assert.Equal(suite.T(), OutcomeExceptional, o.Outcome())
+	assert.Equal(suite.T(), "made the Go Aggro move and rolled 2d6+2 for 11. Strong hit!\nThey have to choose.", o.ToString())
+}
+
+func (suite *PbtATestSuite) TestRollWeakHit() {
+	o := &PbtARollSystem{rand: cofdMockRoller([]int64{3, 4}, nil), Stat: -1, Forward: 1, Ongoing: 1, Partial: "They can choose."}
+	err := o.Roll(context.Background(), nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(8), o.Total())
+	assert.Equal(suite.T(), OutcomeSuccess, o.Outcome())
+	assert.Equal(suite.T(), "rolled 2d6-1 (with Forward +1, Ongoing +1) for 8. Weak hit!\nThey can choose.", o.ToString())
+}
+
+func (suite *PbtATestSuite) TestRollMiss() {
+	o := &PbtARollSystem{rand: cofdMockRoller([]int64{1, 2}, nil), Verbose: true}
+	err := o.Roll(context.Background(), []string{"1", "-1"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), o.Total())
+	assert.Equal(suite.T(), OutcomeFailure, o.Outcome())
+	assert.Equal(suite.T(), "rolled 2d6 for 3. Miss! Rolls: [1 2]", o.ToString())
+}
+
+func (suite *PbtATestSuite) TestRollAdvantage() {
+	o := &PbtARollSystem{rand: cofdMockRoller([]int64{6, 1, 4}, nil), Advantage: true, Verbose: true}
+	err := o.Roll(context.Background(), []string{"0"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{6, 4}, o.Results.Kept)
+	assert.Equal(suite.T(), []int64{6, 1, 4}, o.Rolled())
+	assert.Equal(suite.T(), int64(10), o.Total())
+	assert.Equal(suite.T(), "rolled 2d6 (with Advantage) for 10. Strong hit! Rolls: [6 1 4] Kept: [6 4]", o.ToString())
+}
+
+func (suite *PbtATestSuite) TestRollInvalid() {
+	o := &PbtARollSystem{rand: cofdMockRoller(nil, nil)}
+	err := o.Roll(context.Background(), []string{"cool"})
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *PbtATestSuite) TestKeepHighest() {
+	assert.Equal(suite.T(), []int64{3, 5}, keepHighest([]int64{3, 3, 5}, 2))
+	assert.Equal(suite.T(), []int64{2, 1}, keepHighest([]int64{2, 1}, 2))
+}
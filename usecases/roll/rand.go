@@ -21,16 +21,45 @@
 package roll
 
 import (
+	"math"
 	"math/rand"
+
+	"github.com/pkg/errors"
 )
 
-// MathRand generates a random number between 1 and the number of sides for each
-// die using the math/rand package
+// ErrInvalidRange is thrown when dice are drawn with a maximum below their minimum
+var ErrInvalidRange = errors.New("the maximum of a die cannot be less than its minimum")
+
+// MathRand generates a random number between min and max for each die using the
+// math/rand package
 func MathRand(times, min, max int) []int {
 	var results []int
 	for i := 0; i < times; i++ {
-		roll := rand.Intn(max) + min
+		roll := rand.Intn(max-min+1) + min
 		results = append(results, roll)
 	}
 	return results
 }
+
+// Uniform draws dice between min and max from a source of random 64 bit values.  Values which
+// would bias the dice towards the low end of the range are rejected and drawn again.
+func Uniform(times int, min, max int64, next func() (uint64, error)) ([]int64, error) {
+	if max < min {
+		return nil, ErrInvalidRange
+	}
+	n := uint64(max-min) + 1
+	// 2^64 mod n values at the top of the range would be drawn once too often
+	excess := (math.MaxUint64%n + 1) % n
+	dice := make([]int64, 0, times)
+	for i := 0; i < times; i++ {
+		v, err := next()
+		for err == nil && excess != 0 && v > math.MaxUint64-excess {
+			v, err = next()
+		}
+		if err != nil {
+			return nil, err
+		}
+		dice = append(dice, min+int64(v%n))
+	}
+	return dice, nil
+}
@@ -61,8 +61,11 @@
 package roll
 
 import (
-	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMathRand(t *testing.T) {
@@ -73,4 +76,29 @@ func TestMathRand(t *testing.T) {
 	assert.Equal(t, []int{1, 1, 1}, got)
 	got = MathRand(1, 1, 10)
 	assert.True(t, 1 <= got[0] && got[0] <= 10)
+	got = MathRand(1, 5, 5)
+	assert.Equal(t, []int{5}, got)
+}
+
+func TestUniform(t *testing.T) {
+	values := []uint64{0, 7, math.MaxUint64, 10}
+	next := func() (uint64, error) {
+		v := values[0]
+		values = values[1:]
+		return v, nil
+	}
+
+	// math.MaxUint64 falls in the biased tail for a range of three and is drawn again
+	got, err := Uniform(3, -1, 1, next)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{-1, 0, 0}, got)
+}
+
+func TestUniform_Errors(t *testing.T) {
+	_, err := Uniform(1, 2, 1, nil)
+	assert.Equal(t, ErrInvalidRange, err)
+
+	failure := errors.New("no entropy")
+	_, err = Uniform(1, 1, 6, func() (uint64, error) { return 0, failure })
+	assert.Equal(t, failure, err)
 }
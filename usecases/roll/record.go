@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrRollNotFound is thrown when a kept roll could not be found
+var ErrRollNotFound = errors.New("could not find a roll by that id")
+
+// DefaultHistory and MaxHistory bound how many kept rolls are listed at once
+const (
+	DefaultHistory = 10
+	MaxHistory     = 50
+)
+
+// Visibility determines who may see a roll from its flags.  A gm roll is hidden even from the
+// roller, so it wins over a secret one.
+func Visibility(secret, gm bool) domains.RollVisibility {
+	switch {
+	case gm:
+		return domains.RollGM
+	case secret:
+		return domains.RollSecret
+	}
+	return domains.RollPublic
+}
+
+// Record describes a completed roll so that it can be kept
+func Record(rs System, system string, visibility domains.RollVisibility) (*domains.Roll, error) {
+	results, err := json.Marshal(rs)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode roll")
+	}
+	r := new(domains.Roll)
+	r.System = system
+	r.Visibility = visibility
+	r.Description = rs.ToString()
+	r.Outcome = int(rs.Outcome())
+	r.Dice = rs.Rolled()
+	r.Results = results
+	return r, nil
+}
+
+// Keep stores a roll, so that it can be revealed or reviewed later
+func Keep(ctx context.Context, db domains.RollRepository, r *domains.Roll) error {
+	return errors.Wrap(db.Store(ctx, r), "could not keep roll")
+}
+
+// History lists kept rolls, newest first.  The number of rolls listed is bounded.
+func History(ctx context.Context, db domains.RollRepository, q *domains.RollQuery) ([]*domains.Roll, error) {
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultHistory
+	case q.Limit > MaxHistory:
+		q.Limit = MaxHistory
+	}
+	rolls, err := db.Find(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find rolls")
+	}
+	return rolls, nil
+}
+
+// Hidden determines whether the results of a roll are still hidden
+func Hidden(r *domains.Roll) bool {
+	return r.Visibility != domains.RollPublic && !r.Revealed
+}
+
+// Redact removes the results of a hidden roll, for those who may not see them.  Its proof is removed
+// too, since the draws it records are the dice themselves.
+func Redact(r *domains.Roll) {
+	if !Hidden(r) {
+		return
+	}
+	r.Description = ""
+	r.Outcome = int(OutcomeNone)
+	r.Dice = nil
+	r.Results = nil
+	r.Proof = nil
+}
+
+// Find finds a kept roll by id
+func Find(ctx context.Context, db domains.RollRepository, id string) (*domains.Roll, error) {
+	r, err := db.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrRollNotFound
+	}
+	return r, nil
+}
+
+// Reveal marks a hidden roll as revealed
+func Reveal(ctx context.Context, db domains.RollRepository, r *domains.Roll) error {
+	r.Revealed = true
+	return errors.Wrap(db.Store(ctx, r), "could not reveal roll")
+}
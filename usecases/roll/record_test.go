@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibility(t *testing.T) {
+	assert.Equal(t, domains.RollPublic, Visibility(false, false))
+	assert.Equal(t, domains.RollSecret, Visibility(true, false))
+	assert.Equal(t, domains.RollGM, Visibility(false, true))
+	assert.Equal(t, domains.RollGM, Visibility(true, true))
+}
+
+func TestRecord(t *testing.T) {
+	rs := NewD20RollSystem()
+	rs.SetRand(func(times int, min, max int64) ([]int64, error) { return []int64{4}, nil })
+	err := rs.Roll(context.Background(), []string{"1d6"})
+	assert.Nil(t, err)
+	r, err := Record(rs, "d20", domains.RollSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, "d20", r.System)
+	assert.Equal(t, domains.RollSecret, r.Visibility)
+	assert.Equal(t, rs.ToString(), r.Description)
+	assert.Equal(t, int(OutcomeNone), r.Outcome)
+	assert.Equal(t, []int64{4}, r.Dice)
+	assert.NotEmpty(t, r.Results)
+}
+
+func TestHistory(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	db := domains.NewMockRollRepository(ctrl)
+	rolls := []*domains.Roll{{Visibility: domains.RollPublic}}
+	db.EXPECT().Find(ctx, &domains.RollQuery{Channel: "c1", Limit: DefaultHistory}).Return(rolls, nil)
+	db.EXPECT().Find(ctx, &domains.RollQuery{Channel: "c1", Limit: MaxHistory}).Return(rolls, nil)
+	found, err := History(ctx, db, &domains.RollQuery{Channel: "c1"})
+	assert.Nil(t, err)
+	assert.Equal(t, rolls, found)
+	_, err = History(ctx, db, &domains.RollQuery{Channel: "c1", Limit: 1000})
+	assert.Nil(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	public := &domains.Roll{Visibility: domains.RollPublic, Description: "rolled 1d6: 4", Dice: []int64{4}, Proof: genProof()}
+	Redact(public)
+	assert.Equal(t, "rolled 1d6: 4", public.Description)
+	assert.NotNil(t, public.Proof)
+	secret := &domains.Roll{Visibility: domains.RollSecret, Description: "rolled 1d6: 4", Dice: []int64{4}, Proof: genProof()}
+	Redact(secret)
+	assert.Empty(t, secret.Description)
+	assert.Nil(t, secret.Dice)
+	assert.Nil(t, secret.Proof)
+	gm := &domains.Roll{Visibility: domains.RollGM, Description: "rolled 1d6: 4", Dice: []int64{4}, Proof: genProof()}
+	Redact(gm)
+	assert.Nil(t, gm.Proof)
+	revealed := &domains.Roll{Visibility: domains.RollGM, Revealed: true, Description: "rolled 1d6: 4"}
+	Redact(revealed)
+	assert.Equal(t, "rolled 1d6: 4", revealed.Description)
+}
+
+func TestReveal(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	db := domains.NewMockRollRepository(ctrl)
+	r := &domains.Roll{Visibility: domains.RollGM}
+	db.EXPECT().FindByID(ctx, "1").Return(r, nil)
+	db.EXPECT().FindByID(ctx, "2").Return(nil, errors.New("no rows"))
+	db.EXPECT().Store(ctx, r).Return(nil)
+	found, err := Find(ctx, db, "1")
+	assert.Nil(t, err)
+	_, err = Find(ctx, db, "2")
+	assert.Equal(t, ErrRollNotFound, err)
+	err = Reveal(ctx, db, found)
+	assert.Nil(t, err)
+	assert.True(t, r.Revealed)
+}
+
+func genProof() *domains.RollProof {
+	return &domains.RollProof{Method: domains.ProofRandomOrg, Draws: []*domains.RollDraw{{Times: 1, Min: 1, Max: 6, Dice: []int64{4}}}}
+}
@@ -29,23 +29,45 @@ import (
 )
 
 // ErrInvalidRollSystem is thrown when an invalid roll system is selected
-var ErrInvalidRollSystem = errors.New("roll system must be one of: cofd, d20, fate")
+var ErrInvalidRollSystem = errors.New("roll system must be one of: cofd, d20, pbta, v5")
 
 // ErrInvalidToken is thrown when an invalid token is sent
 var ErrInvalidToken = errors.New("You have submitted an invalid token")
 
+// Systems lists the names of the available roll systems
+var Systems = []string{"cofd", "d20", "pbta", "v5"}
+
 const (
 	cofd int = iota
 	d20
 	fate
 )
 
+// An Outcome describes the overall result of a roll
+type Outcome int
+
+const (
+	// OutcomeNone is the outcome of systems which do not have successes, such as a d20 total
+	OutcomeNone Outcome = iota
+	// OutcomeFailure is the outcome of a failed roll
+	OutcomeFailure
+	// OutcomeSuccess is the outcome of a successful roll
+	OutcomeSuccess
+	// OutcomeExceptional is the outcome of an exceptionally successful roll
+	OutcomeExceptional
+	// OutcomeDramaticFailure is the outcome of a dramatically failed roll
+	OutcomeDramaticFailure
+)
+
 // A System contains the logic needed to perform a roll.
 type System interface {
 	Flags(*flag.FlagSet)
+	Outcome() Outcome
 	Roll(context.Context, []string) error
+	Rolled() []int64
 	SetRand(roller)
 	ToString() string
+	Total() int64
 }
 
 type roller func(times int, min, max int64) ([]int64, error)
@@ -58,6 +80,10 @@ func NewRoller(system string, body json.RawMessage) (System, error) {
 		sys = &CofDRollSystem{}
 	case "d20":
 		sys = NewD20RollSystem()
+	case "pbta":
+		sys = &PbtARollSystem{}
+	case "v5":
+		sys = &V5RollSystem{}
 	default:
 		return nil, ErrInvalidRollSystem
 	}
@@ -0,0 +1,273 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// MaxHunger is the most Hunger a vampire can have
+const MaxHunger = 5
+
+// V5RerollDice are the most dice which Willpower can reroll
+const V5RerollDice = 3
+
+// MaxV5Dice is the largest pool of dice which can be rolled
+const MaxV5Dice = 100
+
+// ErrV5Dice is thrown when too large a pool is rolled
+var ErrV5Dice = errors.Errorf("a pool can be at most %d dice", MaxV5Dice)
+
+// ErrHunger is thrown when a roll is made with Hunger out of range
+var ErrHunger = errors.Errorf("hunger must be between 0 and %d", MaxHunger)
+
+// The V5RollSystem is the d10 system used in Vampire: the Masquerade 5th edition
+type V5RollSystem struct {
+	rand       roller
+	Verbose    bool `json:"verbose"`
+	Hunger     int  `json:"hunger"`
+	Difficulty int  `json:"difficulty"`
+	Reroll     bool `json:"reroll"`
+	Rouse      bool `json:"rouse"`
+	Dice       int  `json:"dice"`
+	Results    struct {
+		Successes int     `json:"Successes"`
+		Rolls     []int64 `json:"Rolls"`    // the regular dice, as first rolled
+		Hunger    []int64 `json:"Hunger"`   // the hunger dice
+		Rerolled  []int   `json:"Rerolled"` // the regular dice which Willpower rerolled
+		Rerolls   []int64 `json:"Rerolls"`  // what those dice were rerolled to
+		Criticals int     `json:"Criticals"`
+		Messy     bool    `json:"Messy"`
+		Bestial   bool    `json:"Bestial"`
+	} `json:"Results"`
+}
+
+// Flags sets up the flag rules for the system
+func (rs *V5RollSystem) Flags(fs *flag.FlagSet) {
+	fs.BoolVar(&rs.Verbose, "verbose", false, "Whether to use a Verbose output.")
+	fs.IntVar(&rs.Hunger, "hunger", 0, "How many of the pool's dice are Hunger dice.")
+	fs.IntVar(&rs.Difficulty, "difficulty", 0, "The Successes needed to win; any Success wins by default.")
+	fs.BoolVar(&rs.Reroll, "reroll", false, "Whether Willpower is spent to reroll up to three failed dice which are not Hunger dice.")
+	fs.BoolVar(&rs.Rouse, "rouse", false, "Whether to make a Rouse check instead of rolling a pool.")
+
+	var system string
+	fs.StringVar(&system, "system", "v5", "-- ignored --")
+}
+
+// Outcome describes the overall result of the roll
+func (rs *V5RollSystem) Outcome() Outcome {
+	switch {
+	case rs.Results.Bestial:
+		return OutcomeDramaticFailure
+	case !rs.won():
+		return OutcomeFailure
+	case rs.Results.Criticals > 0:
+		return OutcomeExceptional
+	}
+	return OutcomeSuccess
+}
+
+// Rolled lists every regular die rolled, then every hunger die, then every die Willpower rerolled
+func (rs *V5RollSystem) Rolled() []int64 {
+	rolled := make([]int64, 0, len(rs.Results.Rolls)+len(rs.Results.Hunger)+len(rs.Results.Rerolls))
+	rolled = append(rolled, rs.Results.Rolls...)
+	rolled = append(rolled, rs.Results.Hunger...)
+	return append(rolled, rs.Results.Rerolls...)
+}
+
+// Total is the number of successes rolled
+func (rs *V5RollSystem) Total() int64 {
+	return int64(rs.Results.Successes)
+}
+
+// SetRand assigns a random number generator to the system
+func (rs *V5RollSystem) SetRand(rand roller) {
+	rs.rand = rand
+}
+
+// Roll runs the rollsystem for a given set of []tokens.
+// This function should only be run once per object.
+func (rs *V5RollSystem) Roll(ctx context.Context, tokens []string) error {
+	if rs.Hunger < 0 || rs.Hunger > MaxHunger {
+		return ErrHunger
+	}
+
+	// a rouse check is a single die, whatever the pool
+	if rs.Rouse {
+		rolls, err := rs.rand(1, 1, 10)
+		if err != nil {
+			return err
+		}
+		rs.Dice = 1
+		rs.Results.Rolls = rolls
+		if rolls[0] >= 6 {
+			rs.Results.Successes = 1
+		}
+		return nil
+	}
+
+	if tokens != nil {
+		var err error
+
+		rs.Dice, err = parsePool(tokens)
+		if err != nil {
+			return err
+		}
+	}
+
+	// there is no chance die; the smallest pool is one die
+	rs.Dice = util.Max(rs.Dice, 1)
+	if rs.Dice > MaxV5Dice {
+		return ErrV5Dice
+	}
+	hunger := util.Min(rs.Hunger, rs.Dice)
+
+	rolls, err := rs.rand(rs.Dice, 1, 10)
+	if err != nil {
+		return err
+	}
+	rs.Results.Rolls = rolls[:rs.Dice-hunger]
+	rs.Results.Hunger = rolls[rs.Dice-hunger:]
+
+	if rs.Reroll {
+		for i, roll := range rs.Results.Rolls {
+			if roll < 6 && len(rs.Results.Rerolled) < V5RerollDice {
+				rs.Results.Rerolled = append(rs.Results.Rerolled, i)
+			}
+		}
+		if len(rs.Results.Rerolled) > 0 {
+			rs.Results.Rerolls, err = rs.rand(len(rs.Results.Rerolled), 1, 10)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	rs.score()
+	return nil
+}
+
+// regular lists the regular dice as they stand after any Willpower rerolls
+func (rs *V5RollSystem) regular() []int64 {
+	dice := append([]int64(nil), rs.Results.Rolls...)
+	for i, die := range rs.Results.Rerolled {
+		dice[die] = rs.Results.Rerolls[i]
+	}
+	return dice
+}
+
+// score counts the successes, where each pair of 10s is a critical worth four
+func (rs *V5RollSystem) score() {
+	var tens, hungerTens int
+	var hungerOnes bool
+	count := func(roll int64, hunger bool) {
+		if roll >= 6 {
+			rs.Results.Successes++
+		}
+		if roll == 10 {
+			tens++
+			if hunger {
+				hungerTens++
+			}
+		}
+		if roll == 1 && hunger {
+			hungerOnes = true
+		}
+	}
+	for _, roll := range rs.regular() {
+		count(roll, false)
+	}
+	for _, roll := range rs.Results.Hunger {
+		count(roll, true)
+	}
+
+	rs.Results.Criticals = tens / 2
+	rs.Results.Successes += rs.Results.Criticals * 2
+	rs.Results.Messy = rs.won() && rs.Results.Criticals > 0 && hungerTens > 0
+	rs.Results.Bestial = !rs.won() && hungerOnes
+}
+
+// won is whether the roll met its difficulty, or rolled any success without one
+func (rs *V5RollSystem) won() bool {
+	return rs.Results.Successes >= util.Max(rs.Difficulty, 1)
+}
+
+// ToString converts the Results to a string.
+func (rs *V5RollSystem) ToString() string {
+	var buff bytes.Buffer
+
+	if rs.Rouse {
+		buff.WriteString(fmt.Sprintf("made a Rouse check and rolled %d.", rs.Results.Rolls[0]))
+		if rs.Results.Successes == 0 {
+			buff.WriteString(" Hunger rises!")
+		}
+		return buff.String()
+	}
+
+	buff.WriteString(fmt.Sprintf("rolled %d V5 Dice", rs.Dice))
+
+	var flags []string
+	if hunger := len(rs.Results.Hunger); hunger > 0 {
+		flags = append(flags, fmt.Sprintf("%d Hunger", hunger))
+	}
+	if rs.Difficulty > 0 {
+		flags = append(flags, fmt.Sprintf("Difficulty %d", rs.Difficulty))
+	}
+	if rs.Reroll {
+		flags = append(flags, "Willpower")
+	}
+	if len(flags) > 0 {
+		buff.WriteString(fmt.Sprintf(" (with %s)", strings.Join(flags, ", ")))
+	}
+
+	buff.WriteString(fmt.Sprintf(" for %d Successes.", rs.Results.Successes))
+
+	switch {
+	case rs.Results.Messy:
+		buff.WriteString(" Messy critical!")
+	case rs.Results.Bestial:
+		buff.WriteString(" Bestial failure!")
+	case rs.won() && rs.Results.Criticals > 0:
+		buff.WriteString(" Critical win!")
+	}
+
+	// add Rolls, Hunger dice and Rerolls if desired
+	if rs.Verbose {
+		buff.WriteString(fmt.Sprintf(" Rolls: %d", rs.Results.Rolls))
+
+		if len(rs.Results.Hunger) > 0 {
+			buff.WriteString(fmt.Sprintf(" Hunger: %d", rs.Results.Hunger))
+		}
+
+		if len(rs.Results.Rerolls) > 0 {
+			buff.WriteString(fmt.Sprintf(" Rerolls: %d", rs.Results.Rerolls))
+		}
+	}
+
+	return buff.String()
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package roll
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type V5TestSuite struct {
+	suite.Suite
+}
+
+func TestV5(t *testing.T) {
+	suite.Run(t, new(V5TestSuite))
+}
+
+func (suite *V5TestSuite) TestRoll() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{2, 6, 9, 3, 1}, nil), Hunger: 2}
+	err := o.Roll(context.Background(), []string{"3+2"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, o.Results.Successes)
+	assert.Equal(suite.T(), []int64{2, 6, 9}, o.Results.Rolls)
+	assert.Equal(suite.T(), []int64{3, 1}, o.Results.Hunger)
+	assert.Equal(suite.T(), OutcomeSuccess, o.Outcome())
+	assert.Equal(suite.T(), "rolled 5 V5 Dice (with 2 Hunger) for 2 Successes.", o.ToString())
+}
+
+func (suite *V5TestSuite) TestRollCritical() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{10, 10, 10, 4}, nil), Verbose: true}
+	err := o.Roll(context.Background(), []string{"4"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, o.Results.Successes)
+	assert.Equal(suite.T(), 1, o.Results.Criticals)
+	assert.False(suite.T(), o.Results.Messy)
+	assert.Equal(suite.T(), OutcomeExceptional, o.Outcome())
+	assert.Equal(suite.T(), "rolled 4 V5 Dice for 5 Successes. Critical win! Rolls: [10 10 10 4]", o.ToString())
+}
+
+func (suite *V5TestSuite) TestRollMessyCritical() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{10, 3, 10}, nil), Hunger: 1, Verbose: true}
+	err := o.Roll(context.Background(), []string{"3"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, o.Results.Successes)
+	assert.True(suite.T(), o.Results.Messy)
+	assert.Equal(suite.T(), OutcomeExceptional, o.Outcome())
+	assert.Equal(suite.T(), "rolled 3 V5 Dice (with 1 Hunger) for 4 Successes. Messy critical! Rolls: [10 3] Hunger: [10]", o.ToString())
+}
+
+func (suite *V5TestSuite) TestRollBestialFailure() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{7, 8, 1}, nil), Hunger: 1, Difficulty: 3}
+	err := o.Roll(context.Background(), []string{"3"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, o.Results.Successes)
+	assert.True(suite.T(), o.Results.Bestial)
+	assert.Equal(suite.T(), OutcomeDramaticFailure, o.Outcome())
+	assert.Equal(suite.T(), "rolled 3 V5 Dice (with 1 Hunger, Difficulty 3) for 2 Successes. Bestial failure!", o.ToString())
+}
+
+func (suite *V5TestSuite) TestRollHungerOneOnWin() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{7, 1}, nil), Hunger: 1}
+	err := o.Roll(context.Background(), []string{"2"})
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), o.Results.Bestial)
+	assert.Equal(suite.T(), OutcomeSuccess, o.Outcome())
+}
+
+func (suite *V5TestSuite) TestRollHungerCapped() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{4, 5}, nil), Hunger: 4}
+	err := o.Roll(context.Background(), []string{"2"})
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), o.Results.Rolls)
+	assert.Equal(suite.T(), []int64{4, 5}, o.Results.Hunger)
+	assert.Equal(suite.T(), OutcomeFailure, o.Outcome())
+}
+
+func (suite *V5TestSuite) TestRollInvalidHunger() {
+	o := &V5RollSystem{rand: cofdMockRoller(nil, nil), Hunger: 6}
+	err := o.Roll(context.Background(), []string{"2"})
+	assert.Equal(suite.T(), ErrHunger, err)
+}
+
+func (suite *V5TestSuite) TestRollTooManyDice() {
+	o := &V5RollSystem{rand: cofdMockRoller(nil, nil)}
+	err := o.Roll(context.Background(), []string{"5000000"})
+	assert.Equal(suite.T(), ErrV5Dice, err)
+}
+
+func (suite *V5TestSuite) TestRollReroll() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{1, 2, 6, 3, 4, 5}, []int64{6, 10, 2}), Hunger: 1, Reroll: true, Verbose: true}
+	err := o.Roll(context.Background(), []string{"6"})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int{0, 1, 3}, o.Results.Rerolled)
+	assert.Equal(suite.T(), []int64{6, 10, 6, 2, 4}, o.regular())
+	assert.Equal(suite.T(), 3, o.Results.Successes)
+	assert.Equal(suite.T(), []int64{1, 2, 6, 3, 4, 5, 6, 10, 2}, o.Rolled())
+	assert.Equal(suite.T(), "rolled 6 V5 Dice (with 1 Hunger, Willpower) for 3 Successes. Rolls: [1 2 6 3 4] Hunger: [5] Rerolls: [6 10 2]", o.ToString())
+}
+
+func (suite *V5TestSuite) TestRouse() {
+	o := &V5RollSystem{rand: cofdMockRoller([]int64{4}, nil), Rouse: true}
+	err := o.Roll(context.Background(), nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), OutcomeFailure, o.Outcome())
+	assert.Equal(suite.T(), "made a Rouse check and rolled 4. Hunger rises!", o.ToString())
+
+	o = &V5RollSystem{rand: cofdMockRoller([]int64{7}, nil), Rouse: true}
+	err = o.Roll(context.Background(), nil)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), OutcomeSuccess, o.Outcome())
+	assert.Equal(suite.T(), "made a Rouse check and rolled 7.", o.ToString())
+}
@@ -31,8 +31,8 @@ type BaseCofD2e struct {
 	Notes       []Note `json:"notes"`
 
 	// Traits
-	Aspirations []string `json:"aspirations"`
-	Conditions  string   `json:"conditions"`
+	Aspirations []string   `json:"aspirations"`
+	Conditions  Conditions `json:"conditions"`
 	Health      struct {
 		Max        int `json:"max"`
 		Aggravated int `json:"aggravated"`
@@ -118,6 +118,7 @@ func NewBaseCofD2e() *BaseCofD2e {
 	sheet.Size = 5
 	sheet.Notes = make([]Note, 0)
 	sheet.Aspirations = make([]string, 0)
+	sheet.Conditions = make(Conditions, 0)
 	sheet.Merits = make([]CofD2eMerit, 0)
 	return sheet
 }
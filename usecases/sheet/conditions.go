@@ -0,0 +1,234 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrConditionName is thrown when a condition is added without a name
+var ErrConditionName = errors.New("a condition needs a name")
+
+// ErrDuplicateCondition is thrown when a character is given a condition they already have
+var ErrDuplicateCondition = errors.New("the character already has that condition")
+
+// ErrConditionNotFound is thrown when a condition the character does not have is resolved
+var ErrConditionNotFound = errors.New("the character does not have that condition")
+
+// ErrModifier is thrown when a dice modifier is not written as trait:dice
+var ErrModifier = errors.New("dice modifiers are written as trait:dice, such as all:-2 or dexterity:-1")
+
+// ErrNoConditions is thrown when conditions are given to a sheet which cannot have them
+var ErrNoConditions = errors.New("that sheet cannot have conditions")
+
+// beatsPerExperience is the number of beats which make an experience
+const beatsPerExperience = 5
+
+// allTraits is the trait of a dice modifier which applies to every roll
+const allTraits = "all"
+
+// A Condition is a lasting effect on a character, such as Shaken, or a Tilt, which lasts only for
+// a scene or a combat
+type Condition struct {
+	Name       string         `json:"name"`
+	Source     string         `json:"source"`
+	Tilt       bool           `json:"tilt"`
+	Persistent bool           `json:"persistent"`
+	Resolution string         `json:"resolution"`
+	Beat       bool           `json:"beat"` // whether resolving the condition earns a beat
+	Modifiers  []DiceModifier `json:"modifiers"`
+}
+
+// A DiceModifier adds dice to, or takes them from, the rolls which use a trait, or every roll
+type DiceModifier struct {
+	Trait string `json:"trait"`
+	Dice  int    `json:"dice"`
+}
+
+// Conditions are the conditions and tilts on a character
+type Conditions []Condition
+
+// UnmarshalJSON reads conditions, including those of sheets which kept their conditions as free
+// text, whose text becomes a single condition
+func (c *Conditions) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*c = make(Conditions, 0)
+		if strings.TrimSpace(text) != "" {
+			*c = append(*c, Condition{Name: strings.TrimSpace(text)})
+		}
+		return nil
+	}
+	var conditions []Condition
+	err := json.Unmarshal(data, &conditions)
+	if err != nil {
+		return err
+	}
+	*c = conditions
+	return nil
+}
+
+// ParseModifiers reads a list of dice modifiers such as all:-2,dexterity:-1
+func ParseModifiers(value string) ([]DiceModifier, error) {
+	modifiers := make([]DiceModifier, 0)
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		pieces := strings.SplitN(part, ":", 2)
+		if len(pieces) != 2 {
+			return nil, ErrModifier
+		}
+		dice, err := strconv.Atoi(strings.TrimSpace(pieces[1]))
+		if err != nil {
+			return nil, ErrModifier
+		}
+		modifiers = append(modifiers, DiceModifier{Trait: TraitKey(pieces[0]), Dice: dice})
+	}
+	return modifiers, nil
+}
+
+// AddCondition gives a character a condition or tilt, and keeps a revision of the changed sheet
+func AddCondition(ctx context.Context, db domains.CharacterRepository, revisions domains.SheetRevisionRepository, char *domains.Character, c Condition, player string) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return ErrConditionName
+	}
+	base := baseOf(char.Sheet)
+	if base == nil {
+		return ErrNoConditions
+	}
+	if findCondition(base.Conditions, c.Name) >= 0 {
+		return ErrDuplicateCondition
+	}
+	base.Conditions = append(base.Conditions, c)
+	return revise(ctx, db, revisions, char, player, fmt.Sprintf("gained the %s condition", c.Name))
+}
+
+// ResolveCondition removes a condition or tilt from a character, and keeps a revision of the
+// changed sheet.  A resolved condition which earns a beat awards it, and every five beats become an
+// experience.  A condition which is only removed, as when a tilt ends, earns nothing.
+func ResolveCondition(ctx context.Context, db domains.CharacterRepository, revisions domains.SheetRevisionRepository, char *domains.Character, name string, resolved bool, player string) (*Condition, bool, error) {
+	base := baseOf(char.Sheet)
+	if base == nil {
+		return nil, false, ErrNoConditions
+	}
+	n := findCondition(base.Conditions, name)
+	if n < 0 {
+		return nil, false, ErrConditionNotFound
+	}
+	c := base.Conditions[n]
+	base.Conditions = append(base.Conditions[:n], base.Conditions[n+1:]...)
+	beat := resolved && c.Beat
+	reason := fmt.Sprintf("removed the %s condition", c.Name)
+	if resolved {
+		reason = fmt.Sprintf("resolved the %s condition", c.Name)
+	}
+	if beat {
+		base.Beats++
+		if base.Beats >= beatsPerExperience {
+			base.Beats -= beatsPerExperience
+			base.Experiences++
+		}
+		reason += " for a beat"
+	}
+	err := revise(ctx, db, revisions, char, player, reason)
+	if err != nil {
+		return nil, false, err
+	}
+	return &c, beat, nil
+}
+
+// SheetPool adds up a dice pool from the traits of a sheet, like Pool, along with the dice modifiers
+// of the character's conditions for the traits it uses.  The names of the conditions which changed
+// the pool are returned with it.
+func SheetPool(sh domains.Sheet, pool string) (int, []string, error) {
+	dice, err := Pool(sh, pool)
+	if err != nil {
+		return 0, nil, err
+	}
+	applied := make([]string, 0)
+	base := baseOf(sh)
+	if base == nil {
+		return dice, applied, nil
+	}
+	used := make(map[string]bool)
+	for _, term := range strings.FieldsFunc(pool, func(r rune) bool { return r == '+' || r == '-' }) {
+		used[TraitKey(term)] = true
+	}
+	for _, c := range base.Conditions {
+		changed := false
+		for _, m := range c.Modifiers {
+			if m.Trait == allTraits || used[m.Trait] {
+				dice += m.Dice
+				changed = true
+			}
+		}
+		if changed {
+			applied = append(applied, c.Name)
+		}
+	}
+	return dice, applied, nil
+}
+
+// revise stores a changed character and keeps a revision of its sheet
+func revise(ctx context.Context, db domains.CharacterRepository, revisions domains.SheetRevisionRepository, char *domains.Character, player, reason string) error {
+	err := db.Store(ctx, char)
+	if err != nil {
+		return errors.Wrap(err, "could not store the character")
+	}
+	revision := &domains.SheetRevision{Character: char.ID, Player: player, Reason: reason, Sheet: char.Sheet}
+	err = revisions.Store(ctx, revision)
+	if err != nil {
+		return errors.Wrap(err, "could not store the sheet revision")
+	}
+	return nil
+}
+
+// baseOf finds the part of a sheet every Chronicles of Darkness sheet shares
+func baseOf(sh domains.Sheet) *BaseCofD2e {
+	switch s := sh.(type) {
+	case *CofD2e:
+		return s.BaseCofD2e
+	case *WtF2e:
+		return s.BaseCofD2e
+	case *CofD2eSpirit:
+		return s.BaseCofD2e
+	}
+	return nil
+}
+
+// findCondition finds the index of a condition by name, ignoring case, or -1
+func findCondition(conditions Conditions, name string) int {
+	for n, c := range conditions {
+		if strings.EqualFold(c.Name, strings.TrimSpace(name)) {
+			return n
+		}
+	}
+	return -1
+}
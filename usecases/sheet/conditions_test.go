@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionsSuite struct {
+	suite.Suite
+}
+
+func TestConditions(t *testing.T) {
+	suite.Run(t, new(ConditionsSuite))
+}
+
+func (suite *ConditionsSuite) TestUnmarshalFreeText() {
+	sh := GenerateSheetBySystem("cofd2e", json.RawMessage(`{"conditions":"Shaken"}`)).(*CofD2e)
+	assert.Equal(suite.T(), Conditions{{Name: "Shaken"}}, sh.Conditions)
+	sh = GenerateSheetBySystem("cofd2e", json.RawMessage(`{"conditions":""}`)).(*CofD2e)
+	assert.Empty(suite.T(), sh.Conditions)
+	sh = GenerateSheetBySystem("cofd2e", json.RawMessage(`{"conditions":[{"name":"Guilty","beat":true}]}`)).(*CofD2e)
+	assert.Equal(suite.T(), Conditions{{Name: "Guilty", Beat: true}}, sh.Conditions)
+}
+
+func (suite *ConditionsSuite) TestParseModifiers() {
+	modifiers, err := ParseModifiers("all:-2, Animal Ken:1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []DiceModifier{{Trait: "all", Dice: -2}, {Trait: "animalken", Dice: 1}}, modifiers)
+	_, err = ParseModifiers("all-2")
+	assert.Equal(suite.T(), ErrModifier, err)
+}
+
+func (suite *ConditionsSuite) TestAddAndResolve() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	revisions := domains.NewMockSheetRevisionRepository(ctrl)
+	id := snowflake.ID(42)
+	sh := NewCofD2e()
+	sh.Beats = 4
+	char := &domains.Character{ID: &id, Sheet: sh}
+	db.EXPECT().Store(ctx, char).Return(nil).Times(4)
+	revisions.EXPECT().Store(ctx, gomock.Any()).Return(nil).Times(3)
+	revisions.EXPECT().Store(ctx, gomock.Any()).Do(func(ctx context.Context, r *domains.SheetRevision) {
+		assert.Equal(suite.T(), "resolved the Guilty condition for a beat", r.Reason)
+	}).Return(nil)
+
+	err := AddCondition(ctx, db, revisions, char, Condition{Name: "Guilty", Beat: true}, "7")
+	assert.Nil(suite.T(), err)
+	err = AddCondition(ctx, db, revisions, char, Condition{Name: "Stunned", Tilt: true}, "7")
+	assert.Nil(suite.T(), err)
+	err = AddCondition(ctx, db, revisions, char, Condition{Name: "guilty"}, "7")
+	assert.Equal(suite.T(), ErrDuplicateCondition, err)
+	err = AddCondition(ctx, db, revisions, char, Condition{Name: " "}, "7")
+	assert.Equal(suite.T(), ErrConditionName, err)
+
+	c, beat, err := ResolveCondition(ctx, db, revisions, char, "stunned", true, "7")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Stunned", c.Name)
+	assert.False(suite.T(), beat)
+	_, beat, err = ResolveCondition(ctx, db, revisions, char, "Guilty", true, "7")
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), beat)
+	assert.Equal(suite.T(), 0, sh.Beats)
+	assert.Equal(suite.T(), 1, sh.Experiences)
+	assert.Empty(suite.T(), sh.Conditions)
+	_, _, err = ResolveCondition(ctx, db, revisions, char, "Guilty", true, "7")
+	assert.Equal(suite.T(), ErrConditionNotFound, err)
+}
+
+func (suite *ConditionsSuite) TestSheetPool() {
+	sh := NewCofD2e()
+	sh.Dexterity = 3
+	sh.Athletics.Dots = 2
+	sh.Conditions = Conditions{
+		{Name: "Leg Wrack", Tilt: true, Modifiers: []DiceModifier{{Trait: "dexterity", Dice: -2}}},
+		{Name: "Inspired", Modifiers: []DiceModifier{{Trait: "occult", Dice: 1}}},
+		{Name: "Drugged", Modifiers: []DiceModifier{{Trait: "all", Dice: -1}}},
+	}
+	dice, applied, err := SheetPool(sh, "dexterity+athletics")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, dice)
+	assert.Equal(suite.T(), []string{"Leg Wrack", "Drugged"}, applied)
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatSlate is the format of a character exported from slate
+	FormatSlate = "slate"
+	// FormatFoundry is the format of an actor exported from Foundry VTT's CofD system
+	FormatFoundry = "foundry"
+)
+
+// ErrInvalidSheetSystem is thrown when an invalid sheet system is selected
+var ErrInvalidSheetSystem = errors.New("sheet system must be one of: cofd2e, cofd2e-spirit, wtf2e")
+
+// ErrUnknownImportFormat is thrown when an import bundle is in a format we do not understand
+var ErrUnknownImportFormat = errors.New("import must be a slate export or a foundry vtt actor")
+
+// An ImportReport describes how an imported character was mapped onto a sheet.
+type ImportReport struct {
+	Format   string   `json:"format"`
+	Unmapped []string `json:"unmapped"`
+}
+
+// Import creates a new character sheet from an exported bundle and stores it.  The system and
+// name, if specified, override whatever the bundle describes.
+func Import(ctx context.Context, db domains.CharacterRepository, data []byte, name, system string, guild, player int64) (*domains.Character, *ImportReport, error) {
+	var top map[string]json.RawMessage
+	err := json.Unmarshal(data, &top)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode import")
+	}
+
+	var character *domains.Character
+	var report *ImportReport
+	switch {
+	case top["sheet"] != nil:
+		character, report, err = importSlate(data, system)
+	case top["type"] != nil && (top["system"] != nil || top["data"] != nil):
+		character, report, err = importFoundry(data, system)
+	default:
+		err = ErrUnknownImportFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if name != "" {
+		character.Name = name
+	}
+	character.Guild = strconv.FormatInt(guild, 10)
+	character.Player = strconv.FormatInt(player, 10)
+	err = db.Store(ctx, character)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not store imported sheet")
+	}
+	return character, report, nil
+}
+
+type slateExport struct {
+	Name   string          `json:"name"`
+	System string          `json:"system"`
+	Sheet  json.RawMessage `json:"sheet"`
+}
+
+func importSlate(data []byte, system string) (*domains.Character, *ImportReport, error) {
+	var export slateExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode slate export")
+	}
+	if system == "" {
+		system = export.System
+	}
+	sh := GenerateSheetBySystem(system, export.Sheet)
+	if sh == nil {
+		return nil, nil, ErrInvalidSheetSystem
+	}
+
+	character := new(domains.Character)
+	character.Name = export.Name
+	character.System = system
+	character.Sheet = sh
+
+	report := &ImportReport{Format: FormatSlate}
+	report.Unmapped, err = unmappedFields(export.Sheet, sh)
+	if err != nil {
+		return nil, nil, err
+	}
+	return character, report, nil
+}
+
+// unmappedFields compares the raw sheet against the generated sheet, and lists every field of the
+// raw sheet that did not survive the trip.
+func unmappedFields(raw json.RawMessage, sh domains.Sheet) ([]string, error) {
+	var in map[string]interface{}
+	err := json.Unmarshal(raw, &in)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode sheet")
+	}
+	body, err := json.Marshal(sh)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal sheet")
+	}
+	var out map[string]interface{}
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode sheet")
+	}
+	missing := make([]string, 0)
+	compareFields("", in, out, &missing)
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func compareFields(prefix string, in, out map[string]interface{}, missing *[]string) {
+	for key, value := range in {
+		found, ok := out[key]
+		if !ok {
+			*missing = append(*missing, prefix+key)
+			continue
+		}
+		inner, iok := value.(map[string]interface{})
+		outer, ook := found.(map[string]interface{})
+		if iok && ook {
+			compareFields(prefix+key+".", inner, outer, missing)
+		}
+	}
+}
+
+type foundryActor struct {
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Data   map[string]interface{} `json:"data"`
+	System map[string]interface{} `json:"system"`
+	Items  []struct {
+		Name   string                 `json:"name"`
+		Type   string                 `json:"type"`
+		Data   map[string]interface{} `json:"data"`
+		System map[string]interface{} `json:"system"`
+	} `json:"items"`
+}
+
+// foundryFields maps the leaves of a Foundry VTT CofD actor onto the fields of a slate sheet
+var foundryFields = map[string]string{
+	"attributes_mental.intelligence.value": "intelligence",
+	"attributes_mental.wits.value":         "wits",
+	"attributes_mental.resolve.value":      "resolve",
+	"attributes_physical.strength.value":   "strength",
+	"attributes_physical.dexterity.value":  "dexterity",
+	"attributes_physical.stamina.value":    "stamina",
+	"attributes_social.presence.value":     "presence",
+	"attributes_social.manipulation.value": "manipulation",
+	"attributes_social.composure.value":    "composure",
+	"health.max":                           "health.max",
+	"health.aggravated":                    "health.aggravated",
+	"health.lethal":                        "health.lethal",
+	"health.bashing":                       "health.bashing",
+	"willpower.value":                      "willpower.current",
+	"willpower.max":                        "willpower.max",
+	"size":                                 "size",
+	"beats":                                "beats",
+	"experience":                           "experiences",
+	"concept":                              "concept",
+	"chronicle":                            "chronicle",
+	"virtue":                               "virtue",
+	"vice":                                 "vice",
+	"faction":                              "faction",
+	"integrity":                            "integrity",
+	"werewolf_traits.harmony.value":        "harmony",
+	"werewolf_traits.primalUrge.value":     "primal_urge",
+	"werewolf_traits.essence.value":        "essence.current",
+	"werewolf_traits.essence.max":          "essence.max",
+	"werewolf_renown.cunning.value":        "cunning",
+	"werewolf_renown.glory.value":          "glory",
+	"werewolf_renown.honor.value":          "honor",
+	"werewolf_renown.purity.value":         "purity",
+	"werewolf_renown.wisdom.value":         "wisdom",
+	"werewolf_traits.auspice":              "auspice",
+	"werewolf_traits.tribe":                "tribe",
+	"werewolf_traits.lodge":                "lodge",
+	"werewolf_traits.blood":                "blood",
+	"werewolf_traits.bone":                 "bone",
+	"werewolf_traits.kuruthTriggers":       "kuruth_triggers",
+	"werewolf_traits.touchstones.flesh":    "touchstones.flesh",
+	"werewolf_traits.touchstones.spirit":   "touchstones.spirit",
+}
+
+// foundrySkills maps the Foundry VTT skill names onto the skills of a slate sheet
+var foundrySkills = map[string]string{
+	"academics":     "academics",
+	"computer":      "computer",
+	"crafts":        "crafts",
+	"investigation": "investigation",
+	"medicine":      "medicine",
+	"occult":        "occult",
+	"politics":      "politics",
+	"science":       "science",
+	"athletics":     "athletics",
+	"brawl":         "brawl",
+	"drive":         "drive",
+	"firearms":      "firearms",
+	"larceny":       "larceny",
+	"stealth":       "stealth",
+	"survival":      "survival",
+	"weaponry":      "weaponry",
+	"animalKen":     "animal_ken",
+	"empathy":       "empathy",
+	"expression":    "expression",
+	"intimidation":  "intimidation",
+	"persuasion":    "persuasion",
+	"socialize":     "socialize",
+	"streetwise":    "streetwise",
+	"subterfuge":    "subterfuge",
+}
+
+// foundrySystems maps the Foundry VTT character types onto slate sheet systems
+var foundrySystems = map[string]string{
+	"Mortal":   "cofd2e",
+	"Werewolf": "wtf2e",
+	"Spirit":   "cofd2e-spirit",
+}
+
+func importFoundry(data []byte, system string) (*domains.Character, *ImportReport, error) {
+	var actor foundryActor
+	err := json.Unmarshal(data, &actor)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode foundry actor")
+	}
+	fields := actor.System
+	if fields == nil {
+		fields = actor.Data
+	}
+	if system == "" {
+		ct, _ := fields["characterType"].(string)
+		system = foundrySystems[ct]
+		if system == "" {
+			system = "cofd2e"
+		}
+	}
+	delete(fields, "characterType")
+
+	report := &ImportReport{Format: FormatFoundry, Unmapped: make([]string, 0)}
+	mapped := make(map[string]interface{})
+	for _, leaf := range flattenFields("", fields) {
+		target, value, ok := foundryField(leaf)
+		if !ok {
+			report.Unmapped = append(report.Unmapped, leaf.path)
+			continue
+		}
+		setField(mapped, target, value)
+	}
+
+	merits := make([]CofD2eMerit, 0)
+	for _, item := range actor.Items {
+		idata := item.System
+		if idata == nil {
+			idata = item.Data
+		}
+		if item.Type != "merit" {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("items.%s (%s)", item.Name, item.Type))
+			continue
+		}
+		dots, _ := idata["rating"].(float64)
+		merits = append(merits, CofD2eMerit{Name: item.Name, Dots: int(dots)})
+	}
+	mapped["merits"] = merits
+	sort.Strings(report.Unmapped)
+
+	body, err := json.Marshal(mapped)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not marshal mapped sheet")
+	}
+	sh := GenerateSheetBySystem(system, body)
+	if sh == nil {
+		return nil, nil, ErrInvalidSheetSystem
+	}
+	character := new(domains.Character)
+	character.Name = actor.Name
+	character.System = system
+	character.Sheet = sh
+	return character, report, nil
+}
+
+// foundryField finds the slate field for a Foundry VTT leaf, converting its value if needed
+func foundryField(leaf fieldLeaf) (string, interface{}, bool) {
+	if target, ok := foundryFields[leaf.path]; ok {
+		return target, leaf.value, true
+	}
+	parts := strings.Split(leaf.path, ".")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "skills_") {
+		return "", nil, false
+	}
+	skill, ok := foundrySkills[parts[1]]
+	if !ok {
+		return "", nil, false
+	}
+	switch parts[2] {
+	case "value":
+		return skill + ".dots", leaf.value, true
+	case "specialties":
+		if list, ok := leaf.value.([]interface{}); ok {
+			specialties := make([]string, 0, len(list))
+			for _, specialty := range list {
+				specialties = append(specialties, fmt.Sprint(specialty))
+			}
+			return skill + ".specialties", strings.Join(specialties, ", "), true
+		}
+		return skill + ".specialties", leaf.value, true
+	}
+	return "", nil, false
+}
+
+type fieldLeaf struct {
+	path  string
+	value interface{}
+}
+
+func flattenFields(prefix string, fields map[string]interface{}) []fieldLeaf {
+	leaves := make([]fieldLeaf, 0)
+	for key, value := range fields {
+		if inner, ok := value.(map[string]interface{}); ok {
+			leaves = append(leaves, flattenFields(prefix+key+".", inner)...)
+			continue
+		}
+		leaves = append(leaves, fieldLeaf{prefix + key, value})
+	}
+	return leaves
+}
+
+func setField(fields map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		inner, ok := fields[key].(map[string]interface{})
+		if !ok {
+			inner = make(map[string]interface{})
+			fields[key] = inner
+		}
+		fields = inner
+	}
+	fields[keys[len(keys)-1]] = value
+}
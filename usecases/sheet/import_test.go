@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportSuite struct {
+	suite.Suite
+}
+
+func TestImport(t *testing.T) {
+	suite.Run(t, new(ImportSuite))
+}
+
+func (suite *ImportSuite) TestImportSlate() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	raw := `{"id":"1","name":"Cahalith","guild":"9","player":"9","playerName":"x","system":"wtf2e",` +
+		`"sheet":{"strength":3,"glory":2,"brawl":{"dots":2,"specialties":"Claws"},"pack":"Bloodline","health":{"max":7,"temp":1}}}`
+	ch, report, err := Import(ctx, db, []byte(raw), "", "", int64(1234), int64(5678))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Cahalith", ch.Name)
+	assert.Equal(suite.T(), "1234", ch.Guild)
+	assert.Equal(suite.T(), "5678", ch.Player)
+	sh := ch.Sheet.(*WtF2e)
+	assert.Equal(suite.T(), 3, sh.Strength)
+	assert.Equal(suite.T(), 2, sh.Glory)
+	assert.Equal(suite.T(), "Claws", sh.Brawl.Specialties)
+	assert.Equal(suite.T(), FormatSlate, report.Format)
+	assert.Equal(suite.T(), []string{"health.temp", "pack"}, report.Unmapped)
+}
+
+func (suite *ImportSuite) TestImportSlateInvalidSystem() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	raw := `{"name":"Nobody","system":"dnd5e","sheet":{}}`
+	_, _, err := Import(ctx, db, []byte(raw), "", "", int64(1), int64(1))
+	assert.Equal(suite.T(), ErrInvalidSheetSystem, err)
+}
+
+func (suite *ImportSuite) TestImportFoundry() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	db.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+	raw := `{"name":"Marcus","type":"character","img":"icon.png","system":{"characterType":"Mortal",` +
+		`"attributes_physical":{"strength":{"value":2}},"attributes_mental":{"wits":{"value":4}},` +
+		`"skills_social":{"animalKen":{"value":1,"specialties":["Dogs","Horses"]}},` +
+		`"willpower":{"value":3,"max":5},"integrity":6,"conditions":"Shaken"},` +
+		`"items":[{"name":"Resources","type":"merit","system":{"rating":2}},{"name":"Crowbar","type":"equipment","system":{}}]}`
+	ch, report, err := Import(ctx, db, []byte(raw), "Marcus Reed", "", int64(1), int64(2))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Marcus Reed", ch.Name)
+	assert.Equal(suite.T(), "cofd2e", ch.System)
+	sh := ch.Sheet.(*CofD2e)
+	assert.Equal(suite.T(), 2, sh.Strength)
+	assert.Equal(suite.T(), 4, sh.Wits)
+	assert.Equal(suite.T(), 1, sh.Intelligence)
+	assert.Equal(suite.T(), 1, sh.AnimalKen.Dots)
+	assert.Equal(suite.T(), "Dogs, Horses", sh.AnimalKen.Specialties)
+	assert.Equal(suite.T(), IntWithMax{Current: 3, Max: 5}, sh.Willpower)
+	assert.Equal(suite.T(), 6, sh.Integrity)
+	assert.Equal(suite.T(), []CofD2eMerit{{Name: "Resources", Dots: 2}}, sh.Merits)
+	assert.Equal(suite.T(), FormatFoundry, report.Format)
+	assert.Equal(suite.T(), []string{"conditions", "items.Crowbar (equipment)"}, report.Unmapped)
+}
+
+func (suite *ImportSuite) TestImportUnknownFormat() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	_, _, err := Import(ctx, db, []byte(`{"hello":"world"}`), "", "", int64(1), int64(1))
+	assert.Equal(suite.T(), ErrUnknownImportFormat, err)
+}
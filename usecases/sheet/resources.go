@@ -0,0 +1,100 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownResource is thrown when a resource is spent or gained which the sheet does not have
+var ErrUnknownResource = errors.New("sheet does not have that resource")
+
+// ErrResourceSpent is thrown when more points of a resource are spent than remain
+var ErrResourceSpent = errors.New("not enough points of that resource remain")
+
+// ErrResourceMax is thrown when a resource would gain points beyond its maximum
+var ErrResourceMax = errors.New("that resource cannot gain points beyond its maximum")
+
+// ErrResourcePoints is thrown when a resource is spent or gained by less than one point
+var ErrResourcePoints = errors.New("resources are spent or gained a point at a time")
+
+// Resources lists the resources which may be spent or gained on some sheet
+var Resources = []string{"willpower", "essence"}
+
+// Resource finds a resource of a sheet by name, ignoring case
+func Resource(sh domains.Sheet, name string) (*IntWithMax, error) {
+	var base *BaseCofD2e
+	var essence *IntWithMax
+	switch s := sh.(type) {
+	case *CofD2e:
+		base = s.BaseCofD2e
+	case *WtF2e:
+		base = s.BaseCofD2e
+		essence = &s.Essence
+	case *CofD2eSpirit:
+		base = s.BaseCofD2e
+		essence = &s.Essence
+	default:
+		return nil, ErrInvalidSheetSystem
+	}
+	switch strings.ToLower(name) {
+	case "willpower", "wp":
+		return &base.Willpower, nil
+	case "essence":
+		if essence != nil {
+			return essence, nil
+		}
+	}
+	return nil, errors.Wrap(ErrUnknownResource, name)
+}
+
+// Adjust spends points of a character's resource, or gains them if points is positive, and keeps
+// a revision of the changed sheet
+func Adjust(ctx context.Context, db domains.CharacterRepository, revisions domains.SheetRevisionRepository, char *domains.Character, resource string, points int, player string) (*IntWithMax, error) {
+	if points == 0 {
+		return nil, ErrResourcePoints
+	}
+	r, err := Resource(char.Sheet, resource)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case r.Current+points < 0:
+		return nil, ErrResourceSpent
+	case r.Current+points > r.Max:
+		return nil, ErrResourceMax
+	}
+	r.Current += points
+	reason := fmt.Sprintf("gained %d %s", points, strings.ToLower(resource))
+	if points < 0 {
+		reason = fmt.Sprintf("spent %d %s", -points, strings.ToLower(resource))
+	}
+	err = revise(ctx, db, revisions, char, player, reason)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResourcesSuite struct {
+	suite.Suite
+}
+
+func TestResources(t *testing.T) {
+	suite.Run(t, new(ResourcesSuite))
+}
+
+func (suite *ResourcesSuite) TestResource() {
+	r, err := Resource(NewWtF2e(), "Essence")
+	assert.Nil(suite.T(), err)
+	assert.NotNil(suite.T(), r)
+	_, err = Resource(NewCofD2e(), "essence")
+	assert.Equal(suite.T(), ErrUnknownResource, errors.Cause(err))
+	sh := NewCofD2e()
+	r, err = Resource(sh, "wp")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &sh.Willpower, r)
+}
+
+func (suite *ResourcesSuite) TestAdjust() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	revisions := domains.NewMockSheetRevisionRepository(ctrl)
+	id := snowflake.ID(42)
+	sh := NewCofD2e()
+	sh.Willpower = IntWithMax{Current: 2, Max: 4}
+	char := &domains.Character{ID: &id, Sheet: sh}
+	db.EXPECT().Store(ctx, char).Return(nil).Times(2)
+	revisions.EXPECT().Store(ctx, gomock.Any()).Do(func(ctx context.Context, r *domains.SheetRevision) {
+		assert.Equal(suite.T(), &id, r.Character)
+		assert.Equal(suite.T(), "7", r.Player)
+		assert.Equal(suite.T(), "spent 2 willpower", r.Reason)
+	}).Return(nil)
+	revisions.EXPECT().Store(ctx, gomock.Any()).Return(nil)
+
+	r, err := Adjust(ctx, db, revisions, char, "willpower", -2, "7")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, r.Current)
+	_, err = Adjust(ctx, db, revisions, char, "willpower", -1, "7")
+	assert.Equal(suite.T(), ErrResourceSpent, err)
+	r, err = Adjust(ctx, db, revisions, char, "willpower", 4, "7")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, r.Current)
+	_, err = Adjust(ctx, db, revisions, char, "willpower", 1, "7")
+	assert.Equal(suite.T(), ErrResourceMax, err)
+	_, err = Adjust(ctx, db, revisions, char, "willpower", 0, "7")
+	assert.Equal(suite.T(), ErrResourcePoints, err)
+}
@@ -29,6 +29,9 @@ import (
 	"strconv"
 )
 
+// Systems lists the names of the available sheet systems
+var Systems = []string{"cofd2e", "cofd2e-spirit", "wtf2e"}
+
 // New creates a new character sheet and stores it
 func New(ctx context.Context, db domains.CharacterRepository, name, system string, guild, player int64) (*domains.Character, error) {
 	character := new(domains.Character)
@@ -49,6 +52,19 @@ func Get(ctx context.Context, db domains.CharacterRepository, id snowflake.ID) (
 	return db.FindByID(ctx, id.String())
 }
 
+// SetChronicle records the name of the chronicle a character's sheet belongs to, if the sheet
+// has one
+func SetChronicle(sh domains.Sheet, name string) {
+	switch s := sh.(type) {
+	case *CofD2e:
+		s.Chronicle = name
+	case *WtF2e:
+		s.Chronicle = name
+	case *CofD2eSpirit:
+		s.Chronicle = name
+	}
+}
+
 // GenerateSheetBySystem generates a sheet by a specified system.  If the body is specified,
 // this function will also populate that sheet from json
 func GenerateSheetBySystem(system string, body json.RawMessage) domains.Sheet {
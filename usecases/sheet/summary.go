@@ -0,0 +1,356 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/kkragenbrink/slate/util"
+	"github.com/pkg/errors"
+)
+
+// ErrCharacterNotFound is thrown when a character could not be found by name
+var ErrCharacterNotFound = errors.New("could not find a character by that name")
+
+// ErrAmbiguousCharacter is thrown when more than one character matches a name
+var ErrAmbiguousCharacter = errors.New("more than one character matches; please specify a name")
+
+// ErrUnknownSection is thrown when a summary is requested for a section the sheet does not have
+var ErrUnknownSection = errors.New("sheet does not have that section")
+
+// A Summary is a compact, per-system rendering of a character sheet
+type Summary struct {
+	Title  string
+	System string
+	Fields []*SummaryField
+}
+
+// A SummaryField is a single titled block of a Summary, belonging to a section
+type SummaryField struct {
+	Section string
+	Name    string
+	Value   string
+	Inline  bool
+}
+
+// List lists a player's characters in a guild.  The sheets of the listed characters are not loaded.
+func List(ctx context.Context, db domains.CharacterRepository, guild, player int64) ([]*domains.Character, error) {
+	chars, err := db.FindByPlayer(ctx, strconv.FormatInt(player, 10))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find characters")
+	}
+	g := strconv.FormatInt(guild, 10)
+	listed := make([]*domains.Character, 0, len(chars))
+	for _, char := range chars {
+		if char.Guild == g {
+			listed = append(listed, char)
+		}
+	}
+	return listed, nil
+}
+
+// FindByName finds one of a player's characters in a guild by name.  If the name is empty and the
+// player has only one character in the guild, that character is found.
+func FindByName(ctx context.Context, db domains.CharacterRepository, guild, player int64, name string) (*domains.Character, error) {
+	chars, err := List(ctx, db, guild, player)
+	if err != nil {
+		return nil, err
+	}
+	return FindAmong(ctx, db, chars, name)
+}
+
+// FindInGuild finds any character in a guild by name, as a storyteller would.  If the name is
+// empty and the guild has only one character, that character is found.
+func FindInGuild(ctx context.Context, db domains.CharacterRepository, guild int64, name string) (*domains.Character, error) {
+	chars, err := db.FindByGuild(ctx, strconv.FormatInt(guild, 10))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find characters")
+	}
+	return FindAmong(ctx, db, chars, name)
+}
+
+// FindAmong finds a character by name from a list of characters, and loads its sheet.  If the name
+// is empty and there is only one character, that character is found.
+func FindAmong(ctx context.Context, db domains.CharacterRepository, chars []*domains.Character, name string) (*domains.Character, error) {
+	var found *domains.Character
+	for _, char := range chars {
+		if name != "" && !strings.EqualFold(char.Name, name) {
+			continue
+		}
+		if found != nil {
+			return nil, ErrAmbiguousCharacter
+		}
+		found = char
+	}
+	if found == nil {
+		return nil, ErrCharacterNotFound
+	}
+	return db.FindByID(ctx, found.ID.String())
+}
+
+// Summarize creates a summary of the character's sheet.  If a section is specified, only that
+// section is summarized.
+func Summarize(char *domains.Character, section string) (*Summary, error) {
+	summary := new(Summary)
+	summary.Title = char.Name
+	summary.System = char.System
+	switch sh := char.Sheet.(type) {
+	case *CofD2e:
+		summary.addCreature(sh.CofD2eCreature)
+		summary.addBase(sh.BaseCofD2e)
+		summary.add("traits", "Integrity", dots(sh.Integrity, 10), true)
+		summary.add("traits", "Virtue / Vice", orNone(strings.Trim(sh.Virtue+" / "+sh.Vice, " /")), true)
+	case *WtF2e:
+		summary.addCreature(sh.CofD2eCreature)
+		summary.addBase(sh.BaseCofD2e)
+		summary.add("renown", "Renown", strings.Join([]string{
+			"Cunning " + dots(sh.Cunning, 5),
+			"Glory " + dots(sh.Glory, 5),
+			"Honor " + dots(sh.Honor, 5),
+			"Purity " + dots(sh.Purity, 5),
+			"Wisdom " + dots(sh.Wisdom, 5),
+		}, "\n"), false)
+		summary.add("traits", "Primal Urge", dots(sh.PrimalUrge, 10), true)
+		summary.add("traits", "Harmony", dots(sh.Harmony, 10), true)
+		summary.add("traits", "Essence", track(sh.Essence), true)
+		moon := make([]string, 0)
+		for _, gift := range sh.Gifts.Moon {
+			if gift.List != "" {
+				moon = append(moon, gift.List+" "+dots(gift.Dots, 5))
+			}
+		}
+		summary.add("gifts", "Moon Gifts", list(moon), true)
+		summary.add("gifts", "Shadow Gifts", list(sh.Gifts.Shadow), true)
+		summary.add("gifts", "Wolf Gifts", list(sh.Gifts.Wolf), true)
+		summary.add("gifts", "Rites", list(sh.Rites), true)
+	case *CofD2eSpirit:
+		summary.add("attributes", "Attributes", strings.Join([]string{
+			"Power " + dots(sh.Power, 5),
+			"Finesse " + dots(sh.Finesse, 5),
+			"Resistance " + dots(sh.Resistance, 5),
+		}, "\n"), false)
+		summary.addBase(sh.BaseCofD2e)
+		summary.add("traits", "Rank", orNone(sh.Rank), true)
+		summary.add("traits", "Essence", track(sh.Essence), true)
+		summary.add("traits", "Ban / Bane", orNone(strings.Trim(sh.Ban+" / "+sh.Bane, " /")), true)
+		summary.add("numina", "Numina", list(sh.Numina), true)
+		summary.add("numina", "Influences", merits(sh.Influences), true)
+		summary.add("numina", "Manifestations", list(sh.Manifestations), true)
+	default:
+		return nil, ErrInvalidSheetSystem
+	}
+
+	if section == "" {
+		return summary, nil
+	}
+	fields := make([]*SummaryField, 0)
+	for _, field := range summary.Fields {
+		if field.Section == section {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.Wrap(ErrUnknownSection, fmt.Sprintf("sections: %s", strings.Join(summary.Sections(), ", ")))
+	}
+	summary.Fields = fields
+	return summary, nil
+}
+
+// Sections lists the sections of the summary, in order
+func (s *Summary) Sections() []string {
+	sections := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, field := range s.Fields {
+		if !seen[field.Section] {
+			seen[field.Section] = true
+			sections = append(sections, field.Section)
+		}
+	}
+	return sections
+}
+
+// Fold keeps a summary to a number of fields, folding those which do not fit into the last field
+func (s *Summary) Fold(limit int) {
+	if limit < 1 || len(s.Fields) <= limit {
+		return
+	}
+	overflow := s.Fields[limit-1:]
+	lines := make([]string, 0, len(overflow)*2)
+	for _, field := range overflow {
+		lines = append(lines, "**"+field.Name+"**", field.Value)
+	}
+	last := &SummaryField{Section: overflow[0].Section, Name: "More", Value: strings.Join(lines, "\n")}
+	s.Fields = append(s.Fields[:limit-1:limit-1], last)
+}
+
+func (s *Summary) add(section, name, value string, inline bool) {
+	s.Fields = append(s.Fields, &SummaryField{section, name, value, inline})
+}
+
+func (s *Summary) addCreature(c *CofD2eCreature) {
+	s.add("attributes", "Mental", strings.Join([]string{
+		"Intelligence " + dots(c.Intelligence, 5),
+		"Wits " + dots(c.Wits, 5),
+		"Resolve " + dots(c.Resolve, 5),
+	}, "\n"), true)
+	s.add("attributes", "Physical", strings.Join([]string{
+		"Strength " + dots(c.Strength, 5),
+		"Dexterity " + dots(c.Dexterity, 5),
+		"Stamina " + dots(c.Stamina, 5),
+	}, "\n"), true)
+	s.add("attributes", "Social", strings.Join([]string{
+		"Presence " + dots(c.Presence, 5),
+		"Manipulation " + dots(c.Manipulation, 5),
+		"Composure " + dots(c.Composure, 5),
+	}, "\n"), true)
+
+	skills := c.skills()
+	for i, name := range []string{"Mental Skills", "Physical Skills", "Social Skills"} {
+		lines := make([]string, 0)
+		for _, skill := range skills[i*8 : i*8+8] {
+			if skill.Dots == 0 {
+				continue
+			}
+			line := skill.name + " " + dots(skill.Dots, 5)
+			if skill.Specialties != "" {
+				line += " (" + skill.Specialties + ")"
+			}
+			lines = append(lines, line)
+		}
+		s.add("skills", name, list(lines), true)
+	}
+}
+
+func (s *Summary) addBase(b *BaseCofD2e) {
+	boxes := clamp(b.Health.Max, 0, maxDots)
+	health := make([]string, 0, boxes)
+	for i := 0; i < boxes; i++ {
+		switch {
+		case i < b.Health.Aggravated:
+			health = append(health, "[*]")
+		case i < b.Health.Aggravated+b.Health.Lethal:
+			health = append(health, "[X]")
+		case i < b.Health.Aggravated+b.Health.Lethal+b.Health.Bashing:
+			health = append(health, "[/]")
+		default:
+			health = append(health, "[ ]")
+		}
+	}
+	s.add("tracks", "Health", orNone(strings.Join(health, "")), true)
+	s.add("tracks", "Willpower", track(b.Willpower), true)
+	s.add("conditions", "Conditions", conditions(b.Conditions), false)
+	s.add("aspirations", "Aspirations", list(b.Aspirations), false)
+	s.add("merits", "Merits", merits(b.Merits), false)
+	for _, note := range b.Notes {
+		s.add("notes", orNone(note.Title), orNone(note.Content), false)
+	}
+}
+
+type namedSkill struct {
+	name string
+	CofD2eSkill
+}
+
+// skills lists the creature's skills, in mental, physical and social order
+func (c *CofD2eCreature) skills() []namedSkill {
+	return []namedSkill{
+		{"Academics", c.Academics}, {"Computer", c.Computer}, {"Crafts", c.Crafts},
+		{"Investigation", c.Investigation}, {"Medicine", c.Medicine}, {"Occult", c.Occult},
+		{"Politics", c.Politics}, {"Science", c.Science},
+		{"Athletics", c.Athletics}, {"Brawl", c.Brawl}, {"Drive", c.Drive},
+		{"Firearms", c.Firearms}, {"Larceny", c.Larceny}, {"Stealth", c.Stealth},
+		{"Survival", c.Survival}, {"Weaponry", c.Weaponry},
+		{"Animal Ken", c.AnimalKen}, {"Empathy", c.Empathy}, {"Expression", c.Expression},
+		{"Intimidation", c.Intimidation}, {"Persuasion", c.Persuasion}, {"Socialize", c.Socialize},
+		{"Streetwise", c.Streetwise}, {"Subterfuge", c.Subterfuge},
+	}
+}
+
+// maxDots is the most dots or boxes drawn for a trait, however many an imported or edited sheet gives it
+const maxDots = 20
+
+// clamp bounds n to between min and max
+func clamp(n, min, max int) int {
+	return util.Max(min, util.Min(n, max))
+}
+
+func dots(n, max int) string {
+	max = clamp(util.Max(n, max), 0, maxDots)
+	n = clamp(n, 0, max)
+	return strings.Repeat("●", n) + strings.Repeat("○", max-n)
+}
+
+func track(i IntWithMax) string {
+	max := clamp(i.Max, 0, maxDots)
+	current := clamp(i.Current, 0, max)
+	return orNone(strings.Repeat("■", current) + strings.Repeat("□", max-current))
+}
+
+func merits(m []CofD2eMerit) string {
+	lines := make([]string, 0, len(m))
+	for _, merit := range m {
+		lines = append(lines, merit.Name+" "+dots(merit.Dots, merit.Dots))
+	}
+	return list(lines)
+}
+
+func conditions(c Conditions) string {
+	lines := make([]string, 0, len(c))
+	for _, condition := range c {
+		tags := make([]string, 0)
+		if condition.Tilt {
+			tags = append(tags, "tilt")
+		}
+		if condition.Persistent {
+			tags = append(tags, "persistent")
+		}
+		for _, m := range condition.Modifiers {
+			tags = append(tags, fmt.Sprintf("%+d %s", m.Dice, m.Trait))
+		}
+		if condition.Source != "" {
+			tags = append(tags, "from "+condition.Source)
+		}
+		line := condition.Name
+		if len(tags) > 0 {
+			line += " (" + strings.Join(tags, ", ") + ")"
+		}
+		if condition.Resolution != "" {
+			line += ": " + condition.Resolution
+		}
+		lines = append(lines, line)
+	}
+	return list(lines)
+}
+
+func list(lines []string) string {
+	return orNone(strings.Join(lines, "\n"))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
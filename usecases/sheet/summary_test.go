@@ -0,0 +1,163 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/golang/mock/gomock"
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SummarySuite struct {
+	suite.Suite
+}
+
+func TestSummary(t *testing.T) {
+	suite.Run(t, new(SummarySuite))
+}
+
+func (suite *SummarySuite) TestFindByName() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	id := snowflake.ID(42)
+	chars := []*domains.Character{
+		{ID: &id, Name: "Ada", Guild: "1"},
+		{Name: "Ada", Guild: "2"},
+		{Name: "Bea", Guild: "1"},
+	}
+	found := &domains.Character{ID: &id, Name: "Ada"}
+	db.EXPECT().FindByPlayer(ctx, "7").Return(chars, nil).Times(3)
+	db.EXPECT().FindByID(ctx, "42").Return(found, nil)
+	char, err := FindByName(ctx, db, 1, 7, "ada")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), found, char)
+	_, err = FindByName(ctx, db, 1, 7, "")
+	assert.Equal(suite.T(), ErrAmbiguousCharacter, err)
+	_, err = FindByName(ctx, db, 1, 7, "Cat")
+	assert.Equal(suite.T(), ErrCharacterNotFound, err)
+}
+
+func (suite *SummarySuite) TestFindInGuild() {
+	ctrl, ctx := gomock.WithContext(context.Background(), suite.T())
+	db := domains.NewMockCharacterRepository(ctrl)
+	id := snowflake.ID(42)
+	chars := []*domains.Character{
+		{ID: &id, Name: "Ada", Guild: "1", Player: "7"},
+		{Name: "Bea", Guild: "1", Player: "8"},
+	}
+	found := &domains.Character{ID: &id, Name: "Ada"}
+	db.EXPECT().FindByGuild(ctx, "1").Return(chars, nil).Times(2)
+	db.EXPECT().FindByID(ctx, "42").Return(found, nil)
+	char, err := FindInGuild(ctx, db, 1, "ADA")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), found, char)
+	_, err = FindInGuild(ctx, db, 1, "")
+	assert.Equal(suite.T(), ErrAmbiguousCharacter, err)
+}
+
+func (suite *SummarySuite) TestSummarizeOutOfRange() {
+	sh := NewCofD2e()
+	sh.Willpower = IntWithMax{Current: 5, Max: -2}
+	sh.Health.Max = 1000
+	sh.Wits = -4
+	sh.Merits = append(sh.Merits, CofD2eMerit{Name: "Cursed", Dots: -3})
+	char := &domains.Character{Name: "Ada", System: "cofd2e", Sheet: sh}
+	summary, err := Summarize(char, "")
+	assert.Nil(suite.T(), err)
+	for _, field := range summary.Fields {
+		assert.True(suite.T(), len([]rune(field.Value)) < 1000, field.Name)
+	}
+	assert.Equal(suite.T(), "", dots(-3, -3))
+	assert.Equal(suite.T(), "●●○○○", dots(2, 5))
+	assert.Equal(suite.T(), strings.Repeat("□", maxDots), track(IntWithMax{Current: -1, Max: 1000}))
+	sh.Health.Max = -1
+	_, err = Summarize(char, "")
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *SummarySuite) TestFold() {
+	summary := new(Summary)
+	for i := 0; i < 30; i++ {
+		summary.add("notes", fmt.Sprintf("Note %d", i), "text", false)
+	}
+	summary.Fold(25)
+	assert.Len(suite.T(), summary.Fields, 25)
+	assert.Equal(suite.T(), "Note 23", summary.Fields[23].Name)
+	assert.Equal(suite.T(), "More", summary.Fields[24].Name)
+	assert.True(suite.T(), strings.HasPrefix(summary.Fields[24].Value, "**Note 24**\ntext"))
+	assert.True(suite.T(), strings.HasSuffix(summary.Fields[24].Value, "**Note 29**\ntext"))
+}
+
+func (suite *SummarySuite) TestSummarize() {
+	sh := NewCofD2e()
+	sh.Strength = 3
+	sh.Brawl = CofD2eSkill{Dots: 2, Specialties: "Boxing"}
+	sh.Health.Max = 4
+	sh.Health.Lethal = 1
+	sh.Health.Bashing = 1
+	sh.Willpower = IntWithMax{Current: 2, Max: 3}
+	sh.Merits = append(sh.Merits, CofD2eMerit{Name: "Resources", Dots: 2})
+	char := &domains.Character{Name: "Ada", System: "cofd2e", Sheet: sh}
+
+	summary, err := Summarize(char, "")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Ada", summary.Title)
+	assert.Equal(suite.T(), []string{"attributes", "skills", "tracks", "conditions", "aspirations", "merits", "traits"}, summary.Sections())
+	assert.Equal(suite.T(), "Strength ●●●○○\nDexterity ●○○○○\nStamina ●○○○○", summary.Fields[1].Value)
+	assert.Equal(suite.T(), "Brawl ●●○○○ (Boxing)", summary.Fields[4].Value)
+
+	summary, err = Summarize(char, "tracks")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "[X][/][ ][ ]", summary.Fields[0].Value)
+	assert.Equal(suite.T(), "■■□", summary.Fields[1].Value)
+
+	sh.Conditions = Conditions{{Name: "Stunned", Tilt: true, Modifiers: []DiceModifier{{Trait: "all", Dice: -2}}, Source: "Bob"}}
+	summary, err = Summarize(char, "conditions")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Stunned (tilt, -2 all, from Bob)", summary.Fields[0].Value)
+
+	summary, err = Summarize(char, "merits")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Resources ●●", summary.Fields[0].Value)
+
+	_, err = Summarize(char, "gifts")
+	assert.Equal(suite.T(), ErrUnknownSection, errors.Cause(err))
+}
+
+func (suite *SummarySuite) TestSummarizeWerewolf() {
+	sh := NewWtF2e()
+	sh.Gifts.Moon[0] = WtF2eMoonGift{List: "Gibbous", Dots: 2}
+	sh.Gifts.Shadow = []string{"Nature"}
+	char := &domains.Character{Name: "Runs-Far", System: "wtf2e", Sheet: sh}
+	summary, err := Summarize(char, "gifts")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "Gibbous ●●○○○", summary.Fields[0].Value)
+	assert.Equal(suite.T(), "Nature", summary.Fields[1].Value)
+	assert.Equal(suite.T(), "—", summary.Fields[2].Value)
+}
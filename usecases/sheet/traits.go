@@ -0,0 +1,171 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kkragenbrink/slate/domains"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownTrait is thrown when a pool names a trait the sheet does not have
+var ErrUnknownTrait = errors.New("sheet does not have that trait")
+
+// ErrNoSheet is thrown when a pool names a trait but there is no sheet to take it from
+var ErrNoSheet = errors.New("name a character to take traits from")
+
+// ResistTraits are the traits which may be taken from a target's dice pool to resist an action
+var ResistTraits = []string{"resolve", "composure", "stamina"}
+
+// Trait finds the dots of an attribute or skill on a sheet by name, ignoring case, spaces and
+// underscores.  Spirits resist with their Resistance in place of Resolve, Composure or Stamina.
+func Trait(sh domains.Sheet, name string) (int, error) {
+	key := TraitKey(name)
+	var traits map[string]int
+	switch s := sh.(type) {
+	case *CofD2e:
+		traits = creatureTraits(s.CofD2eCreature)
+	case *WtF2e:
+		traits = creatureTraits(s.CofD2eCreature)
+		traits["primalurge"] = s.PrimalUrge
+	case *CofD2eSpirit:
+		traits = map[string]int{
+			"power":      s.Power,
+			"finesse":    s.Finesse,
+			"resistance": s.Resistance,
+			"resolve":    s.Resistance,
+			"composure":  s.Resistance,
+			"stamina":    s.Resistance,
+		}
+	default:
+		return 0, ErrInvalidSheetSystem
+	}
+	dots, ok := traits[key]
+	if !ok {
+		return 0, errors.Wrap(ErrUnknownTrait, name)
+	}
+	return dots, nil
+}
+
+// Initiative finds the initiative modifier of a sheet: Dexterity + Composure, or Finesse +
+// Resistance for spirits
+func Initiative(sh domains.Sheet) (int, error) {
+	if _, ok := sh.(*CofD2eSpirit); ok {
+		return Pool(sh, "finesse+resistance")
+	}
+	return Pool(sh, "dexterity+composure")
+}
+
+// Pool adds up a dice pool such as wits+composure-1 from the traits of a sheet.  The sheet may be
+// nil when the pool is only numbers.
+func Pool(sh domains.Sheet, pool string) (int, error) {
+	return SumPool(pool, func(term string) (int, error) {
+		if sh == nil {
+			return 0, errors.Wrap(ErrNoSheet, term)
+		}
+		return Trait(sh, term)
+	})
+}
+
+// NamesTraits determines whether a dice pool names any traits, rather than being only numbers
+func NamesTraits(pool string) bool {
+	named := false
+	SumPool(pool, func(string) (int, error) {
+		named = true
+		return 0, nil
+	})
+	return named
+}
+
+// SumPool adds up the numbers and named terms of a dice pool, looking up each name with trait
+func SumPool(pool string, trait func(name string) (int, error)) (int, error) {
+	total := 0
+	sign := 1
+	term := ""
+	for _, r := range pool + "+" {
+		if r != '+' && r != '-' {
+			term += string(r)
+			continue
+		}
+		term = strings.TrimSpace(term)
+		if term != "" {
+			dots, err := strconv.Atoi(term)
+			if err != nil {
+				dots, err = trait(term)
+				if err != nil {
+					return 0, err
+				}
+			}
+			total += sign * dots
+		}
+		sign = 1
+		if r == '-' {
+			sign = -1
+		}
+		term = ""
+	}
+	return total, nil
+}
+
+// TraitKey normalizes the name of a trait, so that Animal Ken, animal_ken and animalken match
+func TraitKey(name string) string {
+	return strings.NewReplacer(" ", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+func creatureTraits(c *CofD2eCreature) map[string]int {
+	return map[string]int{
+		"intelligence":  c.Intelligence,
+		"wits":          c.Wits,
+		"resolve":       c.Resolve,
+		"strength":      c.Strength,
+		"dexterity":     c.Dexterity,
+		"stamina":       c.Stamina,
+		"presence":      c.Presence,
+		"manipulation":  c.Manipulation,
+		"composure":     c.Composure,
+		"academics":     c.Academics.Dots,
+		"computer":      c.Computer.Dots,
+		"crafts":        c.Crafts.Dots,
+		"investigation": c.Investigation.Dots,
+		"medicine":      c.Medicine.Dots,
+		"occult":        c.Occult.Dots,
+		"politics":      c.Politics.Dots,
+		"science":       c.Science.Dots,
+		"athletics":     c.Athletics.Dots,
+		"brawl":         c.Brawl.Dots,
+		"drive":         c.Drive.Dots,
+		"firearms":      c.Firearms.Dots,
+		"larceny":       c.Larceny.Dots,
+		"stealth":       c.Stealth.Dots,
+		"survival":      c.Survival.Dots,
+		"weaponry":      c.Weaponry.Dots,
+		"animalken":     c.AnimalKen.Dots,
+		"empathy":       c.Empathy.Dots,
+		"expression":    c.Expression.Dots,
+		"intimidation":  c.Intimidation.Dots,
+		"persuasion":    c.Persuasion.Dots,
+		"socialize":     c.Socialize.Dots,
+		"streetwise":    c.Streetwise.Dots,
+		"subterfuge":    c.Subterfuge.Dots,
+	}
+}
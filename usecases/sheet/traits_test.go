@@ -0,0 +1,97 @@
+// Copyright (c) 2019 Kevin Kragenbrink, II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sheet
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TraitsSuite struct {
+	suite.Suite
+}
+
+func TestTraits(t *testing.T) {
+	suite.Run(t, new(TraitsSuite))
+}
+
+func (suite *TraitsSuite) TestTrait() {
+	sh := NewWtF2e()
+	sh.Wits = 3
+	sh.AnimalKen.Dots = 2
+	sh.PrimalUrge = 4
+	dots, err := Trait(sh, "Wits")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 3, dots)
+	dots, err = Trait(sh, "animal_ken")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, dots)
+	dots, err = Trait(sh, "Primal Urge")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, dots)
+	_, err = Trait(sh, "essence")
+	assert.Equal(suite.T(), ErrUnknownTrait, errors.Cause(err))
+}
+
+func (suite *TraitsSuite) TestTraitSpirit() {
+	sh := NewCofD2eSpirit()
+	sh.Resistance = 4
+	for _, trait := range ResistTraits {
+		dots, err := Trait(sh, trait)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), 4, dots)
+	}
+}
+
+func (suite *TraitsSuite) TestPool() {
+	sh := NewCofD2e()
+	sh.Strength = 3
+	sh.Brawl.Dots = 2
+	dots, err := Pool(sh, "strength+brawl-1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, dots)
+	dots, err = Pool(nil, "5-2+1")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 4, dots)
+	_, err = Pool(nil, "strength+2")
+	assert.Equal(suite.T(), ErrNoSheet, errors.Cause(err))
+	_, err = Pool(sh, "strength+flight")
+	assert.Equal(suite.T(), ErrUnknownTrait, errors.Cause(err))
+	assert.True(suite.T(), NamesTraits("strength+weaponry+-1"))
+	assert.False(suite.T(), NamesTraits("5+-1"))
+}
+
+func (suite *TraitsSuite) TestInitiative() {
+	sh := NewCofD2e()
+	sh.Dexterity = 3
+	sh.Composure = 2
+	dots, err := Initiative(sh)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, dots)
+	spirit := NewCofD2eSpirit()
+	spirit.Finesse = 4
+	dots, err = Initiative(spirit)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 5, dots)
+}